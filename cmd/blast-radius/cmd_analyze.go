@@ -11,7 +11,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var accounts []string
+var (
+	accounts     []string
+	pathMode     string
+	maxPathDepth int
+)
 
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze [directory]",
@@ -20,7 +24,7 @@ var analyzeCmd = &cobra.Command{
 	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Load Rules (Embedded or Custom)
-		if err := definitions.LoadRules(rulesFile); err != nil {
+		if err := definitions.LoadRules(rulesFiles...); err != nil {
 			fmt.Printf("Error loading rules: %v\n", err)
 			return
 		}
@@ -31,6 +35,11 @@ var analyzeCmd = &cobra.Command{
 			return
 		}
 
+		if pathMode != "" && !containsMode(analyzer.PathModes, pathMode) {
+			fmt.Printf("Invalid --paths mode %q: must be one of %s\n", pathMode, strings.Join(analyzer.PathModes, ", "))
+			return
+		}
+
 		accountsToAnalyze := accounts
 		if len(accountsToAnalyze) == 0 {
 			accountsToAnalyze = analysis.Config.GetAnalysisAccounts()
@@ -40,21 +49,35 @@ var analyzeCmd = &cobra.Command{
 			return
 		}
 
-		// Get impersonation function
-		canImpersonate := definitions.GetCanImpersonateFunc()
-
-		directAccess := analyzer.Analyze(analysis.Bindings)
-		impGraph := analyzer.BuildImpersonationGraphWithFunc(analysis.Bindings, canImpersonate)
+		directAccess, impGraph, err := analyzeAccess(analysis)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+		scorer := attachedScorer(analysis, directAccess, impGraph)
 
 		if outputFormat == "text" {
 			_, _ = headerColor.Println("\n--- Transitive Access Analysis ---")
 		}
 
+		var identities []analyzer.ExternalIdentity
+		identitiesLoaded := false
 		for _, accountEmail := range accountsToAnalyze {
+			// External identity selectors such as github:org/repo
+			if _, _, ok := analyzer.ParseIdentitySelector(accountEmail); ok {
+				if !identitiesLoaded {
+					identities = findExternalIdentities(analysis)
+					identitiesLoaded = true
+				}
+				analyzeExternalIdentity(accountEmail, identities, directAccess, impGraph, scorer)
+				continue
+			}
+
 			transitiveAccess := analyzer.AnalyzeTransitiveAccess(accountEmail, directAccess, impGraph)
 
 			if outputFormat == "json" {
-				jsonOut := output.ConvertToAnalyzeOutput(accountEmail, transitiveAccess)
+				jsonOut := output.ConvertToAnalyzeOutput(accountEmail, transitiveAccess, scorer)
+				addResourcePaths(&jsonOut, transitiveAccess, directAccess, impGraph)
 				output.PrintJSON(jsonOut)
 				continue
 			}
@@ -65,82 +88,305 @@ var analyzeCmd = &cobra.Command{
 				continue
 			}
 
-			fmt.Printf("\n%s %s\n", principalColor.Sprint("Principal:"), transitiveAccess.Principal)
+			printTransitiveAccess(transitiveAccess)
+			printResourcePaths(transitiveAccess, directAccess, impGraph)
+		}
+	},
+}
 
-			// Direct Access
-			if len(transitiveAccess.DirectAccess.ResourceAccess) > 0 {
-				_, _ = headerColor.Println("\nDirect Access:")
-				var resources []string
-				for resID := range transitiveAccess.DirectAccess.ResourceAccess {
-					resources = append(resources, resID)
-				}
-				sort.Strings(resources)
+// containsMode reports whether mode is one of modes
+func containsMode(modes []string, mode string) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
 
-				for _, resID := range resources {
-					meta := transitiveAccess.DirectAccess.ResourceAccess[resID]
-					fmt.Printf("  - %s (%s):\n", resID, meta.Type)
+// addResourcePaths adds the impersonation paths selected by --paths to the JSON output of an analyzed principal
+func addResourcePaths(out *output.AnalyzeOutput, transitiveAccess *analyzer.TransitiveAccess, directAccess map[string]*analyzer.PrincipalData, impGraph *analyzer.ImpersonationGraph) {
+	if pathMode == "" || transitiveAccess == nil {
+		return
+	}
+	out.PathMode = pathMode
+	out.ImpersonationPaths = analyzer.FindResourcePaths(transitiveAccess.Principal, directAccess, impGraph, pathMode, maxPathDepth)
+}
 
-					var roles []string
-					for role := range meta.Roles {
-						roles = append(roles, role)
-					}
-					sort.Strings(roles)
+// printResourcePaths prints the impersonation paths selected by --paths to each resource an analyzed principal reaches
+func printResourcePaths(transitiveAccess *analyzer.TransitiveAccess, directAccess map[string]*analyzer.PrincipalData, impGraph *analyzer.ImpersonationGraph) {
+	if pathMode == "" || transitiveAccess == nil {
+		return
+	}
+	resourcePaths := analyzer.FindResourcePaths(transitiveAccess.Principal, directAccess, impGraph, pathMode, maxPathDepth)
+	if len(resourcePaths) == 0 {
+		fmt.Printf("\n%s None\n", headerColor.Sprintf("Impersonation Paths (%s):", pathMode))
+		return
+	}
 
-					for _, role := range roles {
-						fmt.Printf("      %s\n", role)
-					}
+	_, _ = headerColor.Printf("\nImpersonation Paths (%s):\n", pathMode)
+	for _, rp := range resourcePaths {
+		fmt.Printf("  - %s (%s): %s\n", rp.ResourceID, rp.ResourceType, strings.Join(rp.Roles, ", "))
+		for i, path := range rp.Paths {
+			fmt.Printf("      %d. %s (%s)\n", i+1, strings.Join(path.Chain, " → "), chainLabels[path.ChainType])
+			if pathMode != analyzer.PathModeMinCut {
+				for j, edge := range path.Edges {
+					fmt.Printf("          %d.%d %s\n", i+1, j+1, formatEdge(edge))
 				}
-			} else {
-				fmt.Printf("\n%s None\n", headerColor.Sprint("Direct Access:"))
 			}
+		}
+		if rp.Truncated {
+			fmt.Printf("    %s\n", accessWrite.Sprintf("Only the first %d paths were enumerated; more paths may exist.", analyzer.MaxImpersonationPaths))
+		}
+		if pathMode == analyzer.PathModeMinCut {
+			label := "Remove any one of these sets of bindings to cut off every path:"
+			if rp.CutApproximate {
+				label = "Removing these bindings cuts off every path (too many bindings for an exhaustive search, may not be the smallest set):"
+			}
+			fmt.Printf("    %s\n", label)
+			for _, cut := range rp.MinimalCuts {
+				fmt.Printf("      %s %s\n", accessAdmin.Sprint("[CUT]"), strings.Join(cut, ", "))
+			}
+		}
+	}
+}
 
-			// Hierarchical Access
-			if len(transitiveAccess.DirectAccess.HierarchicalAccess) > 0 {
-				_, _ = headerColor.Println("\nHierarchical Access:")
-				var projects []string
-				for proj := range transitiveAccess.DirectAccess.HierarchicalAccess {
-					projects = append(projects, proj)
-				}
-				sort.Strings(projects)
-				for _, proj := range projects {
-					fmt.Printf("  - All resources in project '%s'\n", proj)
-				}
+// printTransitiveAccess prints the direct, hierarchical and effective access of an analyzed principal
+func printTransitiveAccess(transitiveAccess *analyzer.TransitiveAccess) {
+	fmt.Printf("\n%s %s\n", principalColor.Sprint("Principal:"), transitiveAccess.Principal)
+
+	// Direct Access
+	if len(transitiveAccess.DirectAccess.ResourceAccess) > 0 {
+		_, _ = headerColor.Println("\nDirect Access:")
+		var resources []string
+		for resID := range transitiveAccess.DirectAccess.ResourceAccess {
+			resources = append(resources, resID)
+		}
+		sort.Strings(resources)
+
+		for _, resID := range resources {
+			meta := transitiveAccess.DirectAccess.ResourceAccess[resID]
+			fmt.Printf("  - %s (%s):\n", resID, meta.Type)
+
+			var roles []string
+			for role := range meta.Roles {
+				roles = append(roles, role)
 			}
+			sort.Strings(roles)
 
-			// Effective Grants (via impersonation)
-			if len(transitiveAccess.TransitiveAccess) > 0 {
-				_, _ = headerColor.Println("\nEffective Grants (via impersonation):")
-				var resources []string
-				for resID := range transitiveAccess.TransitiveAccess {
-					resources = append(resources, resID)
+			for _, role := range roles {
+				if path := meta.GroupPaths[role]; len(path) > 0 {
+					fmt.Printf("      %s (via %s)\n", role, strings.Join(path, " → "))
+				} else {
+					fmt.Printf("      %s\n", role)
 				}
-				sort.Strings(resources)
-
-				for _, resID := range resources {
-					accessVia := transitiveAccess.TransitiveAccess[resID]
-					fmt.Printf("  - %s (%s):\n", resID, accessVia.Resource.Type)
-
-					var roles []string
-					for role := range accessVia.Resource.Roles {
-						roles = append(roles, role)
-					}
-					sort.Strings(roles)
-
-					for _, role := range roles {
-						fmt.Printf("      %s %s\n", accessImpersonate.Sprint("[EFFECTIVE]"), role)
-					}
-					fmt.Printf("    → via chain: %s\n", strings.Join(accessVia.ViaChain, " → "))
+			}
+		}
+	} else {
+		fmt.Printf("\n%s None\n", headerColor.Sprint("Direct Access:"))
+	}
+
+	// Hierarchical Access
+	if len(transitiveAccess.DirectAccess.HierarchicalAccess) > 0 {
+		_, _ = headerColor.Println("\nHierarchical Access:")
+		var projects []string
+		for proj := range transitiveAccess.DirectAccess.HierarchicalAccess {
+			projects = append(projects, proj)
+		}
+		sort.Strings(projects)
+		for _, proj := range projects {
+			fmt.Printf("  - All resources in project '%s'\n", proj)
+		}
+	}
+
+	// Effective Grants (via impersonation)
+	if len(transitiveAccess.TransitiveAccess) > 0 || len(transitiveAccess.HierarchicalAccess) > 0 {
+		_, _ = headerColor.Println("\nEffective Grants (via impersonation):")
+		var resources []string
+		for resID := range transitiveAccess.TransitiveAccess {
+			resources = append(resources, resID)
+		}
+		sort.Strings(resources)
+
+		for _, resID := range resources {
+			accessVia := transitiveAccess.TransitiveAccess[resID]
+			fmt.Printf("  - %s (%s):\n", resID, accessVia.Resource.Type)
+
+			var roles []string
+			for role := range accessVia.Resource.Roles {
+				roles = append(roles, role)
+			}
+			sort.Strings(roles)
+
+			for _, role := range roles {
+				fmt.Printf("      %s %s\n", chainGrantLabel(accessVia.ChainType), role)
+			}
+			printChain(accessVia.ChainType, accessVia.ViaChain, accessVia.ViaEdges)
+		}
+
+		// Project, folder and organization access of the impersonated accounts
+		for _, via := range transitiveAccess.SortedHierarchicalAccess() {
+			fmt.Printf("  - %s '%s' (%s):\n", via.Scope.Type, via.Scope.ID, describeResourceTypes(via.ResourceTypes))
+			for _, entry := range via.Entries {
+				if entry.InheritedFrom != nil {
+					fmt.Printf("      %s %s (inherited from %s '%s')\n", chainGrantLabel(via.ChainType), entry.Role, entry.InheritedFrom.Type, entry.InheritedFrom.ID)
+				} else {
+					fmt.Printf("      %s %s\n", chainGrantLabel(via.ChainType), entry.Role)
 				}
-			} else {
-				fmt.Printf("\n%s None\n", headerColor.Sprint("Effective Grants (via impersonation):"))
 			}
+			printChain(via.ChainType, via.ViaChain, via.ViaEdges)
 		}
-	},
+	} else {
+		fmt.Printf("\n%s None\n", headerColor.Sprint("Effective Grants (via impersonation):"))
+	}
+
+	// Granted access that deny policies take away
+	denied := append(append([]analyzer.DeniedAccess{}, transitiveAccess.DirectAccess.Denied...), transitiveAccess.Denied...)
+	if len(denied) > 0 {
+		_, _ = headerColor.Println("\nDenied Access (granted, but blocked by deny policies):")
+		for _, d := range denied {
+			fmt.Printf("  - %s\n", formatDenied(d))
+		}
+	}
+
+	// Long-lived keys of the principal or of service accounts it reaches
+	if len(transitiveAccess.Keys) > 0 {
+		_, _ = headerColor.Println("\nService Account Keys in Terraform:")
+		for _, key := range transitiveAccess.Keys {
+			fmt.Printf("  - %s %s (%s)\n", accessAdmin.Sprint("[KEY]"), key.ServiceAccount, key.Address)
+		}
+		fmt.Println("    Anyone who can read the Terraform state or its outputs holds these service accounts' access.")
+	}
+}
+
+// printChain prints the impersonation chain behind effective access and the edge behind each hop
+func printChain(chainType string, chain []string, edges []analyzer.ImpersonationEdge) {
+	fmt.Printf("    → via chain (%s): %s\n", chainLabels[chainType], strings.Join(chain, " → "))
+	for i, edge := range edges {
+		fmt.Printf("        %d. %s\n", i+1, formatEdge(edge))
+	}
+}
+
+// describeResourceTypes describes the resource types hierarchical access reaches
+func describeResourceTypes(types map[string]bool) string {
+	if types["*"] {
+		return "all resources"
+	}
+	var list []string
+	for rt := range types {
+		list = append(list, rt)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
+
+// analyzeExternalIdentity analyzes every member that covers an external identity selector
+func analyzeExternalIdentity(selector string, identities []analyzer.ExternalIdentity, directAccess map[string]*analyzer.PrincipalData, impGraph *analyzer.ImpersonationGraph, scorer *analyzer.Scorer) {
+	matched := analyzer.MatchExternalIdentities(selector, identities)
+
+	if outputFormat == "json" {
+		for _, id := range matched {
+			transitiveAccess := analyzer.AnalyzePrincipalTransitiveAccess(id.Principal, directAccess, impGraph)
+			jsonOut := output.ConvertToAnalyzeOutput(selector, transitiveAccess, scorer)
+			addResourcePaths(&jsonOut, transitiveAccess, directAccess, impGraph)
+			jsonOut.ExternalIdentity = &id
+			output.PrintJSON(jsonOut)
+		}
+		return
+	}
+
+	_, _ = headerColor.Printf("\n=== Analyzing: %s ===\n", selector)
+	if len(matched) == 0 {
+		fmt.Printf("  No member in the scanned code covers external identity: %s\n", selector)
+		return
+	}
+	for _, id := range matched {
+		fmt.Printf("\n%s %s\n", principalColor.Sprint("External Identity:"), id.Description())
+		for _, condition := range id.Conditions {
+			fmt.Printf("  Attribute condition: %s\n", condition)
+		}
+		if len(id.Providers) == 0 && id.Kind != analyzer.ExternalKubernetes {
+			fmt.Println("  No provider for this pool in the scanned code; attribute conditions are unknown")
+		}
+		transitiveAccess := analyzer.AnalyzePrincipalTransitiveAccess(id.Principal, directAccess, impGraph)
+		printTransitiveAccess(transitiveAccess)
+		printResourcePaths(transitiveAccess, directAccess, impGraph)
+	}
+}
+
+// chainGrantLabel returns the prefix of a role gained through a chain: [UNCONFIRMED] when an actAs hop has no
+// known deploy role, so the role is only gained if the principal can deploy compute some other way
+func chainGrantLabel(chainType string) string {
+	if chainType == analyzer.ChainActAsUnconfirmed {
+		return accessWrite.Sprint("[UNCONFIRMED]")
+	}
+	return accessImpersonate.Sprint("[EFFECTIVE]")
+}
+
+// chainLabels describes each chain type in text output
+var chainLabels = map[string]string{
+	analyzer.ChainDirectImpersonation: "direct impersonation",
+	analyzer.ChainActAsViaCompute:     "actAs via deployable compute",
+	analyzer.ChainActAsUnconfirmed:    "actAs, no deploy role found",
+	analyzer.ChainViaWorkload:         "via modifiable workload",
+}
+
+// formatEdge explains why a hop of an impersonation chain exists
+func formatEdge(edge analyzer.ImpersonationEdge) string {
+	grant := "granted on the service account"
+	if edge.Workload != "" {
+		grant = "granted on the workload"
+	}
+	if edge.Inherited {
+		scopeType, scopeID, _ := strings.Cut(edge.Scope, ":")
+		grant = fmt.Sprintf("inherited from %s '%s'", scopeType, scopeID)
+	}
+	line := fmt.Sprintf("%s → %s: %s [%s], %s", edge.Source, edge.Target, edge.Role, edge.Capability, grant)
+	if edge.Capability == definitions.CapabilityActAs {
+		if edge.DeployRole != "" {
+			line += fmt.Sprintf(", deploys with %s", edge.DeployRole)
+		} else {
+			line += ", no deploy role in the service account's project"
+		}
+	}
+	if edge.Workload != "" {
+		line += fmt.Sprintf(", modifies %s running as it", edge.Workload)
+	}
+	if len(edge.ViaGroups) > 0 {
+		line += fmt.Sprintf(", through membership of %s", strings.Join(edge.ViaGroups, " → "))
+	}
+	if edge.Condition != "" {
+		line += fmt.Sprintf(", when %s", edge.Condition)
+	}
+	if edge.SourceAddress != "" {
+		line += fmt.Sprintf(" (%s)", edge.SourceAddress)
+	}
+	return line
+}
+
+// formatDenied describes a grant or impersonation hop deny policies take away
+func formatDenied(d analyzer.DeniedAccess) string {
+	label, verb := accessRead.Sprint("[NARROWED]"), "denies"
+	if d.Revoked {
+		label = accessRead.Sprint("[DENIED]")
+	} else if d.Possible {
+		label, verb = accessRead.Sprint("[POSSIBLY NARROWED]"), "may deny"
+	}
+	line := fmt.Sprintf("%s %s on %s, %s %s", label, d.Role, d.ResourceID, verb, strings.Join(d.Permissions, ", "))
+	if d.Edge != nil {
+		line = fmt.Sprintf("%s hop %s → %s (%s), denies %s", label, d.Edge.Source, d.Edge.Target, d.Role, strings.Join(d.Permissions, ", "))
+	}
+	if len(d.Conditions) > 0 {
+		line += fmt.Sprintf(", when %s", strings.Join(d.Conditions, " or "))
+	}
+	return line + fmt.Sprintf(" (%s)", strings.Join(d.Policies, ", "))
 }
 
 func init() {
-	analyzeCmd.Flags().StringSliceVar(&accounts, "account", nil, "Accounts to analyze (comma-separated emails)")
+	analyzeCmd.Flags().StringSliceVar(&accounts, "account", nil, "Accounts to analyze (comma-separated emails, or external identities such as github:org/repo)")
 	analyzeCmd.Flags().StringVar(&tfvarsFile, "tfvars", "", "Path to terraform.tfvars file")
 	analyzeCmd.Flags().StringVar(&planFile, "plan", "", "Path to terraform plan JSON file")
+	analyzeCmd.Flags().StringVar(&pathMode, "paths", "", "List impersonation paths to each resource: shortest, all or min-cut")
+	analyzeCmd.Flags().IntVar(&maxPathDepth, "max-depth", analyzer.DefaultMaxPathDepth, "Maximum number of impersonation hops followed by --paths")
 	rootCmd.AddCommand(analyzeCmd)
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/output"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage [directory]",
+	Short: "Report IAM-like resources and roles the definitions do not cover",
+	Long: `Scans Terraform files or a plan for resource types that look like IAM resources
+(containing _iam_, _access or _acl, or matching coverage.resource_patterns in the config)
+but have no resource definition, and for roles bound at organization, folder or project level
+that are missing from the role rules. Suggests overlay stubs for each gap.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load Rules (Embedded or Custom)
+		if err := definitions.LoadRules(rulesFiles...); err != nil {
+			fmt.Printf("Error loading rules: %v\n", err)
+			return
+		}
+
+		analysis, err := setupAnalysis(args)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+
+		cov := analysis.Config.Coverage
+		result := analyzer.AnalyzeCoverage(analysis.Source.ListResources(), analysis.Bindings, analysis.Defs, cov.ResourcePatterns, cov.IgnoredTypes)
+
+		if outputFormat == "json" {
+			output.PrintJSON(output.ConvertToCoverageOutput(result, analysis.SourceInfo))
+			return
+		}
+
+		_, _ = headerColor.Println("\n--- Coverage Report ---")
+		fmt.Printf("Resources scanned: %d (%d covered by definitions)\n", result.ResourcesScanned, result.CoveredResources)
+
+		if len(result.UncoveredTypes) == 0 && len(result.UnknownRoles) == 0 {
+			color.Green("\nNo coverage gaps found.")
+			return
+		}
+
+		if len(result.UncoveredTypes) > 0 {
+			_, _ = headerColor.Printf("\nResource types without a definition (%d):\n", len(result.UncoveredTypes))
+			for _, t := range result.UncoveredTypes {
+				fmt.Printf("  - %s: %d resource(s)\n", t.Type, t.Count)
+				for _, addr := range t.Addresses {
+					fmt.Printf("      %s\n", addr)
+				}
+			}
+		}
+
+		if len(result.UnknownRoles) > 0 {
+			_, _ = headerColor.Printf("\nRoles missing from hierarchical_roles (%d):\n", len(result.UnknownRoles))
+			for _, r := range result.UnknownRoles {
+				fmt.Printf("  - %s: %d binding(s)\n", r.Role, r.Count)
+				for _, addr := range r.Addresses {
+					fmt.Printf("      %s\n", addr)
+				}
+			}
+		}
+
+		if len(result.UncoveredTypes) > 0 {
+			_, _ = headerColor.Println("\nSuggested definitions overlay (--definitions):")
+			fmt.Println(formatDefinitionStubs(result.UncoveredTypes))
+		}
+		if len(result.UnknownRoles) > 0 {
+			_, _ = headerColor.Println("\nSuggested rules overlay (--rules):")
+			fmt.Println(formatRoleStubs(result.UnknownRoles))
+		}
+
+		color.Yellow("\nReview the stubs before use: field mappings and access levels are guesses.")
+	},
+}
+
+// formatDefinitionStubs renders uncovered types as a definitions overlay
+func formatDefinitionStubs(types []analyzer.UncoveredResourceType) string {
+	var sb strings.Builder
+	sb.WriteString("definitions:\n")
+	for _, t := range types {
+		m := t.Stub.FieldMappings
+		fmt.Fprintf(&sb, "  - type: %s\n", t.Type)
+		sb.WriteString("    field_mappings:\n")
+		fmt.Fprintf(&sb, "      resource_id: %q\n", m.ResourceID)
+		fmt.Fprintf(&sb, "      role: %q\n", m.Role)
+		fmt.Fprintf(&sb, "      member: %q\n", m.Member)
+		fmt.Fprintf(&sb, "      members: %q\n", m.Members)
+		if m.PolicyData != "" {
+			fmt.Fprintf(&sb, "      policy_data: %q\n", m.PolicyData)
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// formatRoleStubs renders unknown roles as a rules overlay
+func formatRoleStubs(roles []analyzer.UnknownRole) string {
+	var sb strings.Builder
+	sb.WriteString("hierarchical_roles:\n")
+	for _, r := range roles {
+		fmt.Fprintf(&sb, "  %q:\n", r.Role)
+		fmt.Fprintf(&sb, "    display_name: %q\n", r.Stub.DisplayName)
+		sb.WriteString("    resource_types: []\n")
+		fmt.Fprintf(&sb, "    target_level: %q\n", r.Stub.TargetLevel)
+		fmt.Fprintf(&sb, "    access_level: %q\n", r.Stub.AccessLevel)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func init() {
+	coverageCmd.Flags().StringVar(&tfvarsFile, "tfvars", "", "Path to terraform.tfvars file")
+	coverageCmd.Flags().StringVar(&planFile, "plan", "", "Path to terraform plan JSON file")
+	rootCmd.AddCommand(coverageCmd)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var showEffective bool
+
+var definitionsCmd = &cobra.Command{
+	Use:   "definitions",
+	Short: "Inspect resource definitions and role rules",
+	Long:  `Commands for inspecting the built-in resource definitions and role rules, and the result of layering overlay files on top of them.`,
+}
+
+var definitionsShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print resource definitions and role rules",
+	Long: `Prints the built-in resource definitions and role rules.
+With --effective, the overlays given via --definitions and --rules are applied in order
+and every entry is annotated with its origin ("builtin" or the overlay file path).`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var defPaths, rulePaths []string
+		if showEffective {
+			defPaths = definitionsFiles
+			rulePaths = rulesFiles
+		}
+
+		defs, err := definitions.LoadResourceDefinitions(defPaths...)
+		if err != nil {
+			fmt.Printf("Error loading resource definitions: %v\n", err)
+			os.Exit(1)
+		}
+		if err := definitions.LoadRules(rulePaths...); err != nil {
+			fmt.Printf("Error loading rules: %v\n", err)
+			os.Exit(1)
+		}
+
+		out := output.ConvertToDefinitionsOutput(defs, definitions.GetRulesConfig(), showEffective)
+
+		if outputFormat == "json" {
+			output.PrintJSON(out)
+			return
+		}
+
+		title := "Built-in"
+		if showEffective {
+			title = "Effective"
+		}
+
+		_, _ = headerColor.Printf("\n--- %s Resource Definitions (%d) ---\n", title, len(out.ResourceDefinitions))
+		for _, def := range out.ResourceDefinitions {
+			fmt.Printf("  - %s (%s) [%s]\n", def.Type, def.ResourceLevel, def.Origin)
+		}
+
+		_, _ = headerColor.Printf("\n--- %s Workloads (%d) ---\n", title, len(out.Workloads))
+		for _, w := range out.Workloads {
+			fmt.Printf("  - %s runs as %s [%s]\n", w.Type, w.ServiceAccount, w.Origin)
+		}
+
+		_, _ = headerColor.Printf("\n--- %s Inventory (%d) ---\n", title, len(out.Inventory))
+		for _, inv := range out.Inventory {
+			fmt.Printf("  - %s (id %s, project %s) [%s]\n", inv.Type, inv.ResourceID, inv.Project, inv.Origin)
+		}
+
+		_, _ = headerColor.Printf("\n--- %s Hierarchical Roles (%d) ---\n", title, len(out.HierarchicalRoles))
+		for _, role := range out.HierarchicalRoles {
+			types := strings.Join(role.ResourceTypes, ", ")
+			if types == "" {
+				types = "none"
+			}
+			name := role.Role
+			if role.Pattern {
+				name = fmt.Sprintf("%s (pattern, priority %d)", role.Role, role.Priority)
+			}
+			fmt.Printf("  - %s: %s on %s (%s) [%s]\n",
+				name, colorizeAccessType(role.AccessLevel), role.DisplayName, types, role.Origin)
+		}
+
+		_, _ = headerColor.Printf("\n--- %s Impersonation Roles (%d) ---\n", title, len(out.ImpersonationRoles))
+		for _, role := range out.ImpersonationRoles {
+			fmt.Printf("  - %s [%s]\n", role.Role, role.Origin)
+		}
+
+		_, _ = headerColor.Printf("\n--- %s Impersonation Rules (%d) ---\n", title, len(out.ImpersonationRules))
+		for _, rule := range out.ImpersonationRules {
+			fmt.Printf("  - %s → %s [%s]\n", rule.Source, rule.Target, rule.Origin)
+		}
+
+		_, _ = headerColor.Printf("\n--- %s Role Includes (%d) ---\n", title, len(out.RoleIncludes))
+		for _, entry := range out.RoleIncludes {
+			fmt.Printf("  - %s ⊇ %s [%s]\n", entry.Role, strings.Join(entry.Values, ", "), entry.Origin)
+		}
+
+		_, _ = headerColor.Printf("\n--- %s Role Permissions (%d) ---\n", title, len(out.RolePermissions))
+		for _, entry := range out.RolePermissions {
+			fmt.Printf("  - %s: %d permission(s) [%s]\n", entry.Role, len(entry.Values), entry.Origin)
+		}
+
+		_, _ = headerColor.Printf("\n--- %s Deploy Roles (%d) ---\n", title, len(out.DeployRoles))
+		for _, role := range out.DeployRoles {
+			fmt.Printf("  - %s [%s]\n", role.Role, role.Origin)
+		}
+
+		_, _ = headerColor.Printf("\n--- %s Escalation Primitives (%d) ---\n", title, len(out.Escalation))
+		for _, p := range out.Escalation {
+			fmt.Printf("  - %s (gains %s): %s [%s]\n", p.Name, p.Gains, p.Description, p.Origin)
+		}
+	},
+}
+
+func init() {
+	definitionsShowCmd.Flags().BoolVar(&showEffective, "effective", false, "Apply --definitions/--rules overlays and show the origin of each entry")
+	definitionsCmd.AddCommand(definitionsShowCmd)
+	rootCmd.AddCommand(definitionsCmd)
+}
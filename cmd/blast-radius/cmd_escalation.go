@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/output"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var escalationMaxDepth int
+
+var escalationCmd = &cobra.Command{
+	Use:   "escalation [directory]",
+	Short: "Find principals that can escalate to owner-equivalent access",
+	Long: `Finds every principal that can reach owner-equivalent access on a project, folder or organization
+it does not already own, through the built-in catalogue of GCP escalation primitives: setIamPolicy on a
+project, folder, organization or service account, service account key and token creation, actAs with a
+deploy role, custom role updates, and Deployment Manager or Cloud Build service agents. Each finding is
+reported as a chain of steps with the role, binding and source location behind each one.
+The catalogue is the escalation_primitives section of the rules and can be extended with --rules.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load Rules (Embedded or Custom)
+		if err := definitions.LoadRules(rulesFiles...); err != nil {
+			fmt.Printf("Error loading rules: %v\n", err)
+			return
+		}
+
+		analysis, err := setupAnalysis(args)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+
+		directAccess, impGraph, err := analyzeAccess(analysis)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+
+		findings := analyzer.FindEscalations(analysis.Bindings, directAccess, impGraph, escalationMaxDepth)
+
+		if outputFormat == "json" {
+			scorer := attachedScorer(analysis, directAccess, impGraph)
+			output.PrintJSON(output.ConvertToEscalationOutput(findings, analysis.SourceInfo, scorer))
+			return
+		}
+
+		_, _ = headerColor.Println("\n--- Privilege Escalation ---")
+		if len(findings) == 0 {
+			color.Green("\nNo principal can escalate to owner-equivalent access.")
+			return
+		}
+
+		principal := ""
+		for _, f := range findings {
+			if f.Principal != principal {
+				principal = f.Principal
+				fmt.Printf("\n%s\n", principalColor.Sprint(f.Principal))
+			}
+			scopeType, scopeID, _ := strings.Cut(f.Scope, ":")
+			line := fmt.Sprintf("  → owner of %s '%s' in %d step(s)", scopeType, scopeID, len(f.Steps))
+			if f.Conditional {
+				line += accessWrite.Sprint(" [conditional]")
+			}
+			fmt.Println(line)
+			for i, step := range f.Steps {
+				fmt.Printf("      %d. %s\n", i+1, formatEscalationStep(step))
+			}
+		}
+		fmt.Printf("\n%d finding(s) for %d principal(s)\n", len(findings), countPrincipals(findings))
+	},
+}
+
+// formatEscalationStep describes one step of an escalation chain and the binding behind it
+func formatEscalationStep(step analyzer.EscalationStep) string {
+	target := step.Target
+	if scopeType, scopeID, ok := strings.Cut(step.Target, ":"); ok && !analyzer.IsPrincipal(step.Target) {
+		target = fmt.Sprintf("owner of %s '%s'", scopeType, scopeID)
+	}
+	line := fmt.Sprintf("%s %s → %s: %s on %s", accessAdmin.Sprintf("[%s]", step.Primitive), step.Principal, target, step.Role, step.Scope)
+	if step.Detail != "" {
+		line += ", " + step.Detail
+	}
+	if len(step.ViaGroups) > 0 {
+		line += fmt.Sprintf(", through membership of %s", strings.Join(step.ViaGroups, " → "))
+	}
+	if step.Condition != "" {
+		line += fmt.Sprintf(", when %s", step.Condition)
+	}
+	if step.Binding != "" {
+		line += fmt.Sprintf(" (%s)", step.Binding)
+	}
+	if step.Location != "" {
+		line += fmt.Sprintf(" at %s", step.Location)
+	}
+	return line
+}
+
+// countPrincipals returns the number of distinct principals with findings
+func countPrincipals(findings []analyzer.EscalationFinding) int {
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		seen[f.Principal] = true
+	}
+	return len(seen)
+}
+
+func init() {
+	escalationCmd.Flags().IntVar(&escalationMaxDepth, "max-depth", analyzer.DefaultMaxPathDepth, "Maximum number of hops followed before the final step")
+	escalationCmd.Flags().StringVar(&tfvarsFile, "tfvars", "", "Path to terraform.tfvars file")
+	escalationCmd.Flags().StringVar(&planFile, "plan", "", "Path to terraform plan JSON file")
+	rootCmd.AddCommand(escalationCmd)
+}
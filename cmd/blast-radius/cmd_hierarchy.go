@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
@@ -18,7 +19,7 @@ var hierarchyCmd = &cobra.Command{
 	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Load Rules (Embedded or Custom)
-		if err := definitions.LoadRules(rulesFile); err != nil {
+		if err := definitions.LoadRules(rulesFiles...); err != nil {
 			fmt.Printf("Error loading rules: %v\n", err)
 			return
 		}
@@ -29,11 +30,21 @@ var hierarchyCmd = &cobra.Command{
 			return
 		}
 
-		// Perform hierarchy analysis
-		result := analyzer.AnalyzeHierarchy(analysis.Bindings)
+		// Perform hierarchy analysis against the reconstructed resource tree
+		result, err := analyzeExpandedHierarchy(analysis)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
 
 		if outputFormat == "json" {
-			jsonOut := output.ConvertToNewHierarchyOutput(result, analysis.SourceInfo, len(analysis.Bindings))
+			var scorer *analyzer.Scorer
+			if groups, err := loadGroups(analysis); err != nil {
+				warnNoScores(err)
+			} else {
+				scorer = attachedScores(analysis, groups, result)
+			}
+			jsonOut := output.ConvertToNewHierarchyOutput(result, analysis.SourceInfo, len(analysis.Bindings), scorer)
 			output.PrintJSON(jsonOut)
 			return
 		}
@@ -60,6 +71,7 @@ var hierarchyCmd = &cobra.Command{
 		}
 		sort.Strings(principals)
 
+		boundEntries := 0
 		for _, principal := range principals {
 			entries := byPrincipal[principal]
 			fmt.Printf("\n%s %s\n", principalColor.Sprint("Principal:"), principal)
@@ -70,14 +82,31 @@ var hierarchyCmd = &cobra.Command{
 				if displayName == "" {
 					displayName = "resources"
 				}
-				fmt.Printf("    - %s access to ALL %ss in %s '%s' via role %s assigned on %s level\n",
-					colorizeAccessType(entry.Grants.AccessType),
-					displayName,
-					entry.Scope.Type,
-					entry.Scope.ID,
-					entry.Role,
-					entry.Scope.Type,
-				)
+				if entry.InheritedFrom != nil {
+					fmt.Printf("    - %s access to ALL %ss in %s '%s' via role %s inherited from %s '%s' (%s)\n",
+						colorizeAccessType(entry.Grants.AccessType),
+						displayName,
+						entry.Scope.Type,
+						entry.Scope.ID,
+						entry.Role,
+						entry.InheritedFrom.Type,
+						entry.InheritedFrom.ID,
+						formatScopePath(entry.Path),
+					)
+				} else {
+					boundEntries++
+					fmt.Printf("    - %s access to ALL %ss in %s '%s' via role %s assigned on %s level\n",
+						colorizeAccessType(entry.Grants.AccessType),
+						displayName,
+						entry.Scope.Type,
+						entry.Scope.ID,
+						entry.Role,
+						entry.Scope.Type,
+					)
+				}
+				for _, r := range entry.Resources {
+					fmt.Printf("        - %s (%s)\n", r.ID, r.Type)
+				}
 			}
 		}
 
@@ -91,10 +120,19 @@ var hierarchyCmd = &cobra.Command{
 
 		// Print summary
 		fmt.Printf("\n%s %d principals with hierarchical access across %d bindings\n",
-			headerColor.Sprint("Summary:"), len(principals), len(result.HierarchicalAccess))
+			headerColor.Sprint("Summary:"), len(principals), boundEntries)
 	},
 }
 
+// formatScopePath renders the scopes a grant is inherited through, e.g. "organization '1' → folder '10' → project 'app'"
+func formatScopePath(path []analyzer.Scope) string {
+	parts := make([]string, len(path))
+	for i, scope := range path {
+		parts[i] = fmt.Sprintf("%s '%s'", scope.Type, scope.ID)
+	}
+	return strings.Join(parts, " → ")
+}
+
 func init() {
 	hierarchyCmd.Flags().StringVar(&tfvarsFile, "tfvars", "", "Path to terraform.tfvars file")
 	hierarchyCmd.Flags().StringVar(&planFile, "plan", "", "Path to terraform plan JSON file")
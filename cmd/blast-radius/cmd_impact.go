@@ -5,6 +5,7 @@ import (
 	"sort"
 
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +16,12 @@ var impactCmd = &cobra.Command{
 	Long:  `Analyzes Terraform files to determine the blast radius of IAM principals.`,
 	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		// Load Rules (Embedded or Custom)
+		if err := definitions.LoadRules(rulesFiles...); err != nil {
+			fmt.Printf("Error loading rules: %v\n", err)
+			return
+		}
+
 		analysis, err := setupAnalysis(args)
 		if err != nil {
 			fmt.Printf("Error setting up analysis: %v\n", err)
@@ -23,8 +30,22 @@ var impactCmd = &cobra.Command{
 
 		results := analyzer.Analyze(analysis.Bindings)
 
+		// Resources reached through project, folder and organization grants
+		hierarchy, err := analyzeExpandedHierarchy(analysis)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+		analyzer.MergeInheritedAccess(results, hierarchy)
+
 		if outputFormat == "json" {
-			jsonOut := output.ConvertToImpactOutput(results, analysis.Config.IsExcluded)
+			var scorer *analyzer.Scorer
+			if groups, err := loadGroups(analysis); err != nil {
+				warnNoScores(err)
+			} else {
+				scorer = attachedScores(analysis, groups, hierarchy)
+			}
+			jsonOut := output.ConvertToImpactOutput(results, analysis.Config.IsExcluded, scorer)
 			output.PrintJSON(jsonOut)
 			return
 		}
@@ -73,7 +94,11 @@ var impactCmd = &cobra.Command{
 				sort.Strings(validRoles)
 
 				for _, r := range validRoles {
-					fmt.Printf("      - %s\n", r)
+					if scope, ok := meta.InheritedFrom[r]; ok {
+						fmt.Printf("      - %s (inherited from %s '%s')\n", r, scope.Type, scope.ID)
+					} else {
+						fmt.Printf("      - %s\n", r)
+					}
 				}
 			}
 		}
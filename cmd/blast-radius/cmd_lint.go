@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/config"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/output"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/policy"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/schema"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintPolicyFiles []string
+	lintBuiltin     bool
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check configuration, definitions, rules and policy files for mistakes",
+	Long: `Strictly validates the blast-radius configuration file, the --definitions and --rules overlays
+and any --policy files. Reports unknown fields, invalid enum values, invalid regular expressions
+and duplicate entries with their file, line and column.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var files []string
+		var issues []schema.Issue
+
+		if _, err := os.Stat(configPath); err == nil {
+			files = append(files, configPath)
+			issues = append(issues, config.LintFile(configPath)...)
+		}
+		for _, path := range definitionsFiles {
+			files = append(files, path)
+			issues = append(issues, definitions.LintDefinitionsFile(path)...)
+		}
+		for _, path := range rulesFiles {
+			files = append(files, path)
+			issues = append(issues, definitions.LintRulesFile(path)...)
+		}
+		for _, path := range lintPolicyFiles {
+			files = append(files, path)
+			issues = append(issues, policy.LintPolicyFile(path)...)
+		}
+		if lintBuiltin {
+			files = append(files, "builtin:rules.yaml", "builtin:resources.yaml")
+			issues = append(issues, definitions.LintBuiltin()...)
+		}
+
+		if outputFormat == "json" {
+			output.PrintJSON(output.ConvertToLintOutput(files, issues))
+			if len(issues) > 0 {
+				os.Exit(1)
+			}
+			return
+		}
+
+		if len(files) == 0 {
+			fmt.Println("No files to lint. Specify --policy, --definitions, --rules or --builtin.")
+			return
+		}
+
+		_, _ = headerColor.Println("\n--- Lint Results ---")
+		for _, f := range files {
+			fmt.Printf("  checked %s\n", f)
+		}
+
+		if len(issues) == 0 {
+			color.Green("\nNo issues found.")
+			return
+		}
+
+		fmt.Println()
+		for _, issue := range issues {
+			fmt.Printf("%s\n", issue.String())
+		}
+		color.Red("\n%d issue(s) found.", len(issues))
+		os.Exit(1)
+	},
+}
+
+func init() {
+	lintCmd.Flags().StringSliceVar(&lintPolicyFiles, "policy", nil, "Policy file(s) to lint")
+	lintCmd.Flags().BoolVar(&lintBuiltin, "builtin", false, "Also lint the embedded definitions and rules")
+	rootCmd.AddCommand(lintCmd)
+}
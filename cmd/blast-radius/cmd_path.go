@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/output"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pathFrom     string
+	pathTo       string
+	pathMaxDepth int
+)
+
+var pathCmd = &cobra.Command{
+	Use:   "path --from <principal> --to <resource|principal> [directory]",
+	Short: "Explain every route from a principal to a resource or another principal",
+	Long: `Explains how a principal reaches a resource or another principal: every route, hop by hop,
+with the role, scope and condition of each binding and the Terraform address and source location
+it is declared at. When there is no route, the bindings that come closest to connecting them are listed.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load Rules (Embedded or Custom)
+		if err := definitions.LoadRules(rulesFiles...); err != nil {
+			fmt.Printf("Error loading rules: %v\n", err)
+			return
+		}
+
+		analysis, err := setupAnalysis(args)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+
+		directAccess, impGraph, err := analyzeAccess(analysis)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+
+		from := analyzer.ResolvePrincipal(pathFrom, directAccess)
+		if from == "" {
+			fmt.Printf("Principal '%s' not found in the configuration\n", pathFrom)
+			return
+		}
+		to := pathTo
+		if principal := analyzer.ResolvePrincipal(pathTo, directAccess); principal != "" {
+			to = principal
+		}
+
+		result := analyzer.FindRoutes(from, to, analysis.Bindings, directAccess, impGraph, pathMaxDepth)
+		bindings := bindingsByAddress(analysis.Bindings)
+
+		if outputFormat == "json" {
+			scorer := attachedScorer(analysis, directAccess, impGraph)
+			output.PrintJSON(output.ConvertToPathOutput(result, analysis.Bindings, analysis.SourceInfo, scorer))
+			return
+		}
+
+		_, _ = headerColor.Printf("\n--- Path: %s → %s ---\n", result.From, result.To)
+		if len(result.Routes) > 0 {
+			for i, route := range result.Routes {
+				printRoute(i+1, result.From, route, bindings)
+			}
+			if result.Truncated {
+				color.Yellow("\nOnly the first %d impersonation paths were followed; more routes may exist.", analyzer.MaxImpersonationPaths)
+			}
+			return
+		}
+
+		color.Yellow("\nNo route from %s to %s (max depth %d).", result.From, result.To, effectiveDepth(pathMaxDepth))
+		if len(result.Reachable) > 0 {
+			fmt.Println("\nAccounts it can reach:")
+			for _, principal := range result.Reachable {
+				fmt.Printf("  - %s\n", principalColor.Sprint(principal))
+			}
+		}
+		if len(result.NearMisses) == 0 {
+			fmt.Println("\nNo binding grants access to the target.")
+			return
+		}
+		fmt.Println("\nNearest bindings:")
+		for _, m := range result.NearMisses {
+			line := fmt.Sprintf("%s holds %s on %s", principalColor.Sprint(m.Principal), m.Role, m.Target)
+			if m.Reachable {
+				line = accessImpersonate.Sprint("[reachable] ") + line
+			}
+			fmt.Printf("  - %s%s\n", line, describeBinding(m.Binding, bindings))
+		}
+	},
+}
+
+// printRoute prints one route as numbered hops, each with the binding behind it
+func printRoute(n int, from string, route analyzer.Route, bindings map[string]parser.IAMBinding) {
+	kind := "direct"
+	switch {
+	case route.Impersonation != nil:
+		kind = "impersonation, " + chainLabels[route.Impersonation.ChainType]
+	case len(route.GroupPath) > 0:
+		kind = "group membership"
+	case route.Grant != nil:
+		kind = route.Grant.Via
+	}
+	_, _ = headerColor.Printf("\nRoute %d (%s):\n", n, kind)
+
+	step := 0
+	next := func(format string, a ...interface{}) {
+		step++
+		fmt.Printf("  %d. %s\n", step, fmt.Sprintf(format, a...))
+	}
+	member := from
+	for _, group := range route.GroupPath {
+		next("%s is a member of %s", member, group)
+		member = group
+	}
+	holder := from
+	if route.Impersonation != nil {
+		for _, edge := range route.Impersonation.Edges {
+			next("%s%s", formatEdge(edge), describeLocation(edge.SourceAddress, bindings))
+		}
+		holder = route.Impersonation.Chain[len(route.Impersonation.Chain)-1]
+	}
+	if g := route.Grant; g != nil {
+		line := fmt.Sprintf("%s holds %s on %s", holder, g.Role, g.ResourceID)
+		if g.InheritedFrom != nil {
+			line += fmt.Sprintf(", inherited from %s '%s'", g.InheritedFrom.Type, g.InheritedFrom.ID)
+		}
+		if len(g.GroupPath) > 0 {
+			line += fmt.Sprintf(", through membership of %s", strings.Join(g.GroupPath, " → "))
+		}
+		if b, ok := bindings[g.Address]; ok && b.Condition != nil {
+			line += fmt.Sprintf(", when %s", b.Condition.Expression)
+		}
+		next("%s%s", line, describeBinding(g.Address, bindings))
+	}
+}
+
+// describeBinding renders a binding's Terraform address and source location as a suffix
+func describeBinding(address string, bindings map[string]parser.IAMBinding) string {
+	if address == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)%s", address, describeLocation(address, bindings))
+}
+
+// describeLocation renders the source location of the binding at an address as a suffix, or ""
+func describeLocation(address string, bindings map[string]parser.IAMBinding) string {
+	if b, ok := bindings[address]; ok && b.Location != "" {
+		return fmt.Sprintf(" at %s", b.Location)
+	}
+	return ""
+}
+
+// bindingsByAddress indexes bindings by Terraform address
+func bindingsByAddress(bindings []parser.IAMBinding) map[string]parser.IAMBinding {
+	byAddr := make(map[string]parser.IAMBinding, len(bindings))
+	for _, b := range bindings {
+		if b.TerraformAddr != "" {
+			byAddr[b.TerraformAddr] = b
+		}
+	}
+	return byAddr
+}
+
+// effectiveDepth returns the hop limit paths are followed to for a --max-depth value
+func effectiveDepth(maxDepth int) int {
+	if maxDepth <= 0 {
+		return analyzer.DefaultMaxPathDepth
+	}
+	return maxDepth
+}
+
+func init() {
+	pathCmd.Flags().StringVar(&pathFrom, "from", "", "Principal the routes start from (e.g. user:alice@example.com)")
+	pathCmd.Flags().StringVar(&pathTo, "to", "", "Resource ID or principal the routes end at")
+	pathCmd.Flags().IntVar(&pathMaxDepth, "max-depth", analyzer.DefaultMaxPathDepth, "Maximum number of impersonation hops followed")
+	pathCmd.Flags().StringVar(&tfvarsFile, "tfvars", "", "Path to terraform.tfvars file")
+	pathCmd.Flags().StringVar(&planFile, "plan", "", "Path to terraform plan JSON file")
+	_ = pathCmd.MarkFlagRequired("from")
+	_ = pathCmd.MarkFlagRequired("to")
+	rootCmd.AddCommand(pathCmd)
+}
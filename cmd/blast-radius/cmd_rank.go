@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var rankTop int
+
+var rankCmd = &cobra.Command{
+	Use:   "rank [directory]",
+	Short: "Rank principals and resources by blast-radius score",
+	Long: `Scores every principal by what it can reach, directly or by impersonation: the access level of
+its roles, the breadth of the scopes they are granted on, the number of resources reached, sensitive
+resources, the service accounts it can impersonate and whether it is external or public. Resources are
+scored by the principals that reach them. The highest scores are listed first; weights and sensitive
+resources are set in the scoring section of the config.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load Rules (Embedded or Custom)
+		if err := definitions.LoadRules(rulesFiles...); err != nil {
+			fmt.Printf("Error loading rules: %v\n", err)
+			return
+		}
+
+		analysis, err := setupAnalysis(args)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+
+		scorer, err := scoreAnalysis(analysis)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+
+		out := output.ConvertToRankOutput(scorer, analysis.SourceInfo, rankTop)
+		if outputFormat == "json" {
+			output.PrintJSON(out)
+			return
+		}
+
+		_, _ = headerColor.Printf("\n--- Principals by Blast Radius (top %d of %d) ---\n\n", len(out.Principals), out.Summary.Principals)
+		for i, ps := range out.Principals {
+			fmt.Printf("%3d. %6.1f  %s\n", i+1, ps.Score, principalColor.Sprint(ps.Principal))
+			fmt.Printf("              %s\n", describePrincipalScore(ps))
+		}
+
+		_, _ = headerColor.Printf("\n--- Resources by Exposure (top %d of %d) ---\n\n", len(out.Resources), out.Summary.Resources)
+		for i, rs := range out.Resources {
+			fmt.Printf("%3d. %6.1f  %s (%s)\n", i+1, rs.Score, rs.ResourceID, rs.ResourceType)
+			fmt.Printf("              %s\n", describeResourceScore(rs))
+		}
+	},
+}
+
+// describePrincipalScore summarizes what a principal's score is made of
+func describePrincipalScore(ps analyzer.PrincipalScore) string {
+	var parts []string
+	if ps.TopAccessLevel != "" {
+		parts = append(parts, "access: "+colorizeAccessType(ps.TopAccessLevel), "broadest scope: "+ps.TopScope)
+	}
+	resources := fmt.Sprintf("resources: %d", ps.Resources)
+	if len(ps.SensitiveResources) > 0 {
+		resources += fmt.Sprintf(" (%d sensitive)", len(ps.SensitiveResources))
+	}
+	parts = append(parts, resources)
+	if ps.Accounts > 0 {
+		parts = append(parts, fmt.Sprintf("service accounts: %d", ps.Accounts))
+	}
+	if ps.External {
+		parts = append(parts, accessWrite.Sprint("external"))
+	}
+	if ps.Public {
+		parts = append(parts, accessAdmin.Sprint("public"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeResourceScore summarizes what a resource's score is made of
+func describeResourceScore(rs analyzer.ResourceScore) string {
+	parts := []string{fmt.Sprintf("principals: %d", rs.Principals)}
+	if rs.TopAccessLevel != "" {
+		parts = append(parts, "access: up to "+colorizeAccessType(rs.TopAccessLevel))
+	}
+	if len(rs.Tags) > 0 {
+		parts = append(parts, "tags: "+strings.Join(rs.Tags, ", "))
+	}
+	if rs.External > 0 {
+		parts = append(parts, accessWrite.Sprintf("external principals: %d", rs.External))
+	}
+	if rs.Public {
+		parts = append(parts, accessAdmin.Sprint("public"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	rankCmd.Flags().IntVar(&rankTop, "top", 20, "Number of principals and resources listed (0 for all)")
+	rankCmd.Flags().StringVar(&tfvarsFile, "tfvars", "", "Path to terraform.tfvars file")
+	rankCmd.Flags().StringVar(&planFile, "plan", "", "Path to terraform plan JSON file")
+	rootCmd.AddCommand(rankCmd)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/output"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/policy"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var recommendTerraformFile string
+
+var recommendCmd = &cobra.Command{
+	Use:   "recommend [directory]",
+	Short: "Suggest narrower roles for grants of broad roles",
+	Long: `For every grant of roles/owner, roles/editor or a service admin role (roles/*.admin), lists the declared
+resources it reaches and suggests the least privileged predefined roles that still cover them, bound on
+each resource or project rather than on the whole scope. A principal belonging to a persona policy of
+--policy needs the persona's required bindings; any other principal needs the access level of its broad
+role on every resource type it reaches. Each suggestion comes with a proposed Terraform binding;
+--terraform writes them to a file.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load Rules (Embedded or Custom)
+		if err := definitions.LoadRules(rulesFiles...); err != nil {
+			fmt.Printf("Error loading rules: %v\n", err)
+			return
+		}
+
+		analysis, err := setupAnalysis(args)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+
+		tree, err := buildResourceTree(analysis)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+		inventory := findInventory(analysis)
+		result := analyzer.AnalyzeHierarchyWithTree(analysis.Bindings, tree)
+		analyzer.ExpandHierarchicalAccess(result, inventory)
+
+		var personas []analyzer.Persona
+		if policyFile != "" {
+			policyConfig, err := policy.LoadPolicies(policyFile)
+			if err != nil {
+				fmt.Printf("Error loading policy: %v\n", err)
+				return
+			}
+			personas = personasFromPolicies(policyConfig)
+		}
+		recs := analyzer.Recommend(analysis.Bindings, result, inventory, analysis.Defs, personas)
+
+		if recommendTerraformFile != "" {
+			if err := os.WriteFile(recommendTerraformFile, []byte(proposedTerraform(recs)), 0644); err != nil {
+				fmt.Printf("Error writing Terraform: %v\n", err)
+				return
+			}
+		}
+
+		if outputFormat == "json" {
+			var scorer *analyzer.Scorer
+			if groups, err := loadGroups(analysis); err != nil {
+				warnNoScores(err)
+			} else {
+				scorer = attachedScores(analysis, groups, result)
+			}
+			output.PrintJSON(output.ConvertToRecommendOutput(recs, analysis.SourceInfo, scorer))
+			return
+		}
+
+		_, _ = headerColor.Println("\n--- Least-Privilege Recommendations ---")
+		if len(recs) == 0 {
+			color.Green("\nNo grants of broad roles found.")
+			return
+		}
+
+		principal := ""
+		suggestions := 0
+		for _, r := range recs {
+			if r.Principal != principal {
+				principal = r.Principal
+				fmt.Printf("\n%s\n", principalColor.Sprint(r.Principal))
+			}
+			fmt.Printf("  %s on %s '%s' %s\n", accessAdmin.Sprint(r.Role), r.Scope.Type, r.Scope.ID, describeNarrowedBinding(r))
+			switch {
+			case r.Required:
+				fmt.Printf("    required by persona %s\n", r.Persona)
+				continue
+			case len(r.Suggestions) == 0 && len(r.Uncovered) == 0:
+				fmt.Println("    reaches nothing declared it needs; remove the binding if nothing undeclared depends on it")
+				continue
+			case r.Persona != "":
+				fmt.Printf("    needs the bindings of persona %s on %d declared resource(s)\n", r.Persona, len(r.Resources))
+			default:
+				fmt.Printf("    needs %s on %d declared resource(s)\n", colorizeAccessType(r.AccessLevel), len(r.Resources))
+			}
+			for _, s := range r.Suggestions {
+				line := fmt.Sprintf("    → %s on %s '%s'", s.Role, s.Scope.Type, s.Scope.ID)
+				if len(s.Resources) > 0 {
+					line += " for " + strings.Join(s.Resources, ", ")
+				}
+				fmt.Println(line)
+			}
+			if len(r.Uncovered) > 0 {
+				fmt.Printf("    %s %s\n", accessWrite.Sprint("no narrower role covers:"), strings.Join(r.Uncovered, ", "))
+			}
+			suggestions += len(r.Suggestions)
+		}
+
+		if snippets := proposedTerraform(recs); snippets != "" {
+			_, _ = headerColor.Println("\n--- Proposed Terraform ---")
+			fmt.Printf("\n%s", snippets)
+		}
+		fmt.Printf("\n%d recommendation(s) for %d principal(s), %d suggested binding(s)\n", len(recs), countRecommendedPrincipals(recs), suggestions)
+		if recommendTerraformFile != "" {
+			fmt.Printf("Proposed bindings written to %s\n", recommendTerraformFile)
+		}
+	},
+}
+
+// describeNarrowedBinding returns the address, location and condition of the binding a recommendation narrows
+func describeNarrowedBinding(r analyzer.Recommendation) string {
+	desc := fmt.Sprintf("(%s)", r.Binding)
+	if r.Location != "" {
+		desc += fmt.Sprintf(" at %s", r.Location)
+	}
+	if r.Condition != "" {
+		desc += fmt.Sprintf(", when %s", r.Condition)
+	}
+	return desc
+}
+
+// proposedTerraform joins the proposed bindings of every suggestion, each preceded by a comment naming the
+// grant it narrows
+func proposedTerraform(recs []analyzer.Recommendation) string {
+	var blocks []string
+	for _, r := range recs {
+		for _, s := range r.Suggestions {
+			if s.Terraform == "" {
+				continue
+			}
+			blocks = append(blocks, fmt.Sprintf("# Narrows %s on %s '%s' (%s)\n%s", r.Role, r.Scope.Type, r.Scope.ID, r.Binding, s.Terraform))
+		}
+	}
+	return strings.Join(blocks, "\n")
+}
+
+// personasFromPolicies returns the personas of the persona policies of a policy file, with the bindings they
+// require. Required roles given as at_least:<role> are narrowed to that role; permission expressions are skipped.
+func personasFromPolicies(cfg *policy.PolicyConfig) []analyzer.Persona {
+	var personas []analyzer.Persona
+	for _, p := range cfg.Policies {
+		if p.Type != policy.PolicyTypePersona || p.Persona == nil {
+			continue
+		}
+		name := p.Persona.PersonaName
+		if name == "" {
+			name = p.Name
+		}
+		principals := p.Persona.Principals
+		persona := analyzer.Persona{Name: name, Matches: func(principal string) bool { return policy.IsPrincipalIn(principal, principals) }}
+		for _, required := range p.Persona.RequiredBindings {
+			role := strings.TrimPrefix(required.Role, policy.RoleExprAtLeast)
+			if strings.HasPrefix(role, policy.RoleExprPermission) {
+				continue
+			}
+			pattern := required.ResourcePattern
+			persona.Required = append(persona.Required, analyzer.PersonaBinding{
+				Role:          role,
+				ResourceType:  required.ResourceType,
+				MatchResource: func(id string) bool { return policy.MatchesResourcePattern(id, pattern) },
+			})
+		}
+		personas = append(personas, persona)
+	}
+	return personas
+}
+
+// countRecommendedPrincipals returns the number of distinct principals with recommendations
+func countRecommendedPrincipals(recs []analyzer.Recommendation) int {
+	seen := make(map[string]bool)
+	for _, r := range recs {
+		seen[r.Principal] = true
+	}
+	return len(seen)
+}
+
+func init() {
+	recommendCmd.Flags().StringVar(&recommendTerraformFile, "terraform", "", "Write the proposed bindings to this Terraform file")
+	recommendCmd.Flags().StringVar(&policyFile, "policy", "", "Policy file whose persona policies describe what principals need")
+	recommendCmd.Flags().StringVar(&tfvarsFile, "tfvars", "", "Path to terraform.tfvars file")
+	recommendCmd.Flags().StringVar(&planFile, "plan", "", "Path to terraform plan JSON file")
+	rootCmd.AddCommand(recommendCmd)
+}
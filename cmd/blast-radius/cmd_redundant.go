@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/output"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var redundantCmd = &cobra.Command{
+	Use:   "redundant [directory]",
+	Short: "Find bindings that add no access",
+	Long: `Finds grants that can be removed without changing effective access: the same role, scope and condition
+granted by two bindings (e.g. duplicate _iam_members across files), the same role granted on a resource and
+on its project, folder or organization, a role included in a broader one on the same or an ancestor scope,
+and roles a principal already holds through one of its groups. Each grant is reported with the source
+address of the binding making it redundant. Conditional grants only cover grants with the same condition.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load Rules (Embedded or Custom)
+		if err := definitions.LoadRules(rulesFiles...); err != nil {
+			fmt.Printf("Error loading rules: %v\n", err)
+			return
+		}
+
+		analysis, err := setupAnalysis(args)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+
+		groups, err := loadGroups(analysis)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+		parents, err := hierarchyParents(analysis)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+		redundant := analyzer.FindRedundantGrantsWithParents(analysis.Bindings, groups, parents)
+
+		if outputFormat == "json" {
+			var scorer *analyzer.Scorer
+			if hierarchy, err := analyzeExpandedHierarchy(analysis); err != nil {
+				warnNoScores(err)
+			} else {
+				scorer = attachedScores(analysis, groups, hierarchy)
+			}
+			output.PrintJSON(output.ConvertToRedundantOutput(redundant, analysis.SourceInfo, scorer))
+			return
+		}
+
+		_, _ = headerColor.Println("\n--- Redundant Bindings ---")
+		if len(redundant) == 0 {
+			color.Green("\nNo redundant bindings found.")
+			return
+		}
+
+		principal := ""
+		principals := 0
+		for _, r := range redundant {
+			if r.Principal != principal {
+				principal = r.Principal
+				principals++
+				fmt.Printf("\n%s\n", principalColor.Sprint(r.Principal))
+			}
+			fmt.Printf("  %s on %s '%s' %s\n", r.Grant.Role, r.Grant.ScopeType, r.Grant.ScopeID, accessWrite.Sprintf("[%s]", r.Kind))
+			fmt.Printf("    redundant:  %s\n", describeGrantRef(r.Grant))
+			fmt.Printf("    %s %s\n", describeCover(r), describeGrantRef(r.CoveredBy))
+		}
+		fmt.Printf("\n%d redundant grant(s) for %d principal(s)\n", len(redundant), principals)
+	},
+}
+
+// describeCover explains how the covering grant of a redundant grant gives its access
+func describeCover(r analyzer.RedundantGrant) string {
+	switch r.Kind {
+	case analyzer.RedundantDuplicate:
+		return "duplicates:"
+	case analyzer.RedundantGroup:
+		return fmt.Sprintf("held through %s: %s on %s '%s',", strings.Join(r.ViaGroups, " → "), r.CoveredBy.Role, r.CoveredBy.ScopeType, r.CoveredBy.ScopeID)
+	}
+	return fmt.Sprintf("covered by: %s on %s '%s',", r.CoveredBy.Role, r.CoveredBy.ScopeType, r.CoveredBy.ScopeID)
+}
+
+// describeGrantRef returns the address, location and condition of the binding behind a grant
+func describeGrantRef(g analyzer.GrantRef) string {
+	desc := g.ResourceAddress
+	if g.Location != "" {
+		desc += fmt.Sprintf(" at %s", g.Location)
+	}
+	if g.Condition != "" {
+		desc += fmt.Sprintf(", when %s", g.Condition)
+	}
+	return desc
+}
+
+func init() {
+	redundantCmd.Flags().StringVar(&tfvarsFile, "tfvars", "", "Path to terraform.tfvars file")
+	redundantCmd.Flags().StringVar(&planFile, "plan", "", "Path to terraform plan JSON file")
+	rootCmd.AddCommand(redundantCmd)
+}
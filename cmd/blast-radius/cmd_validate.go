@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/output"
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/policy"
@@ -28,7 +27,7 @@ var validateCmd = &cobra.Command{
 		}
 
 		// Load Rules (Embedded or Custom)
-		if err := definitions.LoadRules(rulesFile); err != nil {
+		if err := definitions.LoadRules(rulesFiles...); err != nil {
 			fmt.Printf("Error loading rules: %v\n", err)
 			return
 		}
@@ -47,8 +46,11 @@ var validateCmd = &cobra.Command{
 
 		canImpersonate := definitions.GetCanImpersonateFunc()
 
-		directAccess := analyzer.Analyze(analysis.Bindings)
-		impGraph := analyzer.BuildImpersonationGraphWithFunc(analysis.Bindings, canImpersonate)
+		directAccess, impGraph, err := analyzeAccess(analysis)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			os.Exit(1)
+		}
 
 		policyConfig, err := policy.LoadPolicies(policyFile)
 		if err != nil {
@@ -64,7 +66,7 @@ var validateCmd = &cobra.Command{
 		}
 
 		if outputFormat == "json" {
-			jsonOut := output.ConvertToValidateOutput(report)
+			jsonOut := output.ConvertToValidateOutput(report, attachedScorer(analysis, directAccess, impGraph))
 			output.PrintJSON(jsonOut)
 			if report.ErrorCount > 0 {
 				if strictMode && report.WarningCount > 0 {
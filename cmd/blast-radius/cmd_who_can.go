@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/output"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/policy"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	whoCanRole       string
+	whoCanPermission string
+	whoCanMaxDepth   int
+)
+
+var whoCanCmd = &cobra.Command{
+	Use:   "who-can <resource-pattern> [directory]",
+	Short: "List every principal that can reach a resource, and how",
+	Long: `Lists the principals holding a role on the resources whose ID matches the pattern (a regex):
+through a binding on the resource, through a group they belong to, through a grant on the
+resource's project, folder or organization, or by impersonating a service account that can.
+Each principal is shown with the binding, group path, scope or impersonation chain behind its access.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load Rules (Embedded or Custom)
+		if err := definitions.LoadRules(rulesFiles...); err != nil {
+			fmt.Printf("Error loading rules: %v\n", err)
+			return
+		}
+
+		resourcePattern := args[0]
+		analysis, err := setupAnalysis(args[1:])
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+
+		directAccess, impGraph, err := analyzeAccess(analysis)
+		if err != nil {
+			fmt.Printf("Error setting up analysis: %v\n", err)
+			return
+		}
+
+		matchResource := func(id string) bool { return policy.MatchesResourcePattern(id, resourcePattern) }
+		matchRole := func(role string) bool {
+			if whoCanRole != "" && !policy.MatchesRoleExpression(role, whoCanRole) {
+				return false
+			}
+			return whoCanPermission == "" || definitions.RoleMayGrantPermission(role, whoCanPermission)
+		}
+		accessors := analyzer.WhoCan(analysis.Bindings, directAccess, impGraph, matchResource, matchRole, whoCanMaxDepth)
+		if whoCanPermission != "" {
+			for i := range accessors {
+				accessors[i].Unverified = !definitions.RoleHasPermission(accessors[i].Role, whoCanPermission)
+			}
+		}
+
+		if outputFormat == "json" {
+			scorer := attachedScorer(analysis, directAccess, impGraph)
+			output.PrintJSON(output.ConvertToWhoCanOutput(accessors, analysis.SourceInfo, resourcePattern, whoCanRole, whoCanPermission, scorer))
+			return
+		}
+
+		_, _ = headerColor.Printf("\n--- Who Can Reach '%s' ---\n", resourcePattern)
+		if len(accessors) == 0 {
+			color.Green("\nNo principal can reach a matching resource.")
+			return
+		}
+
+		resource, principal := "", ""
+		for _, a := range accessors {
+			if a.ResourceID != resource {
+				resource, principal = a.ResourceID, ""
+				_, _ = headerColor.Printf("\n=== %s (%s) ===\n", a.ResourceID, a.ResourceType)
+			}
+			if a.Principal != principal {
+				principal = a.Principal
+				fmt.Printf("  %s\n", principalColor.Sprint(a.Principal))
+			}
+			fmt.Printf("      %s\n", formatAccessor(a))
+			for i, edge := range a.Edges {
+				fmt.Printf("          %d. %s\n", i+1, formatEdge(edge))
+			}
+		}
+	},
+}
+
+// formatAccessor describes how a principal holds a role on a resource
+func formatAccessor(a analyzer.ResourceAccessor) string {
+	line := fmt.Sprintf("%s %s", a.Role, accessImpersonate.Sprintf("[%s]", a.Via))
+	if a.InheritedFrom != nil {
+		line += fmt.Sprintf(" from %s '%s'", a.InheritedFrom.Type, a.InheritedFrom.ID)
+	}
+	if len(a.GroupPath) > 0 {
+		line += fmt.Sprintf(" through membership of %s", strings.Join(a.GroupPath, " → "))
+	}
+	if len(a.Chain) > 0 {
+		line += fmt.Sprintf(" via chain (%s): %s", chainLabels[a.ChainType], strings.Join(a.Chain, " → "))
+	}
+	if a.Address != "" {
+		line += fmt.Sprintf(" (%s)", a.Address)
+	}
+	if a.Unverified {
+		line += " " + accessWrite.Sprint("[UNVERIFIED]")
+	}
+	return line
+}
+
+func init() {
+	whoCanCmd.Flags().StringVar(&whoCanRole, "role", "", "Only report roles matching this pattern (regex, at_least:<role> or permission:<perm>)")
+	whoCanCmd.Flags().StringVar(&whoCanPermission, "permission", "", "Only report roles that may grant this permission (e.g. storage.objects.delete)")
+	whoCanCmd.Flags().IntVar(&whoCanMaxDepth, "max-depth", analyzer.DefaultMaxPathDepth, "Maximum number of impersonation hops followed")
+	whoCanCmd.Flags().StringVar(&tfvarsFile, "tfvars", "", "Path to terraform.tfvars file")
+	whoCanCmd.Flags().StringVar(&planFile, "plan", "", "Path to terraform plan JSON file")
+	rootCmd.AddCommand(whoCanCmd)
+}
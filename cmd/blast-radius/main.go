@@ -24,7 +24,10 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&configPath, "config", "blast-radius.yaml", "config file (default is blast-radius.yaml)")
 	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", "text", "Output format: text or json")
 
-	// Custom definitions flags (Optional)
-	rootCmd.PersistentFlags().StringVar(&definitionsFile, "definitions", "", "Path to custom resource definitions file")
-	rootCmd.PersistentFlags().StringVar(&rulesFile, "rules", "", "Path to custom validation rules file")
+	// Custom definitions flags (Optional, repeatable overlays applied in order)
+	rootCmd.PersistentFlags().StringSliceVar(&definitionsFiles, "definitions", nil, "Path(s) to resource definition overlay files")
+	rootCmd.PersistentFlags().StringSliceVar(&rulesFiles, "rules", nil, "Path(s) to role rules overlay files")
+
+	// Group memberships export (Optional, overrides groups_file in the config)
+	rootCmd.PersistentFlags().StringVar(&groupsFile, "groups", "", "Path to a YAML or CSV export of group memberships")
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/config"
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/output"
@@ -15,12 +16,13 @@ import (
 
 // Shared Globals
 var (
-	configPath      string
-	outputFormat    string
-	definitionsFile string
-	rulesFile       string
-	tfvarsFile      string
-	planFile        string
+	configPath       string
+	outputFormat     string
+	definitionsFiles []string
+	rulesFiles       []string
+	tfvarsFile       string
+	planFile         string
+	groupsFile       string
 )
 
 // Color definitions for output
@@ -67,6 +69,11 @@ type AnalysisResult struct {
 	Config     *config.Config
 	Defs       []parser.ResourceDefinition
 	SourceInfo output.SourceInfo
+	Source     *parser.Source // Terraform input, loaded once and shared by the analyses
+
+	resources []parser.ResourceInstance // resources of analysisResourceTypes, see parseResources
+	parsed    bool
+	parents   map[string]string // scope parents, see hierarchyParents
 }
 
 func setupAnalysis(args []string) (*AnalysisResult, error) {
@@ -77,7 +84,7 @@ func setupAnalysis(args []string) (*AnalysisResult, error) {
 
 	cfg, err := config.Load(configPath)
 	if err != nil {
-		cfg = &config.Config{}
+		return nil, fmt.Errorf("error loading config:\n%v", err)
 	}
 
 	if outputFormat == "text" {
@@ -97,23 +104,23 @@ func setupAnalysis(args []string) (*AnalysisResult, error) {
 	}
 
 	// Load Resource Definitions (Embedded or Custom)
-	defs, err := definitions.LoadResourceDefinitions(definitionsFile)
+	defs, err := definitions.LoadResourceDefinitions(definitionsFiles...)
 	if err != nil {
 		return nil, fmt.Errorf("error loading resource definitions: %v", err)
 	}
 
-	// Parse Terraform files or plan
-	var bindings []parser.IAMBinding
+	// Load the Terraform files or plan once; every analysis parses from it
+	var source *parser.Source
 	var sourceInfo output.SourceInfo
 
 	if planFile != "" {
-		bindings, err = parser.ParsePlanFile(planFile, defs)
+		source, err = parser.LoadPlan(planFile)
 		if err != nil {
 			return nil, fmt.Errorf("error parsing plan file: %v", err)
 		}
 		sourceInfo = output.SourceInfo{Type: "plan_file", Path: planFile, InputMode: "plan_json"}
 	} else {
-		bindings, err = parser.ParseDir(dir, tfvarsFile, defs, cfg.IgnoredDirectories)
+		source, err = parser.LoadDir(dir, tfvarsFile, cfg.IgnoredDirectories)
 		if err != nil {
 			return nil, fmt.Errorf("error parsing directory: %v", err)
 		}
@@ -121,9 +128,257 @@ func setupAnalysis(args []string) (*AnalysisResult, error) {
 	}
 
 	return &AnalysisResult{
-		Bindings:   bindings,
+		Bindings:   source.Bindings(defs),
 		Config:     cfg,
 		Defs:       defs,
 		SourceInfo: sourceInfo,
+		Source:     source,
 	}, nil
 }
+
+// analysisResourceTypes returns the types of every resource the analyses read: service accounts, keys,
+// workloads, projects and folders, inventory, workload identity pools and groups
+func analysisResourceTypes() []string {
+	var types []string
+	for _, group := range [][]string{
+		analyzer.KeyResourceTypes,
+		analyzer.WorkloadResourceTypes(definitions.GetWorkloadDefinitions()),
+		analyzer.HierarchyResourceTypes,
+		analyzer.InventoryResourceTypes(definitions.GetInventoryDefinitions()),
+		analyzer.IdentityPoolResourceTypes,
+		analyzer.GroupResourceTypes,
+	} {
+		types = append(types, group...)
+	}
+	return types
+}
+
+// parseResources returns the resources of the given types. The resources of every analysis are parsed
+// together the first time and shared by the analyses that follow.
+func parseResources(analysis *AnalysisResult, types []string) []parser.ResourceInstance {
+	if !analysis.parsed {
+		analysis.resources = analysis.Source.Resources(analysisResourceTypes())
+		analysis.parsed = true
+	}
+	var resources []parser.ResourceInstance
+	for _, r := range analysis.resources {
+		if containsMode(types, r.Type) {
+			resources = append(resources, r)
+		}
+	}
+	return resources
+}
+
+// buildImpersonationGraph builds the impersonation graph of an analysis, including Terraform-declared
+// service accounts, service account keys and workloads that run as a service account
+func buildImpersonationGraph(analysis *AnalysisResult, parents map[string]string) *analyzer.ImpersonationGraph {
+	canImpersonate := definitions.GetCanImpersonateFunc()
+	graph := analyzer.BuildImpersonationGraphWithParents(analysis.Bindings, canImpersonate, parents)
+
+	workloadDefs := definitions.GetWorkloadDefinitions()
+	types := append(append([]string{}, analyzer.KeyResourceTypes...), analyzer.WorkloadResourceTypes(workloadDefs)...)
+	resources := parseResources(analysis, types)
+	graph.AddServiceAccounts(resources, analysis.Bindings, canImpersonate)
+	graph.AddKeys(analyzer.FindServiceAccountKeys(resources))
+	graph.AddWorkloadEdges(analyzer.FindWorkloads(resources, workloadDefs), analysis.Bindings, canImpersonate)
+	return graph
+}
+
+// buildResourceTree reconstructs the organization, folder and project tree of an analysis from its
+// google_folder and google_project declarations and the hierarchy section of the config; the scopes of its
+// bindings are added by the analyses
+func buildResourceTree(analysis *AnalysisResult) (*analyzer.ResourceTree, error) {
+	resources := parseResources(analysis, analyzer.HierarchyResourceTypes)
+	links, err := analysis.Config.HierarchyLinks()
+	if err != nil {
+		return nil, fmt.Errorf("error loading config:\n%v", err)
+	}
+	tree := analyzer.NewResourceTree()
+	for _, node := range analyzer.FindHierarchyNodes(resources) {
+		tree.AddNode(node)
+	}
+	// The hierarchy section fills in the parts of the tree managed outside this configuration
+	for _, link := range links {
+		tree.AddNode(analyzer.HierarchyNode{ID: link.ChildID, Type: link.ChildType, ParentID: link.ParentID, ParentType: link.ParentType})
+	}
+	return tree, nil
+}
+
+// hierarchyParents returns the parents of the projects, folders, organizations and resources of an analysis:
+// those its bindings record, filled in from the resource tree. Every analysis inheriting grants down the
+// hierarchy is given this one map.
+func hierarchyParents(analysis *AnalysisResult) (map[string]string, error) {
+	if analysis.parents == nil {
+		tree, err := buildResourceTree(analysis)
+		if err != nil {
+			return nil, err
+		}
+		analysis.parents = analyzer.ParentMap(analysis.Bindings, tree)
+	}
+	return analysis.parents, nil
+}
+
+// findInventory returns the resources of the inventory types declared in the analysis
+func findInventory(analysis *AnalysisResult) []analyzer.InventoryResource {
+	inventoryDefs := definitions.GetInventoryDefinitions()
+	resources := parseResources(analysis, analyzer.InventoryResourceTypes(inventoryDefs))
+	return analyzer.FindInventory(resources, inventoryDefs)
+}
+
+// analyzeExpandedHierarchy runs the hierarchy analysis against the reconstructed resource tree and
+// lists the inventory resources each project-level grant reaches
+func analyzeExpandedHierarchy(analysis *AnalysisResult) (*analyzer.HierarchyAnalysisResult, error) {
+	tree, err := buildResourceTree(analysis)
+	if err != nil {
+		return nil, err
+	}
+	inventory := findInventory(analysis)
+	result := analyzer.AnalyzeHierarchyWithTree(analysis.Bindings, tree)
+	analyzer.ExpandHierarchicalAccess(result, inventory)
+	return result, nil
+}
+
+// findExternalIdentities returns the federated and GKE Workload Identity members of an analysis,
+// with the workload identity pool providers declared alongside them
+func findExternalIdentities(analysis *AnalysisResult) []analyzer.ExternalIdentity {
+	resources := parseResources(analysis, analyzer.IdentityPoolResourceTypes)
+	return analyzer.FindExternalIdentities(analysis.Bindings, analyzer.FindIdentityProviders(resources))
+}
+
+// loadGroups collects group memberships from Cloud Identity resources in the analysis and from the
+// groups file given by --groups or groups_file. It returns nil when there are none.
+func loadGroups(analysis *AnalysisResult) (*analyzer.GroupMemberships, error) {
+	raw := analyzer.FindGroupMemberships(parseResources(analysis, analyzer.GroupResourceTypes))
+
+	path := groupsFile
+	if path == "" {
+		path = analysis.Config.GroupsFile
+	}
+	if path != "" {
+		fileGroups, err := config.LoadGroups(path)
+		if err != nil {
+			return nil, err
+		}
+		for group, members := range fileGroups {
+			raw[group] = append(raw[group], members...)
+		}
+	}
+
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	return analyzer.NewGroupMemberships(raw), nil
+}
+
+// analyzeAccess returns the direct access of every principal, with group access attributed to members
+// and deny policies applied, and the impersonation graph of an analysis with its hierarchy entries
+func analyzeAccess(analysis *AnalysisResult) (map[string]*analyzer.PrincipalData, *analyzer.ImpersonationGraph, error) {
+	groups, err := loadGroups(analysis)
+	if err != nil {
+		return nil, nil, err
+	}
+	hierarchy, err := analyzeExpandedHierarchy(analysis)
+	if err != nil {
+		return nil, nil, err
+	}
+	return analyzeAccessWith(analysis, groups, hierarchy)
+}
+
+// analyzeAccessWith is analyzeAccess for commands that already loaded the groups and hierarchy of an analysis
+func analyzeAccessWith(analysis *AnalysisResult, groups *analyzer.GroupMemberships, hierarchy *analyzer.HierarchyAnalysisResult) (map[string]*analyzer.PrincipalData, *analyzer.ImpersonationGraph, error) {
+	parents, err := hierarchyParents(analysis)
+	if err != nil {
+		return nil, nil, err
+	}
+	impGraph := buildImpersonationGraph(analysis, parents)
+	impGraph.AddGroups(groups)
+	impGraph.AddHierarchy(hierarchy)
+	directAccess := analyzer.AnalyzeWithGroups(analysis.Bindings, groups)
+
+	if rules := analysis.Source.DenyPolicies(); len(rules) > 0 {
+		deny := analyzer.NewDenyPoliciesWithParents(rules, parents, groups)
+		analyzer.ApplyDenyPolicies(directAccess, deny)
+		impGraph.AddDenyPolicies(deny)
+	}
+	return directAccess, impGraph, nil
+}
+
+// newScorer scores the principals and resources of an analysis, with the weights and sensitive resources of its config
+func newScorer(analysis *AnalysisResult, directAccess map[string]*analyzer.PrincipalData, graph *analyzer.ImpersonationGraph) (*analyzer.Scorer, error) {
+	scoring := analysis.Config.Scoring
+	weights := analyzer.DefaultScoreWeights()
+	override := func(key string, w *float64, into map[string]float64) {
+		if w != nil {
+			into[key] = *w
+		}
+	}
+	override("read", scoring.Weights.AccessLevels.Read, weights.AccessLevels)
+	override("write", scoring.Weights.AccessLevels.Write, weights.AccessLevels)
+	override("admin", scoring.Weights.AccessLevels.Admin, weights.AccessLevels)
+	override("impersonate", scoring.Weights.AccessLevels.Impersonate, weights.AccessLevels)
+	override("organization", scoring.Weights.Scopes.Organization, weights.Scopes)
+	override("folder", scoring.Weights.Scopes.Folder, weights.Scopes)
+	override("project", scoring.Weights.Scopes.Project, weights.Scopes)
+	override("resource", scoring.Weights.Scopes.Resource, weights.Scopes)
+	for _, w := range []struct {
+		value *float64
+		into  *float64
+	}{
+		{scoring.Weights.ReachableResource, &weights.ReachableResource},
+		{scoring.Weights.Sensitive, &weights.Sensitive},
+		{scoring.Weights.Impersonation, &weights.Impersonation},
+		{scoring.Weights.External, &weights.External},
+		{scoring.Weights.Public, &weights.Public},
+	} {
+		if w.value != nil {
+			*w.into = *w.value
+		}
+	}
+
+	var tags []analyzer.SensitivityTag
+	for _, rule := range scoring.SensitiveResources {
+		tags = append(tags, analyzer.SensitivityTag{Resource: rule.Resource, Tag: rule.Tag})
+	}
+	scorer, err := analyzer.NewScorer(weights, tags, analysis.Bindings, directAccess, graph)
+	if err != nil {
+		return nil, fmt.Errorf("error loading config:\n%v", err)
+	}
+	return scorer, nil
+}
+
+// scoreAnalysis analyzes the access of an analysis and scores it, for commands that do not otherwise need the
+// impersonation graph
+func scoreAnalysis(analysis *AnalysisResult) (*analyzer.Scorer, error) {
+	directAccess, impGraph, err := analyzeAccess(analysis)
+	if err != nil {
+		return nil, err
+	}
+	return newScorer(analysis, directAccess, impGraph)
+}
+
+// attachedScorer is newScorer for commands that only attach scores to their output: when scoring fails
+// it warns on stderr and returns nil, which leaves the scores out
+func attachedScorer(analysis *AnalysisResult, directAccess map[string]*analyzer.PrincipalData, graph *analyzer.ImpersonationGraph) *analyzer.Scorer {
+	scorer, err := newScorer(analysis, directAccess, graph)
+	if err != nil {
+		warnNoScores(err)
+		return nil
+	}
+	return scorer
+}
+
+// attachedScores analyzes access with the groups and hierarchy a command already loaded and scores it,
+// for commands that only attach scores to their output. Failures leave the scores out, see attachedScorer.
+func attachedScores(analysis *AnalysisResult, groups *analyzer.GroupMemberships, hierarchy *analyzer.HierarchyAnalysisResult) *analyzer.Scorer {
+	directAccess, impGraph, err := analyzeAccessWith(analysis, groups, hierarchy)
+	if err != nil {
+		warnNoScores(err)
+		return nil
+	}
+	return attachedScorer(analysis, directAccess, impGraph)
+}
+
+// warnNoScores reports on stderr why a command leaves the scores out of its output
+func warnNoScores(err error) {
+	fmt.Fprintf(os.Stderr, "Warning: leaving out scores: %v\n", err)
+}
@@ -9,13 +9,16 @@ import (
 type PrincipalData struct {
 	ResourceAccess     map[string]*ResourceMetadata // resourceID -> metadata
 	HierarchicalAccess map[string]map[string]bool   // projectID -> resourceType -> true
+	Denied             []DeniedAccess               // granted roles deny policies revoke or narrow, see ApplyDenyPolicies
 }
 
 // ResourceMetadata holds access details for a specific resource
 type ResourceMetadata struct {
 	Type           string
 	Roles          map[string]bool
-	TerraformAddrs map[string]string // role -> terraform address
+	TerraformAddrs map[string]string   // role -> terraform address
+	GroupPaths     map[string][]string // role -> groups it is held through, innermost first; nil for the principal's own grants
+	InheritedFrom  map[string]Scope    // role -> project, folder or organization it is granted on, for access merged by MergeInheritedAccess
 }
 
 // Analyze processes IAM bindings and groups them by principal
@@ -0,0 +1,212 @@
+package analyzer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// iamTypeMarkers are resource type substrings that suggest a resource manages access
+var iamTypeMarkers = []string{"_iam_", "_access", "_acl"}
+
+// builtinIgnoredTypes are IAM-like resource types read for something other than bindings, so they need no definition
+var builtinIgnoredTypes = map[string]bool{
+	"google_iam_deny_policy":                     true, // deny rules, see parser.ParseDenyPolicies
+	"google_iam_workload_identity_pool":          true, // identity providers, see FindIdentityProviders
+	"google_iam_workload_identity_pool_provider": true,
+}
+
+// resourceIDCandidates are attribute names tried, in order, when guessing a stub's resource_id
+var resourceIDCandidates = []string{"name", "resource", "resource_id", "service", "instance", "repository", "bucket", "project"}
+
+// UncoveredResourceType is an IAM-like resource type that has no resource definition
+type UncoveredResourceType struct {
+	Type      string                    `json:"type"`
+	Count     int                       `json:"count"`
+	Addresses []string                  `json:"addresses"`
+	Stub      parser.ResourceDefinition `json:"stub"`
+}
+
+// UnknownRole is a role bound at organization, folder or project level that has no rule
+type UnknownRole struct {
+	Role      string                    `json:"role"`
+	Count     int                       `json:"count"`
+	Addresses []string                  `json:"addresses"`
+	Stub      definitions.RoleHierarchy `json:"stub"`
+}
+
+// CoverageResult reports resources and roles that the loaded definitions do not cover
+type CoverageResult struct {
+	ResourcesScanned int                     `json:"resources_scanned"`
+	CoveredResources int                     `json:"covered_resources"`
+	UncoveredTypes   []UncoveredResourceType `json:"uncovered_types"`
+	UnknownRoles     []UnknownRole           `json:"unknown_roles"`
+}
+
+// AnalyzeCoverage finds IAM-like resource types without a definition and
+// hierarchy-level roles missing from both hierarchical_roles and impersonation_roles.
+// extraPatterns and ignoredPatterns are regexes matched against resource types.
+func AnalyzeCoverage(resources []parser.ResourceRef, bindings []parser.IAMBinding, defs []parser.ResourceDefinition, extraPatterns, ignoredPatterns []string) *CoverageResult {
+	result := &CoverageResult{
+		ResourcesScanned: len(resources),
+		UncoveredTypes:   []UncoveredResourceType{},
+		UnknownRoles:     []UnknownRole{},
+	}
+
+	defined := make(map[string]bool)
+	for _, def := range defs {
+		defined[def.Type] = true
+	}
+	extra := compilePatterns(extraPatterns)
+	ignored := compilePatterns(ignoredPatterns)
+
+	// 1. Group IAM-like resources without a definition by type
+	byType := make(map[string]*UncoveredResourceType)
+	attributes := make(map[string]map[string]bool)
+	for _, res := range resources {
+		if defined[res.Type] {
+			result.CoveredResources++
+			continue
+		}
+		if !isIAMLikeType(res.Type, extra) || builtinIgnoredTypes[res.Type] || matchesAny(res.Type, ignored) {
+			continue
+		}
+
+		entry, exists := byType[res.Type]
+		if !exists {
+			entry = &UncoveredResourceType{Type: res.Type, Addresses: []string{}}
+			byType[res.Type] = entry
+			attributes[res.Type] = make(map[string]bool)
+		}
+		entry.Count++
+		entry.Addresses = append(entry.Addresses, res.Address)
+		for _, attr := range res.Attributes {
+			attributes[res.Type][attr] = true
+		}
+	}
+
+	for typ, entry := range byType {
+		entry.Stub = suggestDefinition(typ, attributes[typ])
+		sort.Strings(entry.Addresses)
+		result.UncoveredTypes = append(result.UncoveredTypes, *entry)
+	}
+	sort.Slice(result.UncoveredTypes, func(i, j int) bool {
+		return result.UncoveredTypes[i].Type < result.UncoveredTypes[j].Type
+	})
+
+	// 2. Group hierarchy-level bindings whose role has no rule
+	byRole := make(map[string]*UnknownRole)
+	seenAddrs := make(map[string]bool) // "role|address" -> true
+	for _, binding := range bindings {
+		if !isHierarchyLevel(binding.ResourceLevel) || binding.Role == "" {
+			continue
+		}
+		if definitions.GetRoleHierarchy(binding.Role) != nil || definitions.IsImpersonationRole(binding.Role) {
+			continue
+		}
+
+		entry, exists := byRole[binding.Role]
+		if !exists {
+			entry = &UnknownRole{Role: binding.Role, Addresses: []string{}, Stub: suggestRoleHierarchy(binding.Role)}
+			byRole[binding.Role] = entry
+		}
+		entry.Count++
+		if !seenAddrs[binding.Role+"|"+binding.TerraformAddr] {
+			seenAddrs[binding.Role+"|"+binding.TerraformAddr] = true
+			entry.Addresses = append(entry.Addresses, binding.TerraformAddr)
+		}
+	}
+
+	for _, entry := range byRole {
+		sort.Strings(entry.Addresses)
+		result.UnknownRoles = append(result.UnknownRoles, *entry)
+	}
+	sort.Slice(result.UnknownRoles, func(i, j int) bool {
+		return result.UnknownRoles[i].Role < result.UnknownRoles[j].Role
+	})
+
+	return result
+}
+
+// isIAMLikeType reports whether a resource type looks like it manages access
+func isIAMLikeType(resourceType string, extra []*regexp.Regexp) bool {
+	for _, marker := range iamTypeMarkers {
+		if strings.Contains(resourceType, marker) {
+			return true
+		}
+	}
+	return matchesAny(resourceType, extra)
+}
+
+// suggestDefinition builds a definition stub from the resource type suffix and its attributes
+func suggestDefinition(resourceType string, attrs map[string]bool) parser.ResourceDefinition {
+	stub := parser.ResourceDefinition{Type: resourceType}
+
+	switch {
+	case strings.HasSuffix(resourceType, "_iam_policy"):
+		stub.FieldMappings.PolicyData = "policy_data"
+	case strings.HasSuffix(resourceType, "_iam_binding"):
+		stub.FieldMappings.Role = "role"
+		stub.FieldMappings.Members = "members"
+	default:
+		if attrs["role"] {
+			stub.FieldMappings.Role = "role"
+		}
+		if attrs["member"] {
+			stub.FieldMappings.Member = "member"
+		}
+		if attrs["members"] {
+			stub.FieldMappings.Members = "members"
+		}
+	}
+
+	for _, candidate := range resourceIDCandidates {
+		if attrs[candidate] {
+			stub.FieldMappings.ResourceID = candidate
+			break
+		}
+	}
+
+	return stub
+}
+
+// suggestRoleHierarchy builds a rule stub, guessing the access level from the role name
+func suggestRoleHierarchy(role string) definitions.RoleHierarchy {
+	name := strings.ToLower(role[strings.LastIndex(role, ".")+1:])
+	accessLevel := "write"
+	switch {
+	case strings.Contains(name, "viewer") || strings.Contains(name, "reader") || strings.Contains(name, "browser"):
+		accessLevel = "read"
+	case strings.Contains(name, "admin") || strings.Contains(name, "owner"):
+		accessLevel = "admin"
+	}
+
+	return definitions.RoleHierarchy{
+		DisplayName:   "Resource",
+		ResourceTypes: []string{},
+		TargetLevel:   "resource",
+		AccessLevel:   accessLevel,
+	}
+}
+
+func compilePatterns(patterns []string) []*regexp.Regexp {
+	var compiled []*regexp.Regexp
+	for _, p := range patterns {
+		if re, err := regexp.Compile(p); err == nil {
+			compiled = append(compiled, re)
+		}
+	}
+	return compiled
+}
+
+func matchesAny(s string, patterns []*regexp.Regexp) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
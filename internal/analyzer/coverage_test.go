@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestAnalyzeCoverage_ResourceTypes(t *testing.T) {
+	resources := []parser.ResourceRef{
+		{Type: "google_storage_bucket_iam_member", Address: "google_storage_bucket_iam_member.b", Attributes: []string{"bucket", "role", "member"}},
+		{Type: "google_bigquery_dataset_access", Address: "google_bigquery_dataset_access.d", Attributes: []string{"dataset_id", "role"}},
+		{Type: "google_storage_bucket_acl", Address: "google_storage_bucket_acl.a", Attributes: []string{"bucket"}},
+		{Type: "google_storage_bucket", Address: "google_storage_bucket.data", Attributes: []string{"name"}},
+		{Type: "google_secret_manager_secret", Address: "google_secret_manager_secret.s", Attributes: []string{"secret_id"}},
+		{Type: "google_iam_deny_policy", Address: "google_iam_deny_policy.d", Attributes: []string{"name", "parent"}},
+		{Type: "google_iam_workload_identity_pool", Address: "google_iam_workload_identity_pool.gh", Attributes: []string{"workload_identity_pool_id"}},
+		{Type: "google_iam_workload_identity_pool_provider", Address: "google_iam_workload_identity_pool_provider.gh", Attributes: []string{"workload_identity_pool_id"}},
+		{Type: "google_project_iam_member", Address: "google_project_iam_member.p", Attributes: []string{"project", "role", "member"}},
+	}
+	defs := []parser.ResourceDefinition{{Type: "google_project_iam_member"}}
+
+	tests := []struct {
+		name    string
+		extra   []string
+		ignored []string
+		want    []string
+	}{
+		{
+			name: "types with IAM markers",
+			want: []string{"google_bigquery_dataset_access", "google_storage_bucket_acl", "google_storage_bucket_iam_member"},
+		},
+		{
+			name:  "extra patterns add types",
+			extra: []string{"^google_secret_manager_secret$"},
+			want:  []string{"google_bigquery_dataset_access", "google_secret_manager_secret", "google_storage_bucket_acl", "google_storage_bucket_iam_member"},
+		},
+		{
+			name:    "ignored patterns drop types",
+			ignored: []string{"_acl$", "^google_bigquery_"},
+			want:    []string{"google_storage_bucket_iam_member"},
+		},
+		{
+			name:    "ignored patterns win over extra patterns",
+			extra:   []string{"^google_secret_manager_secret$"},
+			ignored: []string{"secret"},
+			want:    []string{"google_bigquery_dataset_access", "google_storage_bucket_acl", "google_storage_bucket_iam_member"},
+		},
+		{
+			name:    "invalid patterns are skipped",
+			extra:   []string{"("},
+			ignored: []string{"["},
+			want:    []string{"google_bigquery_dataset_access", "google_storage_bucket_acl", "google_storage_bucket_iam_member"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := AnalyzeCoverage(resources, nil, defs, tt.extra, tt.ignored)
+
+			var got []string
+			for _, u := range result.UncoveredTypes {
+				got = append(got, u.Type)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("uncovered types = %v, want %v", got, tt.want)
+			}
+			if result.ResourcesScanned != len(resources) || result.CoveredResources != 1 {
+				t.Errorf("scanned %d, covered %d, want %d and 1", result.ResourcesScanned, result.CoveredResources, len(resources))
+			}
+		})
+	}
+}
+
+func TestAnalyzeCoverage_BuiltinIgnoredTypes(t *testing.T) {
+	for typ := range builtinIgnoredTypes {
+		t.Run(typ, func(t *testing.T) {
+			if !isIAMLikeType(typ, nil) {
+				t.Fatalf("%s does not look IAM-like, so ignoring it is not exercised", typ)
+			}
+			resources := []parser.ResourceRef{{Type: typ, Address: typ + ".x"}}
+			if result := AnalyzeCoverage(resources, nil, nil, nil, nil); len(result.UncoveredTypes) != 0 {
+				t.Errorf("UncoveredTypes = %+v, want %s left out", result.UncoveredTypes, typ)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCoverage_Stub(t *testing.T) {
+	resources := []parser.ResourceRef{
+		{Type: "google_widget_iam_member", Address: "google_widget_iam_member.b", Attributes: []string{"widget", "role", "member"}},
+		{Type: "google_widget_iam_member", Address: "google_widget_iam_member.a", Attributes: []string{"name", "role", "member"}},
+		{Type: "google_widget_iam_binding", Address: "google_widget_iam_binding.a", Attributes: []string{"instance", "role", "members"}},
+	}
+
+	result := AnalyzeCoverage(resources, nil, nil, nil, nil)
+
+	want := []UncoveredResourceType{
+		{
+			Type: "google_widget_iam_binding", Count: 1, Addresses: []string{"google_widget_iam_binding.a"},
+			Stub: parser.ResourceDefinition{Type: "google_widget_iam_binding", FieldMappings: parser.FieldMapping{ResourceID: "instance", Role: "role", Members: "members"}},
+		},
+		{
+			Type: "google_widget_iam_member", Count: 2, Addresses: []string{"google_widget_iam_member.a", "google_widget_iam_member.b"},
+			Stub: parser.ResourceDefinition{Type: "google_widget_iam_member", FieldMappings: parser.FieldMapping{ResourceID: "name", Role: "role", Member: "member"}},
+		},
+	}
+	if !reflect.DeepEqual(result.UncoveredTypes, want) {
+		t.Errorf("UncoveredTypes = %+v, want %+v", result.UncoveredTypes, want)
+	}
+}
+
+func TestAnalyzeCoverage_UnknownRoles(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	stub := func(level string) definitions.RoleHierarchy {
+		return definitions.RoleHierarchy{DisplayName: "Resource", ResourceTypes: []string{}, TargetLevel: "resource", AccessLevel: level}
+	}
+
+	tests := []struct {
+		name     string
+		bindings []parser.IAMBinding
+		want     []UnknownRole
+	}{
+		{
+			name: "grouped by role across scopes",
+			bindings: []parser.IAMBinding{
+				{ResourceID: "app", ResourceLevel: "project", Role: "roles/widgets.viewer", TerraformAddr: "google_project_iam_member.b"},
+				{ResourceID: "123", ResourceLevel: "folder", Role: "roles/widgets.viewer", TerraformAddr: "google_folder_iam_member.a"},
+				{ResourceID: "111", ResourceLevel: "organization", Role: "roles/widgets.admin", TerraformAddr: "google_organization_iam_member.c"},
+			},
+			want: []UnknownRole{
+				{Role: "roles/widgets.admin", Count: 1, Addresses: []string{"google_organization_iam_member.c"}, Stub: stub("admin")},
+				{Role: "roles/widgets.viewer", Count: 2, Addresses: []string{"google_folder_iam_member.a", "google_project_iam_member.b"}, Stub: stub("read")},
+			},
+		},
+		{
+			name: "addresses listed once per role",
+			bindings: []parser.IAMBinding{
+				{ResourceID: "app", ResourceLevel: "project", Role: "roles/widgets.editor", TerraformAddr: "google_project_iam_policy.p"},
+				{ResourceID: "app", ResourceLevel: "project", Role: "roles/widgets.editor", TerraformAddr: "google_project_iam_policy.p"},
+			},
+			want: []UnknownRole{
+				{Role: "roles/widgets.editor", Count: 2, Addresses: []string{"google_project_iam_policy.p"}, Stub: stub("write")},
+			},
+		},
+		{
+			name: "known, impersonation and resource-level roles left out",
+			bindings: []parser.IAMBinding{
+				{ResourceID: "app", ResourceLevel: "project", Role: "roles/storage.admin", TerraformAddr: "google_project_iam_member.storage"},
+				{ResourceID: "app", ResourceLevel: "project", Role: "roles/iam.serviceAccountUser", TerraformAddr: "google_project_iam_member.sa"},
+				{ResourceID: "data", ResourceLevel: "resource", Role: "roles/widgets.viewer", TerraformAddr: "google_storage_bucket_iam_member.b"},
+				{ResourceID: "app", ResourceLevel: "project", TerraformAddr: "google_project_iam_member.empty"},
+			},
+			want: []UnknownRole{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := AnalyzeCoverage(nil, tt.bindings, nil, nil, nil)
+			if !reflect.DeepEqual(result.UnknownRoles, tt.want) {
+				t.Errorf("UnknownRoles = %+v, want %+v", result.UnknownRoles, tt.want)
+			}
+		})
+	}
+}
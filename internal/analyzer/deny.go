@@ -0,0 +1,427 @@
+package analyzer
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// DenyPolicies evaluates IAM deny rules against the principals and scopes access is granted on
+type DenyPolicies struct {
+	rules   []parser.DenyRule
+	parents map[string]string // scope → parent scope, see buildParentMap
+	groups  *GroupMemberships // denied group principals also cover the group's members
+}
+
+// DeniedAccess is access a principal is granted but that deny policies take away, in whole or in part
+type DeniedAccess struct {
+	Principal   string             `json:"principal"`            // Principal the access is denied to
+	ResourceID  string             `json:"resource_id"`          // Resource the role is granted on, or the service account of a denied hop
+	Role        string             `json:"role"`                 // Granted role
+	Permissions []string           `json:"permissions"`          // Permissions of the role that are denied
+	Revoked     bool               `json:"revoked"`              // True when the role no longer grants any access
+	Possible    bool               `json:"possible,omitempty"`   // True when the role's permissions are not fully declared and some are only inferred to be denied
+	Policies    []string           `json:"policies"`             // Terraform addresses of the deny policies
+	Conditions  []string           `json:"conditions,omitempty"` // Denial conditions of conditional rules, which never revoke access
+	Edge        *ImpersonationEdge `json:"edge,omitempty"`       // For impersonation, the hop that is denied
+}
+
+// NewDenyPolicies prepares deny rules for evaluation. Bindings supply the folder and organization
+// parents of projects; groups let rules on a group apply to its members.
+func NewDenyPolicies(rules []parser.DenyRule, bindings []parser.IAMBinding, groups *GroupMemberships) *DenyPolicies {
+	return NewDenyPoliciesWithParents(rules, buildParentMap(bindings), groups)
+}
+
+// NewDenyPoliciesWithParents is NewDenyPolicies with the scope parents, as returned by ParentMap, given
+// instead of read from the bindings
+func NewDenyPoliciesWithParents(rules []parser.DenyRule, parents map[string]string, groups *GroupMemberships) *DenyPolicies {
+	return &DenyPolicies{
+		rules:   rules,
+		parents: parents,
+		groups:  groups,
+	}
+}
+
+// deniedPermissions returns which of permissions the rules deny to principal on a resource within scopes.
+// Revoked lists the permissions denied by unconditional rules only.
+func (d *DenyPolicies) deniedPermissions(principal string, scopes []string, permissions []string) (denied, revoked, policies, conditions []string) {
+	deniedSet := make(map[string]bool)
+	revokedSet := make(map[string]bool)
+	for _, rule := range d.applicableRules(principal, scopes) {
+		matched := false
+		for _, p := range permissions {
+			if matchesAnyPermission(rule.DeniedPermissions, p) && !matchesAnyPermission(rule.ExceptionPermissions, p) {
+				matched = true
+				deniedSet[p] = true
+				if rule.Condition == nil {
+					revokedSet[p] = true
+				}
+			}
+		}
+		if !matched {
+			continue
+		}
+		if !containsString(policies, rule.TerraformAddr) {
+			policies = append(policies, rule.TerraformAddr)
+		}
+		if rule.Condition != nil && !containsString(conditions, rule.Condition.Expression) {
+			conditions = append(conditions, rule.Condition.Expression)
+		}
+	}
+
+	for _, p := range permissions {
+		if deniedSet[p] {
+			denied = append(denied, p)
+		}
+		if revokedSet[p] {
+			revoked = append(revoked, p)
+		}
+	}
+	return denied, revoked, policies, conditions
+}
+
+// possiblyDeniedPermissions returns the permissions the rules deny to principal on a resource within scopes that
+// a role whose permissions are not fully declared may grant (see definitions.RoleMayGrantPermission),
+// leaving out those in known
+func (d *DenyPolicies) possiblyDeniedPermissions(principal string, scopes []string, role string, known []string) (possible, policies, conditions []string) {
+	for _, rule := range d.applicableRules(principal, scopes) {
+		matched := false
+		for _, p := range rule.DeniedPermissions {
+			permission := denyPermission(p)
+			if !definitions.RoleMayGrantPermission(role, permission) || matchesAnyPermission(rule.ExceptionPermissions, permission) {
+				continue
+			}
+			matched = true
+			if !containsString(known, permission) && !containsString(possible, permission) {
+				possible = append(possible, permission)
+			}
+		}
+		if !matched {
+			continue
+		}
+		if !containsString(policies, rule.TerraformAddr) {
+			policies = append(policies, rule.TerraformAddr)
+		}
+		if rule.Condition != nil && !containsString(conditions, rule.Condition.Expression) {
+			conditions = append(conditions, rule.Condition.Expression)
+		}
+	}
+	sort.Strings(possible)
+	return possible, policies, conditions
+}
+
+// applicableRules returns the rules attached to one of scopes that deny principal, directly or through its groups
+func (d *DenyPolicies) applicableRules(principal string, scopes []string) []parser.DenyRule {
+	if len(scopes) == 0 {
+		return nil
+	}
+	identities := []string{principal}
+	for _, groupPath := range d.groups.GroupPaths(principal) {
+		identities = append(identities, groupPath[len(groupPath)-1])
+	}
+
+	var rules []parser.DenyRule
+	for _, rule := range d.rules {
+		if !containsString(scopes, attachmentScope(rule.AttachmentPoint)) {
+			continue
+		}
+		if !matchesAnyDenyPrincipal(rule.DeniedPrincipals, identities) || matchesAnyDenyPrincipal(rule.ExceptionPrincipals, identities) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// DeniedRole returns what deny policies take from a role granted to principal on a resource, or nil.
+// The role is revoked when it declares its permissions (see definitions.RolePermissionsComplete)
+// and unconditional rules deny all of them. When it does not, denied permissions the role may grant
+// judging by its resource types and access level are reported too, and the denial is marked possible.
+func (d *DenyPolicies) DeniedRole(principal, resourceID, resourceType, role string) *DeniedAccess {
+	if d == nil {
+		return nil
+	}
+	scopes := d.resourceScopes(resourceID, resourceType)
+	permissions := definitions.RolePermissions(role)
+	denied, revoked, policies, conditions := d.deniedPermissions(principal, scopes, permissions)
+
+	complete := definitions.RolePermissionsComplete(role)
+	var possible []string
+	if !complete {
+		var morePolicies, moreConditions []string
+		possible, morePolicies, moreConditions = d.possiblyDeniedPermissions(principal, scopes, role, denied)
+		for _, p := range morePolicies {
+			if !containsString(policies, p) {
+				policies = append(policies, p)
+			}
+		}
+		for _, c := range moreConditions {
+			if !containsString(conditions, c) {
+				conditions = append(conditions, c)
+			}
+		}
+	}
+	if len(denied) == 0 && len(possible) == 0 {
+		return nil
+	}
+	return &DeniedAccess{
+		Principal:   principal,
+		ResourceID:  resourceID,
+		Role:        role,
+		Permissions: append(denied, possible...),
+		Revoked:     complete && len(revoked) == len(permissions),
+		Possible:    len(possible) > 0,
+		Policies:    policies,
+		Conditions:  conditions,
+	}
+}
+
+// DeniedEdge returns the denial of the permission an impersonation hop relies on when actor makes it, or nil.
+// The actor is the chain's principal, which differs from edge.Source when the hop is granted to one of its groups.
+// Hops through a modifiable workload are never denied: they rely on the workload's own permissions.
+func (d *DenyPolicies) DeniedEdge(actor string, edge ImpersonationEdge) *DeniedAccess {
+	permission := capabilityPermission(edge)
+	if d == nil || permission == "" {
+		return nil
+	}
+	email := strings.TrimPrefix(edge.Target, "serviceAccount:")
+	project := serviceAccountProject(email)
+	if project == "" {
+		return nil
+	}
+	resourceID := "projects/" + project + "/serviceAccounts/" + email
+
+	denied, revoked, policies, conditions := d.deniedPermissions(actor, d.resourceScopes(resourceID, ""), []string{permission})
+	if len(denied) == 0 {
+		return nil
+	}
+	return &DeniedAccess{
+		Principal:   actor,
+		ResourceID:  resourceID,
+		Role:        edge.Role,
+		Permissions: denied,
+		Revoked:     len(revoked) > 0,
+		Policies:    policies,
+		Conditions:  conditions,
+		Edge:        &edge,
+	}
+}
+
+// capabilityPermission returns the permission an impersonation capability relies on
+func capabilityPermission(edge ImpersonationEdge) string {
+	switch edge.Capability {
+	case definitions.CapabilityTokenCreation, definitions.CapabilityWorkloadIdentity:
+		return "iam.serviceAccounts.getAccessToken"
+	case definitions.CapabilityActAs:
+		return "iam.serviceAccounts.actAs"
+	case definitions.CapabilityKeyCreation:
+		if definitions.RoleHasPermission(edge.Role, "iam.serviceAccountKeys.create") {
+			return "iam.serviceAccountKeys.create"
+		}
+		return "iam.serviceAccounts.setIamPolicy"
+	}
+	return ""
+}
+
+// resourceScopes returns the scope a resource's access is granted on, followed by its known ancestors.
+// Resources other than projects, folders and organizations are placed in the project their bindings record
+// or their ID names (e.g. "projects/my-project/serviceAccounts/..."); otherwise their scopes are unknown and nil is returned.
+func (d *DenyPolicies) resourceScopes(resourceID, resourceType string) []string {
+	switch {
+	case strings.HasPrefix(resourceType, "google_project_iam"):
+		return ancestorScopes(scopeKey("project", resourceID), d.parents)
+	case strings.HasPrefix(resourceType, "google_folder_iam"):
+		return ancestorScopes(scopeKey("folder", resourceID), d.parents)
+	case strings.HasPrefix(resourceType, "google_organization_iam"):
+		return ancestorScopes(scopeKey("organization", resourceID), d.parents)
+	}
+
+	if parent, ok := d.parents[scopeKey("resource", resourceID)]; ok {
+		return ancestorScopes(parent, d.parents)
+	}
+	project := ""
+	if parts := strings.Split(resourceID, "/"); len(parts) >= 2 && parts[0] == "projects" {
+		project = parts[1]
+	} else if email := extractServiceAccountEmail(resourceID); email != "" {
+		project = serviceAccountProject(email)
+	}
+	if project == "" {
+		return nil
+	}
+	return ancestorScopes(scopeKey("project", project), d.parents)
+}
+
+// attachmentScope returns the scope key of a deny policy attachment point
+// Example: "cloudresourcemanager.googleapis.com/projects/my-project" → "project:my-project"
+func attachmentScope(attachmentPoint string) string {
+	parts := strings.Split(strings.TrimPrefix(attachmentPoint, "cloudresourcemanager.googleapis.com/"), "/")
+	if len(parts) != 2 {
+		return ""
+	}
+	switch parts[0] {
+	case "projects":
+		return scopeKey("project", parts[1])
+	case "folders":
+		return scopeKey("folder", parts[1])
+	case "organizations":
+		return scopeKey("organization", parts[1])
+	}
+	return ""
+}
+
+// denyPrincipalMember converts an IAM v2 principal identifier to the member format of IAM bindings.
+// "principalSet://goog/public:all" becomes "*"; identifiers without a binding equivalent are returned unchanged.
+// Example: "principalSet://goog/group/devs@example.com" → "group:devs@example.com"
+func denyPrincipalMember(principal string) string {
+	if principal == "principalSet://goog/public:all" {
+		return "*"
+	}
+	if email, ok := strings.CutPrefix(principal, "principal://goog/subject/"); ok {
+		return "user:" + email
+	}
+	if email, ok := strings.CutPrefix(principal, "principalSet://goog/group/"); ok {
+		return "group:" + email
+	}
+	if email, ok := strings.CutPrefix(principal, "principal://iam.googleapis.com/projects/-/serviceAccounts/"); ok {
+		return "serviceAccount:" + email
+	}
+	return principal
+}
+
+// matchesAnyDenyPrincipal reports whether one of the deny rule principals covers one of the identities
+func matchesAnyDenyPrincipal(denyPrincipals []string, identities []string) bool {
+	for _, p := range denyPrincipals {
+		member := denyPrincipalMember(p)
+		if member == "*" || containsString(identities, member) {
+			return true
+		}
+	}
+	return false
+}
+
+// denyPermission converts an IAM v2 permission to the format of role permissions.
+// Example: "storage.googleapis.com/buckets.delete" → "storage.buckets.delete"
+func denyPermission(permission string) string {
+	host, rest, ok := strings.Cut(permission, "/")
+	if !ok {
+		return permission
+	}
+	service, _, _ := strings.Cut(host, ".")
+	if service == "cloudresourcemanager" {
+		service = "resourcemanager"
+	}
+	return service + "." + rest
+}
+
+// matchesAnyPermission reports whether a permission matches one of the deny rule permissions, which may use "*" wildcards
+func matchesAnyPermission(denyPermissions []string, permission string) bool {
+	for _, p := range denyPermissions {
+		if matched, _ := path.Match(denyPermission(p), permission); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyDenyPolicies removes the roles deny policies revoke from every principal's direct access and records
+// in PrincipalData.Denied the roles they revoke or narrow. Hierarchical access is rebuilt from the
+// project roles that remain.
+func ApplyDenyPolicies(results map[string]*PrincipalData, deny *DenyPolicies) {
+	if deny == nil {
+		return
+	}
+	for principal, data := range results {
+		for resID, meta := range data.ResourceAccess {
+			revoked := false
+			for _, role := range sortedRoles(meta.Roles) {
+				denied := deny.DeniedRole(principal, resID, meta.Type, role)
+				if denied == nil {
+					continue
+				}
+				data.Denied = append(data.Denied, *denied)
+				if denied.Revoked {
+					revoked = true
+					delete(meta.Roles, role)
+					delete(meta.TerraformAddrs, role)
+					delete(meta.GroupPaths, role)
+				}
+			}
+			if !revoked || !strings.HasPrefix(meta.Type, "google_project_iam") {
+				continue
+			}
+			delete(data.HierarchicalAccess, resID)
+			for role := range meta.Roles {
+				for _, rt := range definitions.GetResourceTypesForRole(role) {
+					if data.HierarchicalAccess[resID] == nil {
+						data.HierarchicalAccess[resID] = make(map[string]bool)
+					}
+					data.HierarchicalAccess[resID][rt] = true
+				}
+			}
+		}
+		for resID, meta := range data.ResourceAccess {
+			if len(meta.Roles) == 0 {
+				delete(data.ResourceAccess, resID)
+			}
+		}
+		sort.Slice(data.Denied, func(i, j int) bool {
+			if data.Denied[i].ResourceID != data.Denied[j].ResourceID {
+				return data.Denied[i].ResourceID < data.Denied[j].ResourceID
+			}
+			return data.Denied[i].Role < data.Denied[j].Role
+		})
+	}
+}
+
+// sortedRoles returns the roles of a role set in order
+func sortedRoles(roles map[string]bool) []string {
+	sorted := make([]string, 0, len(roles))
+	for role := range roles {
+		sorted = append(sorted, role)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// AddDenyPolicies makes transitive analyses skip impersonation hops whose permission is denied to the chain's principal
+func (g *ImpersonationGraph) AddDenyPolicies(deny *DenyPolicies) {
+	g.Deny = deny
+}
+
+// allowedEdge returns the edge from source to target that best explains the hop when actor makes it, like Edge,
+// but passing over edges deny policies revoke. ActAs edges without a deploy role of their source are confirmed
+// by one of the actor or of its groups. The denial is returned alongside an edge that is only
+// conditionally denied, and on its own when every edge is revoked.
+func (g *ImpersonationGraph) allowedEdge(actor, source, target string) (ImpersonationEdge, *DeniedAccess, bool) {
+	var fallback *ImpersonationEdge
+	var fallbackDenial, firstDenial *DeniedAccess
+	for _, e := range g.Graph[source] {
+		if e.Target != target {
+			continue
+		}
+		if e.Capability == definitions.CapabilityActAs && e.DeployRole == "" {
+			e.DeployRole = g.deployRole(actor, e.Target)
+		}
+		denial := g.Deny.DeniedEdge(actor, e)
+		if denial != nil && denial.Revoked {
+			if firstDenial == nil {
+				firstDenial = denial
+			}
+			continue
+		}
+		if e.Effective() {
+			return e, denial, true
+		}
+		if fallback == nil {
+			edge := e
+			fallback, fallbackDenial = &edge, denial
+		}
+	}
+	if fallback != nil {
+		return *fallback, fallbackDenial, true
+	}
+	return ImpersonationEdge{}, firstDenial, false
+}
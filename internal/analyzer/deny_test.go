@@ -0,0 +1,156 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestDenyPrincipalMember(t *testing.T) {
+	tests := map[string]string{
+		"principal://goog/subject/alice@example.com":                                               "user:alice@example.com",
+		"principalSet://goog/group/devs@example.com":                                               "group:devs@example.com",
+		"principal://iam.googleapis.com/projects/-/serviceAccounts/ci@app.iam.gserviceaccount.com": "serviceAccount:ci@app.iam.gserviceaccount.com",
+		"principalSet://goog/public:all":                                                           "*",
+		"principalSet://iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/gh/*": "principalSet://iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/gh/*",
+	}
+	for in, want := range tests {
+		if got := denyPrincipalMember(in); got != want {
+			t.Errorf("denyPrincipalMember(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDenyPermission(t *testing.T) {
+	tests := map[string]string{
+		"storage.googleapis.com/buckets.delete":               "storage.buckets.delete",
+		"cloudresourcemanager.googleapis.com/projects.delete": "resourcemanager.projects.delete",
+		"iam.googleapis.com/serviceAccountKeys.*":             "iam.serviceAccountKeys.*",
+	}
+	for in, want := range tests {
+		if got := denyPermission(in); got != want {
+			t.Errorf("denyPermission(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestApplyDenyPolicies(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	bindings := []parser.IAMBinding{
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", ParentID: "folders/10", ParentType: "folder", Role: "roles/storage.objectViewer", Members: []string{"user:alice@example.com"}},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/storage.objectAdmin", Members: []string{"user:bob@example.com"}},
+		{ResourceID: "other", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/storage.objectViewer", Members: []string{"user:alice@example.com"}},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/owner", Members: []string{"user:carol@example.com"}},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/viewer", Members: []string{"user:dave@example.com"}},
+	}
+	rules := []parser.DenyRule{
+		{
+			AttachmentPoint:   "cloudresourcemanager.googleapis.com/folders/10",
+			DeniedPrincipals:  []string{"principalSet://goog/group/readers@example.com"},
+			DeniedPermissions: []string{"storage.googleapis.com/objects.*"},
+			TerraformAddr:     "google_iam_deny_policy.folder",
+		},
+		{
+			AttachmentPoint:      "cloudresourcemanager.googleapis.com/projects/app",
+			DeniedPrincipals:     []string{"principalSet://goog/public:all"},
+			DeniedPermissions:    []string{"storage.googleapis.com/objects.delete"},
+			ExceptionPermissions: []string{"storage.googleapis.com/objects.get"},
+			TerraformAddr:        "google_iam_deny_policy.app",
+		},
+	}
+	groups := NewGroupMemberships(map[string][]string{"readers@example.com": {"alice@example.com"}})
+
+	results := Analyze(bindings)
+	ApplyDenyPolicies(results, NewDenyPolicies(rules, bindings, groups))
+
+	alice := results["user:alice@example.com"]
+	if _, ok := alice.ResourceAccess["app"]; ok {
+		t.Errorf("objectViewer on app should be revoked through the folder deny on readers")
+	}
+	if _, ok := alice.HierarchicalAccess["app"]; ok {
+		t.Errorf("hierarchical access to app should be removed with its only role")
+	}
+	if !alice.ResourceAccess["other"].Roles["roles/storage.objectViewer"] {
+		t.Errorf("objectViewer on other is outside the folder and should remain")
+	}
+	if len(alice.Denied) != 1 || !alice.Denied[0].Revoked || alice.Denied[0].ResourceID != "app" {
+		t.Errorf("alice.Denied = %+v, want the revoked objectViewer on app", alice.Denied)
+	}
+
+	bob := results["user:bob@example.com"]
+	if !bob.ResourceAccess["app"].Roles["roles/storage.objectAdmin"] {
+		t.Fatalf("objectAdmin keeps other permissions and should remain")
+	}
+	want := DeniedAccess{
+		Principal:   "user:bob@example.com",
+		ResourceID:  "app",
+		Role:        "roles/storage.objectAdmin",
+		Permissions: []string{"storage.objects.delete"},
+		Policies:    []string{"google_iam_deny_policy.app"},
+	}
+	if len(bob.Denied) != 1 || !reflect.DeepEqual(bob.Denied[0], want) {
+		t.Errorf("bob.Denied = %+v, want %+v", bob.Denied, want)
+	}
+
+	// roles/owner does not list its permissions, so the deny is inferred from its resource types and access level
+	carol := results["user:carol@example.com"]
+	want = DeniedAccess{
+		Principal:   "user:carol@example.com",
+		ResourceID:  "app",
+		Role:        "roles/owner",
+		Permissions: []string{"storage.objects.delete"},
+		Possible:    true,
+		Policies:    []string{"google_iam_deny_policy.app"},
+	}
+	if len(carol.Denied) != 1 || !reflect.DeepEqual(carol.Denied[0], want) || !carol.ResourceAccess["app"].Roles["roles/owner"] {
+		t.Errorf("carol.Denied = %+v, want %+v and the role kept", carol.Denied, want)
+	}
+	if dave := results["user:dave@example.com"]; len(dave.Denied) != 0 {
+		t.Errorf("roles/viewer cannot delete objects, got dave.Denied = %+v", dave.Denied)
+	}
+}
+
+func TestAnalyzeTransitiveAccess_DeniedHop(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	bindings := []parser.IAMBinding{
+		{
+			ResourceID:   "projects/app/serviceAccounts/deployer@app.iam.gserviceaccount.com",
+			ResourceType: "google_service_account_iam_member", ResourceLevel: "resource",
+			Role: "roles/iam.serviceAccountTokenCreator", Members: []string{"user:alice@example.com", "user:bob@example.com"},
+		},
+		{ResourceID: "prod", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/owner", Members: []string{"serviceAccount:deployer@app.iam.gserviceaccount.com"}},
+	}
+	rules := []parser.DenyRule{{
+		AttachmentPoint:   "cloudresourcemanager.googleapis.com/projects/app",
+		DeniedPrincipals:  []string{"principal://goog/subject/alice@example.com"},
+		DeniedPermissions: []string{"iam.googleapis.com/serviceAccounts.getAccessToken"},
+		TerraformAddr:     "google_iam_deny_policy.app",
+	}}
+
+	results := Analyze(bindings)
+	deny := NewDenyPolicies(rules, bindings, nil)
+	ApplyDenyPolicies(results, deny)
+	graph := BuildImpersonationGraph(bindings)
+	graph.AddDenyPolicies(deny)
+
+	alice := AnalyzeTransitiveAccess("alice@example.com", results, graph)
+	if len(alice.TransitiveAccess) != 0 {
+		t.Errorf("alice's only hop is denied, got transitive access %+v", alice.TransitiveAccess)
+	}
+	if len(alice.Denied) != 1 || !alice.Denied[0].Revoked || alice.Denied[0].Edge == nil {
+		t.Errorf("alice.Denied = %+v, want the revoked hop to deployer", alice.Denied)
+	}
+
+	bob := AnalyzeTransitiveAccess("bob@example.com", results, graph)
+	if _, ok := bob.TransitiveAccess["prod"]; !ok || len(bob.Denied) != 0 {
+		t.Errorf("bob is not denied and should reach prod, got %+v (denied %+v)", bob.TransitiveAccess, bob.Denied)
+	}
+}
@@ -0,0 +1,346 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// EscalationOwnerGrant is the last step of a chain ending at an account that already holds roles/owner
+const EscalationOwnerGrant = "owner_grant"
+
+// EscalationStep is one move of an escalation chain and the binding that allows it
+type EscalationStep struct {
+	Primitive string   `json:"primitive"`            // escalation_primitives name, EscalationOwnerGrant, or the capability of a hop no primitive describes
+	Principal string   `json:"principal"`            // Account taking the step
+	Target    string   `json:"target"`               // Service account gained, or the scope ("level:id") owner-equivalent access is gained on
+	Role      string   `json:"role"`                 // Role the step relies on
+	Scope     string   `json:"scope"`                // Where the role is granted, as "level:id"
+	Binding   string   `json:"binding,omitempty"`    // Terraform address of the binding
+	Location  string   `json:"location,omitempty"`   // "file:line" of the binding
+	Condition string   `json:"condition,omitempty"`  // IAM condition expression, if the grant is conditional
+	Detail    string   `json:"detail,omitempty"`     // What else the step relies on, e.g. a deploy role or a custom role
+	ViaGroups []string `json:"via_groups,omitempty"` // When the role is held by a group, the groups leading to it, innermost first
+}
+
+// EscalationFinding is a principal that can reach owner-equivalent access on a scope it does not already own
+type EscalationFinding struct {
+	Principal   string           `json:"principal"`
+	Scope       string           `json:"scope"`                 // Project, folder or organization reached, as "level:id"
+	Steps       []EscalationStep `json:"steps"`                 // Shortest chain, the last step gaining owner-equivalent access
+	Conditional bool             `json:"conditional,omitempty"` // True when a step relies on a conditional grant
+}
+
+// escalationIndex is every step bindings allow, by the account (or group) holding the role
+type escalationIndex struct {
+	owners    map[string][]string         // principal → scopes it holds an unconditional owner-equivalent role on
+	owner     map[string][]EscalationStep // principal → steps gaining owner-equivalent access
+	agents    map[string][]EscalationStep // principal → steps gaining the access of a service agent
+	locations map[string]string           // Terraform address → "file:line" of the binding
+}
+
+// FindEscalations returns, for every principal in directAccess, each project, folder and organization it can reach
+// owner-equivalent access on through the escalation_primitives catalogue: impersonation hops it can make, service
+// agents it can run code as, and primitives such as setIamPolicy that end in owner-equivalent access. Scopes the
+// principal already owns, directly, through a group or through an ancestor, are left out. Each finding carries the
+// shortest chain of at most maxDepth hops (DefaultMaxPathDepth when not positive), ordered by principal and scope.
+// graph should carry the groups, hierarchy and deny policies of the bindings; steps deny policies revoke are skipped.
+func FindEscalations(bindings []parser.IAMBinding, directAccess map[string]*PrincipalData, graph *ImpersonationGraph, maxDepth int) []EscalationFinding {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxPathDepth
+	}
+	parents := make(map[string]string)
+	for scope, parent := range graph.parentMap(bindings) {
+		parents[scope] = parent
+	}
+	if graph.Hierarchy != nil {
+		for _, node := range graph.Hierarchy.Nodes {
+			if node.ParentID != "" {
+				parents[scopeKey(node.Type, node.ID)] = scopeKey(node.ParentType, node.ParentID)
+			}
+		}
+	}
+	index := buildEscalationIndex(bindings, parents)
+	primitives := definitions.GetEscalationPrimitives()
+
+	principals := make([]string, 0, len(directAccess))
+	for principal := range directAccess {
+		principals = append(principals, principal)
+	}
+	sort.Strings(principals)
+
+	var findings []EscalationFinding
+	for _, principal := range principals {
+		groupPaths := map[string][]string{}
+		sources := func(account string) []string {
+			list := []string{account}
+			for _, path := range graph.Groups.GroupPaths(account) {
+				group := path[len(path)-1]
+				list = append(list, group)
+				groupPaths[account+"|"+group] = path
+			}
+			return list
+		}
+
+		// Scopes the principal already owns, and so cannot escalate to
+		var owned []string
+		for _, source := range sources(principal) {
+			owned = append(owned, index.owners[source]...)
+		}
+		alreadyOwned := func(scope string) bool {
+			for _, s := range ancestorScopes(scope, parents) {
+				if containsString(owned, s) {
+					return true
+				}
+			}
+			return false
+		}
+
+		type hop struct {
+			account string
+			steps   []EscalationStep
+		}
+		queue := []hop{{account: principal}}
+		visited := make(map[string]bool)
+		found := make(map[string]bool)
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			if visited[current.account] {
+				continue
+			}
+			visited[current.account] = true
+
+			accountSources := sources(current.account)
+			for _, source := range accountSources {
+				for _, step := range index.owner[source] {
+					if len(current.steps) == 0 && step.Primitive == EscalationOwnerGrant {
+						continue // a conditional owner grant of its own is not an escalation
+					}
+					if found[step.Target] || alreadyOwned(step.Target) || graph.Deny.deniesPrimitive(principal, step, primitives, parents) {
+						continue
+					}
+					found[step.Target] = true
+					step.Principal, step.ViaGroups = current.account, groupPaths[current.account+"|"+source]
+					findings = append(findings, newEscalationFinding(principal, step.Target, append(append([]EscalationStep{}, current.steps...), step)))
+				}
+			}
+			if len(current.steps) >= maxDepth {
+				continue
+			}
+
+			next := func(target string, step EscalationStep) {
+				if visited[target] || target == principal {
+					return
+				}
+				steps := append(append([]EscalationStep{}, current.steps...), step)
+				queue = append(queue, hop{account: target, steps: steps})
+			}
+			for _, source := range accountSources {
+				for _, target := range graph.Targets(source) {
+					edge, _, ok := graph.allowedEdge(current.account, source, target)
+					if !ok || !edge.Effective() {
+						continue
+					}
+					step := edgeStep(edge, primitives, index.locations)
+					step.Principal, step.ViaGroups = current.account, groupPaths[current.account+"|"+source]
+					next(target, step)
+				}
+				for _, step := range index.agents[source] {
+					if graph.Deny.deniesPrimitive(principal, step, primitives, parents) {
+						continue
+					}
+					step.Principal, step.ViaGroups = current.account, groupPaths[current.account+"|"+source]
+					next(step.Target, step)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Principal != findings[j].Principal {
+			return findings[i].Principal < findings[j].Principal
+		}
+		return findings[i].Scope < findings[j].Scope
+	})
+	return findings
+}
+
+func newEscalationFinding(principal, scope string, steps []EscalationStep) EscalationFinding {
+	finding := EscalationFinding{Principal: principal, Scope: scope, Steps: steps}
+	for _, s := range steps {
+		if s.Condition != "" {
+			finding.Conditional = true
+		}
+	}
+	return finding
+}
+
+// buildEscalationIndex collects the owner-equivalent grants and the owner and service agent steps of the bindings
+func buildEscalationIndex(bindings []parser.IAMBinding, parents map[string]string) *escalationIndex {
+	index := &escalationIndex{
+		owners:    make(map[string][]string),
+		owner:     make(map[string][]EscalationStep),
+		agents:    make(map[string][]EscalationStep),
+		locations: make(map[string]string),
+	}
+	primitives := definitions.GetEscalationPrimitives()
+
+	// Service agents by the projects they hold roles in, and custom roles by holder
+	agentProjects := make(map[string][]string)
+	type customGrant struct {
+		role, scope, address string
+	}
+	customRoles := make(map[string][]customGrant)
+	for _, b := range bindings {
+		if b.Location != "" && index.locations[b.TerraformAddr] == "" {
+			index.locations[b.TerraformAddr] = b.Location
+		}
+		project := ""
+		switch {
+		case b.ResourceLevel == "project":
+			project = scopeKey("project", b.ResourceID)
+		case b.ParentType == "project":
+			project = scopeKey("project", b.ParentID)
+		}
+		for _, member := range b.Members {
+			if project != "" && GetPrincipalType(member) == "serviceAccount" && !containsString(agentProjects[member], project) {
+				agentProjects[member] = append(agentProjects[member], project)
+			}
+			if isHierarchyLevel(b.ResourceLevel) && customRoleScope(b.Role) != "" {
+				customRoles[member] = append(customRoles[member], customGrant{b.Role, scopeKey(b.ResourceLevel, b.ResourceID), b.TerraformAddr})
+			}
+		}
+	}
+	agents := make([]string, 0, len(agentProjects))
+	for agent := range agentProjects {
+		agents = append(agents, agent)
+	}
+	sort.Strings(agents)
+
+	for _, b := range bindings {
+		if !isHierarchyLevel(b.ResourceLevel) {
+			continue
+		}
+		scope := scopeKey(b.ResourceLevel, b.ResourceID)
+		base := EscalationStep{Role: b.Role, Scope: scope, Binding: b.TerraformAddr, Location: b.Location}
+		if b.Condition != nil {
+			base.Condition = b.Condition.Expression
+		}
+
+		for _, member := range b.Members {
+			if definitions.IsOwnerEquivalent(b.Role) {
+				if b.Condition == nil {
+					index.owners[member] = append(index.owners[member], scope)
+				}
+				step := base
+				step.Primitive, step.Target = EscalationOwnerGrant, scope
+				index.owner[member] = append(index.owner[member], step)
+				continue
+			}
+			for _, p := range primitives {
+				if !p.GrantedBy(b.Role, b.ResourceLevel) {
+					continue
+				}
+				switch p.Gains {
+				case definitions.GainsOwner:
+					if !p.RequiresCustomRole {
+						step := base
+						step.Primitive, step.Target = p.Name, scope
+						index.owner[member] = append(index.owner[member], step)
+						continue
+					}
+					// Custom roles defined on or below the scope, held by the member
+					for _, held := range customRoles[member] {
+						if !containsString(ancestorScopes(customRoleScope(held.role), parents), scope) {
+							continue
+						}
+						step := base
+						step.Primitive, step.Target = p.Name, held.scope
+						step.Detail = "updates " + held.role + " held on " + held.scope + " (" + held.address + ")"
+						index.owner[member] = append(index.owner[member], step)
+					}
+				case definitions.GainsServiceAgent:
+					for _, agent := range agents {
+						if !strings.HasSuffix(agent, "@"+p.ServiceAgent) || agent == member {
+							continue
+						}
+						for _, project := range agentProjects[agent] {
+							if containsString(ancestorScopes(project, parents), scope) {
+								step := base
+								step.Primitive, step.Target = p.Name, agent
+								step.Detail = "runs as the service agent of " + project
+								index.agents[member] = append(index.agents[member], step)
+								break
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return index
+}
+
+// edgeStep describes an impersonation hop as a step, named after the first service_account primitive the edge's
+// role grants, or after its capability when none does
+func edgeStep(edge ImpersonationEdge, primitives []definitions.EscalationPrimitive, locations map[string]string) EscalationStep {
+	step := EscalationStep{
+		Primitive: edge.Capability,
+		Target:    edge.Target,
+		Role:      edge.Role,
+		Scope:     edge.Scope,
+		Binding:   edge.SourceAddress,
+		Location:  locations[edge.SourceAddress],
+		Condition: edge.Condition,
+	}
+	level, _, _ := strings.Cut(edge.Scope, ":")
+	for _, p := range primitives {
+		if p.Gains == definitions.GainsServiceAccount && p.GrantedBy(edge.Role, level) && (!p.RequiresDeployRole || edge.DeployRole != "") {
+			step.Primitive = p.Name
+			break
+		}
+	}
+	switch {
+	case edge.DeployRole != "":
+		step.Detail = "deploys with " + edge.DeployRole
+	case edge.Workload != "":
+		step.Detail = "modifies " + edge.Workload
+	}
+	return step
+}
+
+// customRoleScope returns the scope a custom role is defined on ("projects/p/roles/r" → "project:p"), or "" for
+// predefined roles
+func customRoleScope(role string) string {
+	parts := strings.Split(role, "/")
+	if len(parts) != 4 || parts[2] != "roles" {
+		return ""
+	}
+	switch parts[0] {
+	case "projects":
+		return scopeKey("project", parts[1])
+	case "organizations":
+		return scopeKey("organization", parts[1])
+	}
+	return ""
+}
+
+// deniesPrimitive reports whether unconditional deny rules take from actor every permission the primitive of a
+// step relies on, on the scope the step's role is granted on. Owner grants and primitives without permissions are
+// never denied.
+func (d *DenyPolicies) deniesPrimitive(actor string, step EscalationStep, primitives []definitions.EscalationPrimitive, parents map[string]string) bool {
+	if d == nil {
+		return false
+	}
+	for _, p := range primitives {
+		if p.Name != step.Primitive || len(p.Permissions) == 0 {
+			continue
+		}
+		_, revoked, _, _ := d.deniedPermissions(actor, ancestorScopes(step.Scope, parents), p.Permissions)
+		return len(revoked) == len(p.Permissions)
+	}
+	return false
+}
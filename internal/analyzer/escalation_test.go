@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestFindEscalations(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	deployer := "serviceAccount:deployer@app.iam.gserviceaccount.com"
+	agent := "serviceAccount:123@cloudservices.gserviceaccount.com"
+	bindings := []parser.IAMBinding{
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", ParentType: "folder", ParentID: "10", Role: "roles/owner", Members: []string{deployer, "user:root@example.com"}, TerraformAddr: "deployer_owner", Location: "main.tf:3"},
+		{
+			ResourceID: "projects/app/serviceAccounts/deployer@app.iam.gserviceaccount.com", ResourceType: "google_service_account_iam_member", ResourceLevel: "resource",
+			Role: "roles/iam.serviceAccountTokenCreator", Members: []string{"user:alice@example.com"}, TerraformAddr: "alice_deployer", Location: "main.tf:9",
+		},
+		{ResourceID: "10", ResourceType: "google_folder_iam_member", ResourceLevel: "folder", Role: "roles/resourcemanager.folderIamAdmin", Members: []string{"group:admins@example.com"}, TerraformAddr: "folder_admins"},
+		{ResourceID: "data", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/deploymentmanager.editor", Members: []string{"user:dm@example.com"}, TerraformAddr: "dm_editor"},
+		{ResourceID: "data", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/owner", Members: []string{agent}, TerraformAddr: "agent_owner"},
+		{ResourceID: "data", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/iam.roleAdmin", Members: []string{"user:carol@example.com"}, TerraformAddr: "carol_roles"},
+		{ResourceID: "data", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "projects/data/roles/ops", Members: []string{"user:carol@example.com"}, TerraformAddr: "carol_ops"},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/iam.roleAdmin", Members: []string{"user:dave@example.com"}, TerraformAddr: "dave_roles"},
+	}
+	groups := NewGroupMemberships(map[string][]string{"admins@example.com": {"bob@example.com"}})
+	graph := BuildImpersonationGraph(bindings)
+	graph.AddGroups(groups)
+	directAccess := AnalyzeWithGroups(bindings, groups)
+
+	var got []string
+	for _, f := range FindEscalations(bindings, directAccess, graph, 0) {
+		var steps []string
+		for _, s := range f.Steps {
+			steps = append(steps, s.Primitive)
+		}
+		got = append(got, f.Principal+" "+f.Scope+" "+strings.Join(steps, ","))
+	}
+	want := []string{
+		"group:admins@example.com folder:10 folder_set_iam_policy",
+		"user:alice@example.com project:app service_account_token_creation,owner_grant",
+		"user:bob@example.com folder:10 folder_set_iam_policy",
+		"user:carol@example.com project:data custom_role_update",
+		"user:dm@example.com project:data deployment_manager,owner_grant",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("FindEscalations() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	findings := FindEscalations(bindings, directAccess, graph, 0)
+	alice := findings[1].Steps
+	if alice[0].Binding != "alice_deployer" || alice[0].Location != "main.tf:9" || alice[1].Principal != deployer || alice[1].Location != "main.tf:3" {
+		t.Errorf("alice's chain should carry the evidence of each binding, got %+v", alice)
+	}
+	if bob := findings[2].Steps[0]; len(bob.ViaGroups) != 1 || bob.Binding != "folder_admins" {
+		t.Errorf("bob should escalate through group:admins, got %+v", bob)
+	}
+
+	if got := FindEscalations(bindings, directAccess, graph, 1); len(got) != 5 {
+		t.Errorf("one hop should still reach every finding, got %d", len(got))
+	}
+}
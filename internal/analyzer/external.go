@@ -0,0 +1,324 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// Terraform resource types describing Workload Identity Federation
+const (
+	IdentityPoolType         = "google_iam_workload_identity_pool"
+	IdentityPoolProviderType = "google_iam_workload_identity_pool_provider"
+)
+
+// IdentityPoolResourceTypes are the resource types FindIdentityProviders needs parsed
+var IdentityPoolResourceTypes = []string{IdentityPoolType, IdentityPoolProviderType}
+
+// Kinds of external identity
+const (
+	ExternalGitHub     = "github"
+	ExternalKubernetes = "kubernetes"
+	ExternalAWS        = "aws"
+	ExternalOIDC       = "oidc"
+	ExternalSAML       = "saml"
+	ExternalUnknown    = "external" // a federated identity whose provider is not in the scanned code
+)
+
+// Parts of a pool an external identity member can select
+const (
+	ScopeSubject   = "subject"   // a single subject, e.g. "repo:org/repo:ref:refs/heads/main"
+	ScopeAttribute = "attribute" // every identity with an attribute value, e.g. attribute.repository
+	ScopeGroup     = "group"     // every identity in a group
+	ScopeNamespace = "namespace" // every Kubernetes service account in a namespace
+	ScopePool      = "pool"      // every identity in the pool
+)
+
+// IdentityProvider is a google_iam_workload_identity_pool_provider declared in Terraform
+type IdentityProvider struct {
+	Address            string `json:"address"`                       // Terraform address of the provider
+	Pool               string `json:"pool"`                          // Pool ID, e.g. "github"
+	ID                 string `json:"id"`                            // Provider ID, e.g. "github-actions"
+	Kind               string `json:"kind"`                          // github, aws, oidc or saml
+	Issuer             string `json:"issuer,omitempty"`              // OIDC issuer URI
+	AttributeCondition string `json:"attribute_condition,omitempty"` // CEL condition every external token must satisfy
+}
+
+// ExternalIdentity is an IAM member that stands for identities outside Google Cloud:
+// a federated principal or principal set, or a Kubernetes service account using GKE Workload Identity
+type ExternalIdentity struct {
+	Principal  string   `json:"principal"`            // Member as it appears in bindings
+	Kind       string   `json:"kind"`                 // One of the External* constants
+	Pool       string   `json:"pool"`                 // Workload identity pool ID, or "<project>.svc.id.goog" for GKE
+	Scope      string   `json:"scope"`                // One of the Scope* constants
+	Attribute  string   `json:"attribute,omitempty"`  // Attribute name for attribute scopes, e.g. "repository"
+	Value      string   `json:"value,omitempty"`      // Subject, attribute value, group, namespace or "namespace/ksa"
+	Providers  []string `json:"providers,omitempty"`  // Terraform addresses of the pool's providers
+	Conditions []string `json:"conditions,omitempty"` // Attribute conditions of the pool's providers
+}
+
+// Description names the identity in plain words, e.g. "GitHub repository org/repo"
+func (id ExternalIdentity) Description() string {
+	switch id.Kind {
+	case ExternalGitHub:
+		switch {
+		case id.Attribute == "repository":
+			return "GitHub repository " + id.Value
+		case id.Attribute == "repository_owner":
+			return "GitHub repositories of " + id.Value
+		case id.Scope == ScopeSubject:
+			return "GitHub subject " + id.Value
+		}
+	case ExternalKubernetes:
+		switch id.Scope {
+		case ScopeSubject:
+			return "Kubernetes service account " + id.Value
+		case ScopeNamespace:
+			return "Kubernetes namespace " + id.Value
+		}
+	case ExternalAWS:
+		if id.Attribute == "aws_role" {
+			return "AWS role " + id.Value
+		}
+	}
+	switch id.Scope {
+	case ScopePool:
+		return fmt.Sprintf("every identity in pool %s", id.Pool)
+	case ScopeAttribute:
+		return fmt.Sprintf("%s identities with %s=%s", id.Kind, id.Attribute, id.Value)
+	}
+	return fmt.Sprintf("%s %s %s", id.Kind, id.Scope, id.Value)
+}
+
+// FindIdentityProviders returns the workload identity pool providers among resources.
+// The pool ID may be given directly or as a reference to a google_iam_workload_identity_pool.
+func FindIdentityProviders(resources []parser.ResourceInstance) []IdentityProvider {
+	pools := make(map[string]string)
+	for _, r := range resources {
+		if r.Type == IdentityPoolType {
+			pools[r.Address] = r.Values["workload_identity_pool_id"]
+		}
+	}
+
+	var providers []IdentityProvider
+	for _, r := range resources {
+		if r.Type != IdentityPoolProviderType {
+			continue
+		}
+		pool := r.Values["workload_identity_pool_id"]
+		if pool == "" {
+			pool = pools[r.References["workload_identity_pool_id"]]
+		}
+		provider := IdentityProvider{
+			Address:            r.Address,
+			Pool:               pool,
+			ID:                 r.Values["workload_identity_pool_provider_id"],
+			Kind:               ExternalOIDC,
+			Issuer:             r.Values["oidc.issuer_uri"],
+			AttributeCondition: r.Values["attribute_condition"],
+		}
+		switch {
+		case r.Values["aws.account_id"] != "":
+			provider.Kind = ExternalAWS
+		case r.Values["saml.idp_metadata_xml"] != "" || r.References["saml.idp_metadata_xml"] != "":
+			provider.Kind = ExternalSAML
+		case strings.Contains(provider.Issuer, "token.actions.githubusercontent.com"):
+			provider.Kind = ExternalGitHub
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// ParseExternalIdentity parses a federated or GKE Workload Identity member. It returns false for other members.
+//
+// Supported forms:
+//
+//	principal://iam.googleapis.com/projects/N/locations/global/workloadIdentityPools/POOL/subject/SUBJECT
+//	principalSet://iam.googleapis.com/projects/N/locations/global/workloadIdentityPools/POOL/attribute.NAME/VALUE
+//	principalSet://iam.googleapis.com/projects/N/locations/global/workloadIdentityPools/POOL/group/GROUP
+//	principalSet://iam.googleapis.com/projects/N/locations/global/workloadIdentityPools/POOL/namespace/NS (GKE pools)
+//	principalSet://iam.googleapis.com/projects/N/locations/global/workloadIdentityPools/POOL/*
+//	serviceAccount:PROJECT.svc.id.goog[NS/KSA]
+func ParseExternalIdentity(member string) (ExternalIdentity, bool) {
+	if rest, ok := strings.CutPrefix(member, "serviceAccount:"); ok {
+		pool, ksa, ok := strings.Cut(rest, "[")
+		if !ok || !strings.HasSuffix(pool, ".svc.id.goog") || !strings.HasSuffix(ksa, "]") {
+			return ExternalIdentity{}, false
+		}
+		return ExternalIdentity{
+			Principal: member, Kind: ExternalKubernetes, Pool: pool,
+			Scope: ScopeSubject, Value: strings.TrimSuffix(ksa, "]"),
+		}, true
+	}
+
+	if !strings.HasPrefix(member, "principal://") && !strings.HasPrefix(member, "principalSet://") {
+		return ExternalIdentity{}, false
+	}
+	_, path, _ := strings.Cut(member, "/workloadIdentityPools/")
+	pool, selector, ok := strings.Cut(path, "/")
+	if !ok || pool == "" {
+		return ExternalIdentity{}, false
+	}
+
+	id := ExternalIdentity{Principal: member, Kind: ExternalUnknown, Pool: pool}
+	if strings.HasSuffix(pool, ".svc.id.goog") {
+		id.Kind = ExternalKubernetes
+	}
+	key, value, _ := strings.Cut(selector, "/")
+	switch {
+	case selector == "*":
+		id.Scope = ScopePool
+	case key == "subject":
+		id.Scope = ScopeSubject
+		id.Value = value
+		// GKE subjects are "ns/NS/sa/KSA"
+		if parts := strings.Split(value, "/"); id.Kind == ExternalKubernetes && len(parts) == 4 && parts[0] == "ns" && parts[2] == "sa" {
+			id.Value = parts[1] + "/" + parts[3]
+		}
+	case key == "group":
+		id.Scope = ScopeGroup
+		id.Value = value
+	case key == "namespace":
+		id.Scope = ScopeNamespace
+		id.Value = value
+	case strings.HasPrefix(key, "attribute."):
+		id.Scope = ScopeAttribute
+		id.Attribute = strings.TrimPrefix(key, "attribute.")
+		id.Value = value
+	default:
+		id.Scope = key
+		id.Value = value
+	}
+	return id, true
+}
+
+// FindExternalIdentities returns the distinct external identities among binding members, sorted by principal.
+// The kind of a federated identity comes from the providers of its pool, or failing that from the shape
+// of its attribute or subject; the providers' attribute conditions are attached.
+func FindExternalIdentities(bindings []parser.IAMBinding, providers []IdentityProvider) []ExternalIdentity {
+	byPool := make(map[string][]IdentityProvider)
+	for _, p := range providers {
+		byPool[p.Pool] = append(byPool[p.Pool], p)
+	}
+
+	seen := make(map[string]bool)
+	var identities []ExternalIdentity
+	for _, b := range bindings {
+		for _, member := range b.Members {
+			if seen[member] {
+				continue
+			}
+			seen[member] = true
+			id, ok := ParseExternalIdentity(member)
+			if !ok {
+				continue
+			}
+			for _, p := range byPool[id.Pool] {
+				id.Providers = append(id.Providers, p.Address)
+				if p.AttributeCondition != "" {
+					id.Conditions = append(id.Conditions, p.AttributeCondition)
+				}
+			}
+			if id.Kind == ExternalUnknown {
+				id.Kind = inferExternalKind(id, byPool[id.Pool])
+			}
+			identities = append(identities, id)
+		}
+	}
+
+	sort.Slice(identities, func(i, j int) bool { return identities[i].Principal < identities[j].Principal })
+	return identities
+}
+
+// inferExternalKind returns the kind shared by a pool's providers, or guesses it from the identity itself
+func inferExternalKind(id ExternalIdentity, providers []IdentityProvider) string {
+	kind := ""
+	for _, p := range providers {
+		if kind != "" && p.Kind != kind {
+			return ExternalUnknown
+		}
+		kind = p.Kind
+	}
+	if kind != "" {
+		return kind
+	}
+
+	switch {
+	case strings.HasPrefix(id.Attribute, "repository"), strings.HasPrefix(id.Value, "repo:"):
+		return ExternalGitHub
+	case strings.HasPrefix(id.Attribute, "aws_"), strings.HasPrefix(id.Value, "arn:aws:"):
+		return ExternalAWS
+	}
+	return ExternalUnknown
+}
+
+// ParseIdentitySelector splits a selector such as "github:org/repo", "kubernetes:ns/ksa" (or "k8s:")
+// or "aws:arn:aws:iam::123456789012:role/deployer" into its kind and value
+func ParseIdentitySelector(selector string) (kind, value string, ok bool) {
+	kind, value, ok = strings.Cut(selector, ":")
+	if !ok || value == "" {
+		return "", "", false
+	}
+	if kind == "k8s" {
+		kind = ExternalKubernetes
+	}
+	switch kind {
+	case ExternalGitHub, ExternalKubernetes, ExternalAWS, ExternalOIDC, ExternalSAML:
+		return kind, value, true
+	}
+	return "", "", false
+}
+
+// Covers reports whether an external identity of the given kind and value belongs to this member.
+// A GitHub repository "org/repo" is covered by its repository, its owner, a subject of the repository
+// and a pool-wide member; a Kubernetes "ns/ksa" by the service account, its namespace and the pool.
+func (id ExternalIdentity) Covers(kind, value string) bool {
+	if id.Kind != kind {
+		return false
+	}
+	if id.Scope == ScopePool {
+		return true
+	}
+
+	switch kind {
+	case ExternalGitHub:
+		owner, _, _ := strings.Cut(value, "/")
+		switch {
+		case id.Attribute == "repository":
+			return strings.EqualFold(id.Value, value)
+		case id.Attribute == "repository_owner":
+			return strings.EqualFold(id.Value, owner)
+		case id.Scope == ScopeSubject:
+			return strings.HasPrefix(strings.ToLower(id.Value), "repo:"+strings.ToLower(value)+":")
+		}
+	case ExternalKubernetes:
+		namespace, _, _ := strings.Cut(value, "/")
+		if id.Scope == ScopeNamespace {
+			return id.Value == namespace
+		}
+	case ExternalAWS:
+		// The mapped role is usually an assumed-role ARN while selectors name the IAM role, so compare role names
+		if id.Attribute == "aws_role" {
+			return lastPathSegment(id.Value) == lastPathSegment(value)
+		}
+	}
+	return id.Value == value
+}
+
+// MatchExternalIdentities returns the identities that cover the external identity a selector names
+func MatchExternalIdentities(selector string, identities []ExternalIdentity) []ExternalIdentity {
+	kind, value, ok := ParseIdentitySelector(selector)
+	if !ok {
+		return nil
+	}
+	var matched []ExternalIdentity
+	for _, id := range identities {
+		if id.Covers(kind, value) {
+			matched = append(matched, id)
+		}
+	}
+	return matched
+}
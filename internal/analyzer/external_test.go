@@ -0,0 +1,182 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+const poolPrefix = "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/"
+
+func TestParseExternalIdentity(t *testing.T) {
+	tests := []struct {
+		member string
+		want   ExternalIdentity
+		ok     bool
+	}{
+		{
+			member: "principalSet:" + poolPrefix + "gh/attribute.repository/acme/infra",
+			want:   ExternalIdentity{Kind: ExternalUnknown, Pool: "gh", Scope: ScopeAttribute, Attribute: "repository", Value: "acme/infra"},
+			ok:     true,
+		},
+		{
+			member: "principal:" + poolPrefix + "gh/subject/repo:acme/infra:ref:refs/heads/main",
+			want:   ExternalIdentity{Kind: ExternalUnknown, Pool: "gh", Scope: ScopeSubject, Value: "repo:acme/infra:ref:refs/heads/main"},
+			ok:     true,
+		},
+		{
+			member: "principalSet:" + poolPrefix + "gh/*",
+			want:   ExternalIdentity{Kind: ExternalUnknown, Pool: "gh", Scope: ScopePool},
+			ok:     true,
+		},
+		{
+			member: "principal:" + poolPrefix + "app.svc.id.goog/subject/ns/jobs/sa/runner",
+			want:   ExternalIdentity{Kind: ExternalKubernetes, Pool: "app.svc.id.goog", Scope: ScopeSubject, Value: "jobs/runner"},
+			ok:     true,
+		},
+		{
+			member: "principalSet:" + poolPrefix + "app.svc.id.goog/namespace/jobs",
+			want:   ExternalIdentity{Kind: ExternalKubernetes, Pool: "app.svc.id.goog", Scope: ScopeNamespace, Value: "jobs"},
+			ok:     true,
+		},
+		{
+			member: "serviceAccount:app.svc.id.goog[jobs/runner]",
+			want:   ExternalIdentity{Kind: ExternalKubernetes, Pool: "app.svc.id.goog", Scope: ScopeSubject, Value: "jobs/runner"},
+			ok:     true,
+		},
+		{member: "serviceAccount:ci@app.iam.gserviceaccount.com"},
+		{member: "user:alice@example.com"},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseExternalIdentity(tt.member)
+		if ok != tt.ok {
+			t.Errorf("ParseExternalIdentity(%q) ok = %v, want %v", tt.member, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		tt.want.Principal = tt.member
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseExternalIdentity(%q) = %+v, want %+v", tt.member, got, tt.want)
+		}
+	}
+}
+
+func TestFindExternalIdentities(t *testing.T) {
+	resources := []parser.ResourceInstance{
+		{Type: IdentityPoolType, Address: "google_iam_workload_identity_pool.gh", Values: map[string]string{"workload_identity_pool_id": "gh"}},
+		{
+			Type: IdentityPoolProviderType, Address: "google_iam_workload_identity_pool_provider.gh",
+			Values: map[string]string{
+				"workload_identity_pool_provider_id": "actions",
+				"attribute_condition":                "assertion.repository_owner == 'acme'",
+				"oidc.issuer_uri":                    "https://token.actions.githubusercontent.com",
+			},
+			References: map[string]string{"workload_identity_pool_id": "google_iam_workload_identity_pool.gh"},
+		},
+		{
+			Type: IdentityPoolProviderType, Address: "google_iam_workload_identity_pool_provider.aws",
+			Values: map[string]string{"workload_identity_pool_id": "aws", "aws.account_id": "123456789012"},
+		},
+	}
+	providers := FindIdentityProviders(resources)
+	if len(providers) != 2 || providers[0].Pool != "gh" || providers[0].Kind != ExternalGitHub || providers[1].Kind != ExternalAWS {
+		t.Fatalf("FindIdentityProviders() = %+v", providers)
+	}
+
+	repo := "principalSet:" + poolPrefix + "gh/attribute.repository/acme/infra"
+	role := "principalSet:" + poolPrefix + "aws/attribute.aws_role/arn:aws:sts::123456789012:assumed-role/deployer"
+	owner := "principalSet:" + poolPrefix + "other/attribute.repository_owner/acme"
+	bindings := []parser.IAMBinding{
+		{Role: "roles/iam.workloadIdentityUser", Members: []string{repo, "user:alice@example.com"}},
+		{Role: "roles/iam.workloadIdentityUser", Members: []string{role, owner, repo}},
+	}
+
+	// Sorted by principal
+	want := []ExternalIdentity{
+		{
+			Principal: role, Kind: ExternalAWS, Pool: "aws", Scope: ScopeAttribute, Attribute: "aws_role",
+			Value:     "arn:aws:sts::123456789012:assumed-role/deployer",
+			Providers: []string{"google_iam_workload_identity_pool_provider.aws"},
+		},
+		{
+			Principal: repo, Kind: ExternalGitHub, Pool: "gh", Scope: ScopeAttribute, Attribute: "repository", Value: "acme/infra",
+			Providers:  []string{"google_iam_workload_identity_pool_provider.gh"},
+			Conditions: []string{"assertion.repository_owner == 'acme'"},
+		},
+		{Principal: owner, Kind: ExternalGitHub, Pool: "other", Scope: ScopeAttribute, Attribute: "repository_owner", Value: "acme"},
+	}
+	got := FindExternalIdentities(bindings, providers)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindExternalIdentities() = %+v, want %+v", got, want)
+	}
+
+	matched := MatchExternalIdentities("github:acme/infra", got)
+	if len(matched) != 2 || matched[0].Principal != repo || matched[1].Principal != owner {
+		t.Errorf("MatchExternalIdentities(github:acme/infra) = %+v, want the repository and owner members", matched)
+	}
+	if matched := MatchExternalIdentities("aws:arn:aws:iam::123456789012:role/deployer", got); len(matched) != 1 || matched[0].Principal != role {
+		t.Errorf("MatchExternalIdentities(aws role) = %+v, want the aws_role member", matched)
+	}
+	if matched := MatchExternalIdentities("alice@example.com", got); matched != nil {
+		t.Errorf("MatchExternalIdentities(email) = %+v, want nil", matched)
+	}
+}
+
+func TestFindIdentityProviders_Plan(t *testing.T) {
+	// The provider's pool ID is computed from the pool created by the plan; the configuration names the pool
+	resources := parsePlanResources(t, IdentityPoolResourceTypes, []parser.Resource{
+		{Address: "google_iam_workload_identity_pool.gh", Mode: "managed", Type: IdentityPoolType, Values: map[string]interface{}{"workload_identity_pool_id": "gh"}},
+		{
+			Address: "google_iam_workload_identity_pool_provider.gh", Mode: "managed", Type: IdentityPoolProviderType,
+			Values: map[string]interface{}{
+				"workload_identity_pool_provider_id": "actions",
+				"oidc":                               []interface{}{map[string]interface{}{"issuer_uri": "https://token.actions.githubusercontent.com"}},
+			},
+		},
+	}, []parser.PlanConfigResource{
+		{
+			Address: "google_iam_workload_identity_pool_provider.gh", Mode: "managed",
+			Expressions: map[string]interface{}{
+				"workload_identity_pool_id": map[string]interface{}{"references": []interface{}{
+					"google_iam_workload_identity_pool.gh.workload_identity_pool_id", "google_iam_workload_identity_pool.gh",
+				}},
+			},
+		},
+	})
+
+	providers := FindIdentityProviders(resources)
+	if len(providers) != 1 || providers[0].Pool != "gh" || providers[0].Kind != ExternalGitHub {
+		t.Errorf("FindIdentityProviders() = %+v, want the GitHub provider of pool gh", providers)
+	}
+}
+
+func TestExternalIdentityCovers(t *testing.T) {
+	tests := []struct {
+		member string
+		kind   string
+		value  string
+		want   bool
+	}{
+		{"principal:" + poolPrefix + "gh/subject/repo:acme/infra:ref:refs/heads/main", ExternalGitHub, "acme/infra", true},
+		{"principal:" + poolPrefix + "gh/subject/repo:acme/infra-old:ref:refs/heads/main", ExternalGitHub, "acme/infra", false},
+		{"principalSet:" + poolPrefix + "app.svc.id.goog/namespace/jobs", ExternalKubernetes, "jobs/runner", true},
+		{"serviceAccount:app.svc.id.goog[jobs/runner]", ExternalKubernetes, "jobs/runner", true},
+		{"serviceAccount:app.svc.id.goog[jobs/other]", ExternalKubernetes, "jobs/runner", false},
+		{"principalSet:" + poolPrefix + "app.svc.id.goog/*", ExternalKubernetes, "jobs/runner", true},
+	}
+
+	for _, tt := range tests {
+		id, _ := ParseExternalIdentity(tt.member)
+		id.Kind = inferExternalKind(id, nil)
+		if id.Pool == "app.svc.id.goog" {
+			id.Kind = ExternalKubernetes
+		}
+		if got := id.Covers(tt.kind, tt.value); got != tt.want {
+			t.Errorf("%s Covers(%s:%s) = %v, want %v", tt.member, tt.kind, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// Terraform resource types describing Cloud Identity groups
+const (
+	GroupType           = "google_cloud_identity_group"
+	GroupMembershipType = "google_cloud_identity_group_membership"
+)
+
+// GroupResourceTypes are the resource types FindGroupMemberships needs parsed
+var GroupResourceTypes = []string{GroupType, GroupMembershipType}
+
+// GroupMemberships holds the direct members of each group and resolves nested membership
+type GroupMemberships struct {
+	members map[string][]string // "group:email" -> direct member principals
+	parents map[string][]string // member principal -> groups it is a direct member of
+}
+
+// NewGroupMemberships builds memberships from group email -> members.
+// Members without a principal type prefix are typed by email: groups listed as keys are groups,
+// *.gserviceaccount.com addresses are service accounts and everything else is a user.
+func NewGroupMemberships(raw map[string][]string) *GroupMemberships {
+	known := make(map[string]bool, len(raw))
+	for group := range raw {
+		known[strings.ToLower(strings.TrimPrefix(group, "group:"))] = true
+	}
+
+	g := &GroupMemberships{
+		members: make(map[string][]string),
+		parents: make(map[string][]string),
+	}
+	for group, members := range raw {
+		groupPrincipal := "group:" + strings.TrimPrefix(group, "group:")
+		for _, member := range members {
+			principal := memberPrincipal(member, known)
+			if principal == groupPrincipal || containsString(g.members[groupPrincipal], principal) {
+				continue
+			}
+			g.members[groupPrincipal] = append(g.members[groupPrincipal], principal)
+			g.parents[principal] = append(g.parents[principal], groupPrincipal)
+		}
+	}
+	for _, groups := range g.parents {
+		sort.Strings(groups)
+	}
+	return g
+}
+
+// memberPrincipal adds a principal type prefix to a bare member email
+func memberPrincipal(member string, groups map[string]bool) string {
+	if strings.Contains(member, ":") {
+		return member
+	}
+	switch {
+	case groups[strings.ToLower(member)]:
+		return "group:" + member
+	case strings.HasSuffix(member, ".gserviceaccount.com"):
+		return "serviceAccount:" + member
+	}
+	return "user:" + member
+}
+
+// Members returns the principals that belong to at least one group, groups excluded, sorted
+func (g *GroupMemberships) Members() []string {
+	var members []string
+	for principal := range g.parents {
+		if GetPrincipalType(principal) != "group" {
+			members = append(members, principal)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// GroupPaths returns, for every group a principal belongs to directly or through nested groups,
+// the shortest path of groups leading to it, innermost first. The last element of each path is the group.
+// Example: alice ∈ devs ∈ eng → [[group:devs] [group:devs group:eng]]
+func (g *GroupMemberships) GroupPaths(principal string) [][]string {
+	if g == nil {
+		return nil
+	}
+	var paths [][]string
+	visited := map[string]bool{principal: true}
+	queue := [][]string{{}}
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		current := principal
+		if len(path) > 0 {
+			current = path[len(path)-1]
+		}
+		for _, group := range g.parents[current] {
+			if visited[group] {
+				continue
+			}
+			visited[group] = true
+			next := append(append([]string{}, path...), group)
+			paths = append(paths, next)
+			queue = append(queue, next)
+		}
+	}
+	return paths
+}
+
+// FindGroupMemberships returns group email -> member emails from google_cloud_identity_group_membership
+// resources. A membership's group must reference a google_cloud_identity_group, give its resource name
+// (groups/<id>) or give its email; members are read from preferred_member_key.
+func FindGroupMemberships(resources []parser.ResourceInstance) map[string][]string {
+	groupEmails := make(map[string]string) // group address or name -> email
+	for _, r := range resources {
+		if r.Type != GroupType || r.Values["group_key.id"] == "" {
+			continue
+		}
+		groupEmails[r.Address] = r.Values["group_key.id"]
+		if name := r.Values["name"]; name != "" {
+			groupEmails[name] = r.Values["group_key.id"]
+		}
+	}
+
+	memberships := make(map[string][]string)
+	for _, r := range resources {
+		if r.Type != GroupMembershipType {
+			continue
+		}
+		group := groupEmails[r.References["group"]]
+		if group == "" {
+			group = groupEmails[r.Values["group"]]
+		}
+		if group == "" && strings.Contains(r.Values["group"], "@") {
+			group = r.Values["group"]
+		}
+		member := r.Values["preferred_member_key.id"]
+		if group == "" || member == "" {
+			continue
+		}
+		memberships[group] = append(memberships[group], member)
+	}
+	return memberships
+}
+
+// AnalyzeWithGroups processes IAM bindings like Analyze and attributes the access of each group to its
+// members, including members of nested groups. Roles a member holds only through a group record the group
+// path in ResourceMetadata.GroupPaths; members that hold no grants of their own are added.
+func AnalyzeWithGroups(bindings []parser.IAMBinding, groups *GroupMemberships) map[string]*PrincipalData {
+	results := Analyze(bindings)
+	if groups == nil {
+		return results
+	}
+
+	for _, member := range groups.Members() {
+		for _, path := range groups.GroupPaths(member) {
+			groupData, ok := results[path[len(path)-1]]
+			if !ok {
+				continue
+			}
+			if _, exists := results[member]; !exists {
+				results[member] = &PrincipalData{
+					ResourceAccess:     make(map[string]*ResourceMetadata),
+					HierarchicalAccess: make(map[string]map[string]bool),
+				}
+			}
+			mergeGroupAccess(results[member], groupData, path)
+		}
+	}
+	return results
+}
+
+// mergeGroupAccess adds a group's access to a member's data, keeping the member's own grants and
+// the first group path found for each role
+func mergeGroupAccess(data, groupData *PrincipalData, path []string) {
+	for resID, groupMeta := range groupData.ResourceAccess {
+		meta, exists := data.ResourceAccess[resID]
+		if !exists {
+			meta = &ResourceMetadata{
+				Type:           groupMeta.Type,
+				Roles:          make(map[string]bool),
+				TerraformAddrs: make(map[string]string),
+			}
+			data.ResourceAccess[resID] = meta
+		}
+		for role := range groupMeta.Roles {
+			if meta.Roles[role] {
+				continue
+			}
+			meta.Roles[role] = true
+			if addr := groupMeta.TerraformAddrs[role]; addr != "" {
+				meta.TerraformAddrs[role] = addr
+			}
+			if meta.GroupPaths == nil {
+				meta.GroupPaths = make(map[string][]string)
+			}
+			meta.GroupPaths[role] = path
+		}
+	}
+
+	for project, types := range groupData.HierarchicalAccess {
+		if data.HierarchicalAccess[project] == nil {
+			data.HierarchicalAccess[project] = make(map[string]bool)
+		}
+		for t := range types {
+			data.HierarchicalAccess[project][t] = true
+		}
+	}
+}
+
+// AddGroups lets transitive analyses follow the impersonation grants of the groups a principal belongs to
+func (g *ImpersonationGraph) AddGroups(groups *GroupMemberships) {
+	g.Groups = groups
+}
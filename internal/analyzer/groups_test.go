@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestGroupMemberships_GroupPaths(t *testing.T) {
+	groups := NewGroupMemberships(map[string][]string{
+		"devs@example.com":   {"alice@example.com", "ci@app.iam.gserviceaccount.com"},
+		"eng@example.com":    {"devs@example.com", "group:oncall@example.com"},
+		"oncall@example.com": {"user:alice@example.com", "eng@example.com"}, // cycle through eng
+	})
+
+	want := [][]string{
+		{"group:devs@example.com"},
+		{"group:oncall@example.com"},
+		{"group:devs@example.com", "group:eng@example.com"},
+	}
+	if got := groups.GroupPaths("user:alice@example.com"); !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupPaths(alice) = %v, want %v", got, want)
+	}
+
+	if got := groups.Members(); !reflect.DeepEqual(got, []string{"serviceAccount:ci@app.iam.gserviceaccount.com", "user:alice@example.com"}) {
+		t.Errorf("Members() = %v", got)
+	}
+
+	var none *GroupMemberships
+	if got := none.GroupPaths("user:alice@example.com"); got != nil {
+		t.Errorf("nil GroupPaths() = %v, want nil", got)
+	}
+}
+
+func TestFindGroupMemberships(t *testing.T) {
+	resources := []parser.ResourceInstance{
+		{Type: GroupType, Address: "google_cloud_identity_group.devs", Values: map[string]string{"group_key.id": "devs@example.com"}},
+		{
+			Type: GroupMembershipType, Address: "google_cloud_identity_group_membership.alice",
+			Values:     map[string]string{"preferred_member_key.id": "alice@example.com"},
+			References: map[string]string{"group": "google_cloud_identity_group.devs"},
+		},
+		{
+			Type: GroupMembershipType, Address: "google_cloud_identity_group_membership.unknown_group",
+			Values: map[string]string{"group": "groups/abc123", "preferred_member_key.id": "bob@example.com"},
+		},
+	}
+
+	want := map[string][]string{"devs@example.com": {"alice@example.com"}}
+	if got := FindGroupMemberships(resources); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindGroupMemberships() = %v, want %v", got, want)
+	}
+}
+
+func TestFindGroupMemberships_Plan(t *testing.T) {
+	// Plans carry no references: memberships of existing groups name the group by its resource name
+	resources := parsePlanResources(t, GroupResourceTypes, []parser.Resource{
+		{
+			Address: "google_cloud_identity_group.devs", Mode: "managed", Type: GroupType,
+			Values: map[string]interface{}{
+				"name":      "groups/abc",
+				"group_key": []interface{}{map[string]interface{}{"id": "devs@example.com"}},
+			},
+		},
+		{
+			Address: "google_cloud_identity_group_membership.alice", Mode: "managed", Type: GroupMembershipType,
+			Values: map[string]interface{}{
+				"group":                "groups/abc",
+				"preferred_member_key": []interface{}{map[string]interface{}{"id": "alice@example.com"}},
+			},
+		},
+	}, nil)
+
+	want := map[string][]string{"devs@example.com": {"alice@example.com"}}
+	if got := FindGroupMemberships(resources); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindGroupMemberships() = %v, want %v", got, want)
+	}
+}
+
+// parsePlanResources writes a plan of the given planned resources and configuration blocks, whose
+// expressions record what unknown values refer to, and parses the resources of types from it
+func parsePlanResources(t *testing.T, types []string, planned []parser.Resource, config []parser.PlanConfigResource) []parser.ResourceInstance {
+	t.Helper()
+	resources, err := parser.ParsePlanResources(writePlan(t, parser.TerraformPlan{
+		PlannedValues: parser.PlannedValues{RootModule: parser.Module{Resources: planned}},
+		Configuration: parser.PlanConfiguration{RootModule: parser.PlanConfigModule{Resources: config}},
+	}), types)
+	if err != nil {
+		t.Fatalf("ParsePlanResources() error = %v", err)
+	}
+	return resources
+}
+
+// writePlan writes plan as JSON to a temporary file and returns its path
+func writePlan(t *testing.T, plan parser.TerraformPlan) string {
+	t.Helper()
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	planFile := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(planFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return planFile
+}
+
+func TestAnalyzeWithGroups(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	deployer := "serviceAccount:deployer@app.iam.gserviceaccount.com"
+	bindings := []parser.IAMBinding{
+		{
+			ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project",
+			Role: "roles/viewer", Members: []string{"group:eng@example.com"}, TerraformAddr: "google_project_iam_member.eng",
+		},
+		{
+			ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project",
+			Role: "roles/browser", Members: []string{"user:alice@example.com"},
+		},
+		{
+			ResourceID:   "projects/app/serviceAccounts/deployer@app.iam.gserviceaccount.com",
+			ResourceType: "google_service_account_iam_member", ResourceLevel: "resource",
+			Role: "roles/iam.serviceAccountTokenCreator", Members: []string{"group:devs@example.com"},
+		},
+		{
+			ResourceID: "prod", ResourceType: "google_project_iam_member", ResourceLevel: "project",
+			Role: "roles/owner", Members: []string{deployer},
+		},
+	}
+	groups := NewGroupMemberships(map[string][]string{
+		"devs@example.com": {"alice@example.com"},
+		"eng@example.com":  {"devs@example.com", "bob@example.com"},
+	})
+
+	results := AnalyzeWithGroups(bindings, groups)
+
+	app := results["user:alice@example.com"].ResourceAccess["app"]
+	if !app.Roles["roles/viewer"] || !app.Roles["roles/browser"] {
+		t.Fatalf("alice roles on app = %v, want viewer and browser", app.Roles)
+	}
+	if got := app.GroupPaths["roles/viewer"]; !reflect.DeepEqual(got, []string{"group:devs@example.com", "group:eng@example.com"}) {
+		t.Errorf("GroupPaths[viewer] = %v", got)
+	}
+	if _, ok := app.GroupPaths["roles/browser"]; ok {
+		t.Errorf("alice's own browser grant should have no group path")
+	}
+	if app.TerraformAddrs["roles/viewer"] != "google_project_iam_member.eng" {
+		t.Errorf("TerraformAddrs[viewer] = %q", app.TerraformAddrs["roles/viewer"])
+	}
+	// bob holds no grants of his own
+	if bob, ok := results["user:bob@example.com"]; !ok || !bob.ResourceAccess["app"].Roles["roles/viewer"] {
+		t.Errorf("bob should get roles/viewer on app through eng")
+	}
+
+	graph := BuildImpersonationGraph(bindings)
+	graph.AddGroups(groups)
+	access := AnalyzeTransitiveAccess("alice@example.com", results, graph)
+	prod, ok := access.TransitiveAccess["prod"]
+	if !ok || !prod.Resource.Roles["roles/owner"] {
+		t.Fatalf("alice should reach roles/owner on prod through devs, got %+v", access.TransitiveAccess)
+	}
+	if edge := prod.ViaEdges[0]; edge.Source != "group:devs@example.com" || !reflect.DeepEqual(edge.ViaGroups, []string{"group:devs@example.com"}) {
+		t.Errorf("first hop = %+v, want the devs grant with its group path", edge)
+	}
+}
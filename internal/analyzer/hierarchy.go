@@ -46,13 +46,16 @@ type Source struct {
 
 // HierarchicalAccessEntry represents a single hierarchical access grant
 type HierarchicalAccessEntry struct {
-	Principal      string `json:"principal"`
-	PrincipalType  string `json:"principal_type"`
-	Role           string `json:"role"`
-	Scope          Scope  `json:"scope"`
-	Grants         Grants `json:"grants"`
-	HierarchyKnown bool   `json:"hierarchy_known"`
-	Source         Source `json:"source"`
+	Principal      string              `json:"principal"`
+	PrincipalType  string              `json:"principal_type"`
+	Role           string              `json:"role"`
+	Scope          Scope               `json:"scope"`
+	Grants         Grants              `json:"grants"`
+	HierarchyKnown bool                `json:"hierarchy_known"`
+	Source         Source              `json:"source"`
+	InheritedFrom  *Scope              `json:"inherited_from,omitempty"` // For grants inherited by a descendant of the bound scope, the bound scope
+	Path           []Scope             `json:"path,omitempty"`           // For inherited grants, the scopes from InheritedFrom down to Scope, outermost first
+	Resources      []InventoryResource `json:"resources,omitempty"`      // For project scopes, the declared resources the grant reaches, see ExpandHierarchicalAccess
 }
 
 // Warning represents an issue found during analysis
@@ -83,37 +86,37 @@ var hierarchyOrder = map[string]int{
 
 // AnalyzeHierarchy performs comprehensive hierarchy analysis
 func AnalyzeHierarchy(bindings []parser.IAMBinding) *HierarchyAnalysisResult {
+	return AnalyzeHierarchyWithTree(bindings, BuildResourceTree(bindings))
+}
+
+// AnalyzeHierarchyWithTree performs hierarchy analysis against a reconstructed resource tree,
+// to which the scopes of the bindings are added.
+// Besides the entry on the bound scope, every grant gets an inherited entry on each folder and project
+// known below it, recording the path the grant travels.
+func AnalyzeHierarchyWithTree(bindings []parser.IAMBinding, tree *ResourceTree) *HierarchyAnalysisResult {
+	tree.AddBindings(bindings)
+
 	result := &HierarchyAnalysisResult{
-		Nodes:              []HierarchyNode{},
+		Nodes:              tree.Nodes(),
 		Unknown:            []UnknownHierarchy{},
 		HierarchicalAccess: []HierarchicalAccessEntry{},
 		Warnings:           []Warning{},
 	}
 
-	// Track known hierarchy nodes to avoid duplicates
-	knownNodes := make(map[string]bool) // "type:id" -> true
+	// Track nodes already checked for an unknown hierarchy
+	checkedNodes := make(map[string]bool) // "type:id" -> true
 
 	// Track unknown references for grouping
 	unknownRefs := make(map[string][]string) // "type:id" -> []terraform addresses
 
-	// 1. Build hierarchy nodes from bindings
+	// 1. Find bound scopes whose parent is unknown
 	for _, binding := range bindings {
 		if isHierarchyLevel(binding.ResourceLevel) {
 			nodeKey := binding.ResourceLevel + ":" + binding.ResourceID
-			if !knownNodes[nodeKey] {
-				knownNodes[nodeKey] = true
-				node := HierarchyNode{
-					ID:         binding.ResourceID,
-					Type:       binding.ResourceLevel,
-					ParentID:   binding.ParentID,
-					ParentType: binding.ParentType,
-				}
-				result.Nodes = append(result.Nodes, node)
-
-				// Track if hierarchy is unknown
-				if binding.ParentID == "" && binding.ResourceLevel != "organization" {
-					unknownKey := binding.ResourceLevel + ":" + binding.ResourceID
-					unknownRefs[unknownKey] = append(unknownRefs[unknownKey], binding.TerraformAddr)
+			if !checkedNodes[nodeKey] {
+				checkedNodes[nodeKey] = true
+				if binding.ResourceLevel != "organization" && !tree.HasParent(scopeKey(binding.ResourceLevel, binding.ResourceID)) {
+					unknownRefs[nodeKey] = append(unknownRefs[nodeKey], binding.TerraformAddr)
 				}
 			}
 		}
@@ -163,12 +166,12 @@ func AnalyzeHierarchy(bindings []parser.IAMBinding) *HierarchyAnalysisResult {
 		}
 
 		// Determine if hierarchy is known
-		hierarchyKnown := binding.ParentID != "" || binding.ResourceLevel == "organization"
+		hierarchyKnown := binding.ResourceLevel == "organization" || tree.HasParent(scopeKey(binding.ResourceLevel, binding.ResourceID))
 
 		// Add warning for unknown hierarchy (only once per scope)
-		scopeKey := binding.ResourceLevel + ":" + binding.ResourceID
-		if !hierarchyKnown && !warnedScopes[scopeKey] {
-			warnedScopes[scopeKey] = true
+		warnKey := binding.ResourceLevel + ":" + binding.ResourceID
+		if !hierarchyKnown && !warnedScopes[warnKey] {
+			warnedScopes[warnKey] = true
 			result.Warnings = append(result.Warnings, Warning{
 				Type:      "unknown_hierarchy",
 				ScopeID:   binding.ResourceID,
@@ -200,17 +203,22 @@ func AnalyzeHierarchy(bindings []parser.IAMBinding) *HierarchyAnalysisResult {
 				},
 			}
 			result.HierarchicalAccess = append(result.HierarchicalAccess, entry)
+
+			// The same grant on every descendant of the scope
+			for _, path := range tree.Descendants(scopeKey(binding.ResourceLevel, binding.ResourceID)) {
+				inherited := entry
+				inherited.Scope = path[len(path)-1]
+				inherited.Grants.AffectedLevels = getAffectedLevels(inherited.Scope.Type)
+				inherited.HierarchyKnown = true
+				boundScope := entry.Scope
+				inherited.InheritedFrom = &boundScope
+				inherited.Path = path
+				result.HierarchicalAccess = append(result.HierarchicalAccess, inherited)
+			}
 		}
 	}
 
 	// Sort results for deterministic output
-	sort.Slice(result.Nodes, func(i, j int) bool {
-		if result.Nodes[i].Type != result.Nodes[j].Type {
-			return result.Nodes[i].Type < result.Nodes[j].Type
-		}
-		return result.Nodes[i].ID < result.Nodes[j].ID
-	})
-
 	sort.Slice(result.Unknown, func(i, j int) bool {
 		if result.Unknown[i].Type != result.Unknown[j].Type {
 			return result.Unknown[i].Type < result.Unknown[j].Type
@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
@@ -9,20 +10,138 @@ import (
 
 // ImpersonationGraph represents the impersonation relationships between principals
 type ImpersonationGraph struct {
-	Graph map[string][]string // principal → service accounts they can impersonate
+	Graph     map[string][]ImpersonationEdge // principal → edges to the service accounts they can impersonate
+	Keys      []ServiceAccountKey            // long-lived keys declared in Terraform, see AddKeys
+	Groups    *GroupMemberships              // group memberships followed by transitive analyses, see AddGroups
+	Deny      *DenyPolicies                  // deny policies that can take hops away, see AddDenyPolicies
+	Hierarchy *HierarchyAnalysisResult       // hierarchy entries carried through impersonation hops, see AddHierarchy
+
+	deployRoles map[string]map[string]string // principal → "type:id" scope → first deploy role granted there
+	parents     map[string]string            // scope → parent scope, see buildParentMap
+}
+
+// ImpersonationEdge is a single impersonation relationship and the grant that created it
+type ImpersonationEdge struct {
+	Source        string   `json:"source"`                   // Principal holding the role (e.g. "user:alice@example.com")
+	Target        string   `json:"target"`                   // Service account principal that can be impersonated
+	Role          string   `json:"role"`                     // Role that grants the impersonation
+	Capability    string   `json:"capability"`               // What the role allows, see definitions.Capability*
+	Scope         string   `json:"scope"`                    // Where the role was granted, as "level:id" (e.g. "folder:123")
+	Condition     string   `json:"condition,omitempty"`      // IAM condition expression, if the grant is conditional
+	SourceAddress string   `json:"source_address,omitempty"` // Terraform address of the binding
+	Inherited     bool     `json:"inherited,omitempty"`      // True when granted on a project, folder or organization rather than the service account
+	DeployRole    string   `json:"deploy_role,omitempty"`    // For actAs edges, a role of the acting account or its groups that can deploy compute in the target's project
+	Workload      string   `json:"workload,omitempty"`       // For workload_control edges, Terraform address of the resource running as the target
+	ViaGroups     []string `json:"via_groups,omitempty"`     // When Source is a group, the path of groups from the chain's principal to it, innermost first
+}
+
+// Effective reports whether the edge hands over the target's access on its own.
+// An actAs edge only does when the source can also deploy compute that runs as the target.
+func (e ImpersonationEdge) Effective() bool {
+	return e.Capability != definitions.CapabilityActAs || e.DeployRole != ""
+}
+
+// Targets returns the distinct service accounts a principal can impersonate, in edge order
+func (g *ImpersonationGraph) Targets(source string) []string {
+	var targets []string
+	seen := make(map[string]bool)
+	for _, e := range g.Graph[source] {
+		if !seen[e.Target] {
+			seen[e.Target] = true
+			targets = append(targets, e.Target)
+		}
+	}
+	return targets
+}
+
+// Edge returns the edge from source to target that best explains the hop:
+// effective edges win over unconfirmed actAs edges, then direct grants over inherited ones
+func (g *ImpersonationGraph) Edge(source, target string) (ImpersonationEdge, bool) {
+	var fallback *ImpersonationEdge
+	for i, e := range g.Graph[source] {
+		if e.Target != target {
+			continue
+		}
+		if e.Effective() {
+			return e, true
+		}
+		if fallback == nil {
+			fallback = &g.Graph[source][i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return ImpersonationEdge{}, false
+}
+
+// addEdge adds an edge unless an identical grant (same target, role, scope and capability) is already present
+func (g *ImpersonationGraph) addEdge(edge ImpersonationEdge) {
+	for _, e := range g.Graph[edge.Source] {
+		if e.Target == edge.Target && e.Role == edge.Role && e.Scope == edge.Scope && e.Capability == edge.Capability {
+			return
+		}
+	}
+	g.Graph[edge.Source] = append(g.Graph[edge.Source], edge)
+}
+
+// newEdge creates the edge a binding grants from member to target
+func newEdge(b parser.IAMBinding, member, target string) ImpersonationEdge {
+	edge := ImpersonationEdge{
+		Source:        member,
+		Target:        target,
+		Role:          b.Role,
+		Capability:    definitions.ImpersonationCapability(b.Role),
+		Scope:         scopeKey(b.ResourceLevel, b.ResourceID),
+		SourceAddress: b.TerraformAddr,
+		Inherited:     isHierarchyLevel(b.ResourceLevel),
+	}
+	if b.Condition != nil {
+		edge.Condition = b.Condition.Expression
+	}
+	return edge
 }
 
 // TransitiveAccess represents the complete access analysis for a principal including impersonation
 type TransitiveAccess struct {
-	Principal        string
-	DirectAccess     *PrincipalData
-	TransitiveAccess map[string]*AccessVia // resourceID → how it was accessed
+	Principal          string
+	DirectAccess       *PrincipalData
+	TransitiveAccess   map[string]*AccessVia       // resourceID → how it was accessed
+	HierarchicalAccess map[string]*HierarchicalVia // "type:id" scope → project, folder or organization access gained through impersonation
+	Keys               []ServiceAccountKey         // Terraform-declared keys of the principal and of every service account it can reach
+	Denied             []DeniedAccess              // impersonation hops deny policies revoke or make conditional
 }
 
+// Chain types, describing how an impersonation chain turns into access
+const (
+	ChainDirectImpersonation = "direct_impersonation" // every hop mints credentials for the next service account
+	ChainActAsViaCompute     = "act_as_via_compute"   // at least one actAs hop, each backed by a deploy role
+	ChainActAsUnconfirmed    = "act_as_unconfirmed"   // at least one actAs hop without a known deploy role
+	ChainViaWorkload         = "via_workload"         // at least one hop through a workload the source can modify, all actAs hops confirmed
+)
+
 // AccessVia represents access obtained through impersonation
 type AccessVia struct {
-	Resource *ResourceMetadata
-	ViaChain []string // Chain of impersonation (e.g., ["sa-b", "sa-c"])
+	Resource  *ResourceMetadata
+	ViaChain  []string            // Chain of impersonation (e.g., ["sa-b", "sa-c"])
+	ViaEdges  []ImpersonationEdge // The edge behind each hop of ViaChain
+	ChainType string              // One of the Chain* constants
+}
+
+// classifyChain returns the chain type of a sequence of edges
+func classifyChain(edges []ImpersonationEdge) string {
+	chainType := ChainDirectImpersonation
+	for _, e := range edges {
+		switch {
+		case !e.Effective():
+			return ChainActAsUnconfirmed
+		case e.Capability == definitions.CapabilityWorkloadControl:
+			chainType = ChainViaWorkload
+		case e.Capability == definitions.CapabilityActAs && chainType != ChainViaWorkload:
+			chainType = ChainActAsViaCompute
+		}
+	}
+	return chainType
 }
 
 // GetPrincipalType extracts the principal type from a full principal string
@@ -36,8 +155,8 @@ func GetPrincipalType(principal string) string {
 
 // CanImpersonate checks if a source principal type can impersonate a target principal type per GCP rules
 func CanImpersonate(sourcePrincipalType, targetPrincipalType string) bool {
-	// PrincipalSets cannot be impersonated by anyone
-	if targetPrincipalType == "principalSet" {
+	// PrincipalSets and federated principals cannot be impersonated by anyone
+	if targetPrincipalType == "principalSet" || targetPrincipalType == "principal" {
 		return false
 	}
 
@@ -51,11 +170,13 @@ func CanImpersonate(sourcePrincipalType, targetPrincipalType string) bool {
 	// - users
 	// - groups
 	// - principalSets
+	// - principals (single federated identities)
 	if targetPrincipalType == "serviceAccount" {
 		return sourcePrincipalType == "serviceAccount" ||
 			sourcePrincipalType == "user" ||
 			sourcePrincipalType == "group" ||
-			sourcePrincipalType == "principalSet"
+			sourcePrincipalType == "principalSet" ||
+			sourcePrincipalType == "principal"
 	}
 
 	return false
@@ -68,13 +189,20 @@ func BuildImpersonationGraph(bindings []parser.IAMBinding) *ImpersonationGraph {
 
 // BuildImpersonationGraphWithFunc scans bindings for impersonation relationships using a custom canImpersonate function
 func BuildImpersonationGraphWithFunc(bindings []parser.IAMBinding, canImpersonate func(string, string) bool) *ImpersonationGraph {
+	return BuildImpersonationGraphWithParents(bindings, canImpersonate, nil)
+}
+
+// BuildImpersonationGraphWithParents is BuildImpersonationGraphWithFunc with the scope parents grants on projects,
+// folders and organizations are inherited through, as returned by ParentMap. Nil parents are read from the bindings.
+// The graph keeps them for the service accounts, workloads and analyses added later.
+func BuildImpersonationGraphWithParents(bindings []parser.IAMBinding, canImpersonate func(string, string) bool, parents map[string]string) *ImpersonationGraph {
 	graph := &ImpersonationGraph{
-		Graph: make(map[string][]string),
+		Graph: make(map[string][]ImpersonationEdge),
 	}
 
 	for _, b := range bindings {
-		// Check if this is an impersonation role
-		if !definitions.IsImpersonationRole(b.Role) {
+		// Check if this role gives any hold over a service account
+		if definitions.ImpersonationCapability(b.Role) == "" {
 			continue
 		}
 
@@ -104,16 +232,163 @@ func BuildImpersonationGraphWithFunc(bindings []parser.IAMBinding, canImpersonat
 			}
 
 			// Add to graph
-			if _, exists := graph.Graph[member]; !exists {
-				graph.Graph[member] = []string{}
-			}
-			graph.Graph[member] = append(graph.Graph[member], targetPrincipal)
+			graph.addEdge(newEdge(b, member, targetPrincipal))
 		}
 	}
 
+	if parents == nil {
+		parents = buildParentMap(bindings)
+	}
+	addInheritedEdges(graph, bindings, knownServiceAccounts(bindings), parents, canImpersonate)
+	graph.parents = parents
+	graph.deployRoles = deployRoleGrants(bindings)
+	graph.resolveDeployRoles()
+
 	return graph
 }
 
+// addInheritedEdges adds edges from organization, folder and project grants of roles that
+// include an impersonation role or can create keys to every service account of serviceAccounts in the covered scope.
+// A service account is covered when its project is the granted scope or lies below it.
+func addInheritedEdges(graph *ImpersonationGraph, bindings []parser.IAMBinding, serviceAccounts []string, parents map[string]string, canImpersonate func(string, string) bool) {
+	for _, b := range bindings {
+		if !isHierarchyLevel(b.ResourceLevel) || definitions.ImpersonationCapability(b.Role) == "" {
+			continue
+		}
+		scope := scopeKey(b.ResourceLevel, b.ResourceID)
+
+		for _, sa := range serviceAccounts {
+			project := serviceAccountProject(sa)
+			if project == "" || !containsString(ancestorScopes(scopeKey("project", project), parents), scope) {
+				continue
+			}
+
+			targetPrincipal := "serviceAccount:" + sa
+			for _, member := range b.Members {
+				if member == targetPrincipal || !canImpersonate(GetPrincipalType(member), "serviceAccount") {
+					continue
+				}
+				graph.addEdge(newEdge(b, member, targetPrincipal))
+			}
+		}
+	}
+}
+
+// resolveDeployRoles records on every actAs edge a deploy role the source holds on the target's project or one
+// of its ancestors. Edges left without one are unconfirmed until a transitive analysis finds a deploy role of the
+// acting account or of its groups, see deployRole.
+func (g *ImpersonationGraph) resolveDeployRoles() {
+	for source, edges := range g.Graph {
+		for i := range edges {
+			if edges[i].Capability == definitions.CapabilityActAs {
+				edges[i].DeployRole = g.deployRole(source, edges[i].Target)
+			}
+		}
+	}
+}
+
+// deployRoleGrants indexes the deploy roles granted on organizations, folders and projects by member and scope
+func deployRoleGrants(bindings []parser.IAMBinding) map[string]map[string]string {
+	grants := make(map[string]map[string]string)
+	for _, b := range bindings {
+		if !isHierarchyLevel(b.ResourceLevel) || !definitions.IsDeployRole(b.Role) {
+			continue
+		}
+		scope := scopeKey(b.ResourceLevel, b.ResourceID)
+		for _, member := range b.Members {
+			if grants[member] == nil {
+				grants[member] = make(map[string]string)
+			}
+			if _, ok := grants[member][scope]; !ok {
+				grants[member][scope] = b.Role
+			}
+		}
+	}
+	return grants
+}
+
+// deployRole returns a deploy role the actor, or one of the groups it belongs to, holds on the target service
+// account's project or one of its ancestors, nearest scope first, or ""
+func (g *ImpersonationGraph) deployRole(actor, target string) string {
+	project := serviceAccountProject(strings.TrimPrefix(target, "serviceAccount:"))
+	if project == "" {
+		return ""
+	}
+	identities := []string{actor}
+	for _, path := range g.Groups.GroupPaths(actor) {
+		identities = append(identities, path[len(path)-1])
+	}
+	for _, scope := range ancestorScopes(scopeKey("project", project), g.parents) {
+		for _, identity := range identities {
+			if role := g.deployRoles[identity][scope]; role != "" {
+				return role
+			}
+		}
+	}
+	return ""
+}
+
+// knownServiceAccounts returns the sorted emails of service accounts that appear as
+// binding members or as the target of service account IAM bindings
+func knownServiceAccounts(bindings []parser.IAMBinding) []string {
+	seen := make(map[string]bool)
+	for _, b := range bindings {
+		if strings.HasPrefix(b.ResourceType, "google_service_account_iam") {
+			if email := extractServiceAccountEmail(b.ResourceID); email != "" {
+				seen[email] = true
+			}
+		}
+		for _, member := range b.Members {
+			if email, ok := strings.CutPrefix(member, "serviceAccount:"); ok {
+				seen[email] = true
+			}
+		}
+	}
+
+	emails := make([]string, 0, len(seen))
+	for email := range seen {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+	return emails
+}
+
+// AddServiceAccounts adds inherited edges to the service accounts declared as google_service_account
+// resources, which organization, folder and project grants reach even when no binding names them
+func (g *ImpersonationGraph) AddServiceAccounts(resources []parser.ResourceInstance, bindings []parser.IAMBinding, canImpersonate func(string, string) bool) {
+	seen := make(map[string]bool)
+	var emails []string
+	for _, r := range resources {
+		if r.Type != ServiceAccountType {
+			continue
+		}
+		if email := serviceAccountEmail(r); email != "" && !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	sort.Strings(emails)
+
+	addInheritedEdges(g, bindings, emails, g.parentMap(bindings), canImpersonate)
+	g.resolveDeployRoles()
+}
+
+// serviceAccountProject returns the project ID a service account belongs to, derived from its email.
+// Example: "sa@my-project.iam.gserviceaccount.com" → "my-project", "my-project@appspot.gserviceaccount.com" → "my-project"
+func serviceAccountProject(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return ""
+	}
+	if project, ok := strings.CutSuffix(domain, ".iam.gserviceaccount.com"); ok {
+		return project
+	}
+	if domain == "appspot.gserviceaccount.com" {
+		return local
+	}
+	return ""
+}
+
 // extractServiceAccountEmail extracts the email from a service_account_id resource path
 // Example: "projects/my-project/serviceAccounts/sa-b@my-project.iam.gserviceaccount.com" → "sa-b@my-project.iam.gserviceaccount.com"
 func extractServiceAccountEmail(resourceID string) string {
@@ -134,19 +409,29 @@ func AnalyzeTransitiveAccess(accountEmail string, directAccess map[string]*Princ
 	if principal == "" {
 		return nil
 	}
+	return AnalyzePrincipalTransitiveAccess(principal, directAccess, graph)
+}
+
+// AnalyzePrincipalTransitiveAccess calculates transitive access for a full principal
+// (e.g. a principalSet:// member), which must appear in directAccess
+func AnalyzePrincipalTransitiveAccess(principal string, directAccess map[string]*PrincipalData, graph *ImpersonationGraph) *TransitiveAccess {
+	if _, ok := directAccess[principal]; !ok {
+		return nil
+	}
 
 	result := &TransitiveAccess{
-		Principal:        principal,
-		DirectAccess:     directAccess[principal],
-		TransitiveAccess: make(map[string]*AccessVia),
+		Principal:          principal,
+		DirectAccess:       directAccess[principal],
+		TransitiveAccess:   make(map[string]*AccessVia),
+		HierarchicalAccess: make(map[string]*HierarchicalVia),
 	}
 
-	queue := []struct {
+	type hop struct {
 		principal string
 		chain     []string
-	}{
-		{principal: principal, chain: []string{}},
+		edges     []ImpersonationEdge
 	}
+	queue := []hop{{principal: principal, chain: []string{}}}
 	visited := make(map[string]bool)
 
 	for len(queue) > 0 {
@@ -157,24 +442,40 @@ func AnalyzeTransitiveAccess(accountEmail string, directAccess map[string]*Princ
 			continue
 		}
 		visited[current.principal] = true
-
-		targets, exists := graph.Graph[current.principal]
-		if !exists {
-			continue
+		result.Keys = append(result.Keys, graph.KeysFor(current.principal)...)
+
+		// The principal's own grants first, then those of the groups it belongs to
+		sources := []string{current.principal}
+		groupPaths := map[string][]string{}
+		for _, path := range graph.Groups.GroupPaths(current.principal) {
+			group := path[len(path)-1]
+			sources = append(sources, group)
+			groupPaths[group] = path
 		}
 
-		for _, target := range targets {
-			if isCircularReference(current.chain, target) {
-				continue
+		reached := make(map[string]bool)
+		for _, source := range sources {
+			for _, target := range graph.Targets(source) {
+				if reached[target] || isCircularReference(current.chain, target) || target == current.principal {
+					continue
+				}
+				edge, denied, ok := graph.allowedEdge(current.principal, source, target)
+				if denied != nil {
+					result.Denied = append(result.Denied, *denied)
+				}
+				if !ok {
+					continue
+				}
+				reached[target] = true
+
+				edge.ViaGroups = groupPaths[source]
+				newChain := append(append([]string{}, current.chain...), target)
+				newEdges := append(append([]ImpersonationEdge{}, current.edges...), edge)
+				mergeTransitiveAccess(result, target, directAccess, newChain, newEdges)
+				mergeTransitiveHierarchy(result, graph, target, directAccess, newChain, newEdges)
+
+				queue = append(queue, hop{principal: target, chain: newChain, edges: newEdges})
 			}
-
-			newChain := append(append([]string{}, current.chain...), target)
-			mergeTransitiveAccess(result, target, directAccess, newChain)
-
-			queue = append(queue, struct {
-				principal string
-				chain     []string
-			}{principal: target, chain: newChain})
 		}
 	}
 
@@ -199,7 +500,7 @@ func isCircularReference(chain []string, target string) bool {
 	return false
 }
 
-func mergeTransitiveAccess(result *TransitiveAccess, target string, directAccess map[string]*PrincipalData, chain []string) {
+func mergeTransitiveAccess(result *TransitiveAccess, target string, directAccess map[string]*PrincipalData, chain []string, edges []ImpersonationEdge) {
 	targetAccess, ok := directAccess[target]
 	if !ok {
 		return
@@ -219,7 +520,9 @@ func mergeTransitiveAccess(result *TransitiveAccess, target string, directAccess
 					Type:  resMeta.Type,
 					Roles: newRoles,
 				},
-				ViaChain: chain,
+				ViaChain:  chain,
+				ViaEdges:  edges,
+				ChainType: classifyChain(edges),
 			}
 		}
 	}
@@ -242,3 +545,19 @@ func MatchesPrincipalEmail(principal, email string) bool {
 	}
 	return parts[1] == email || strings.HasSuffix(parts[1], email)
 }
+
+// Parents returns the scope parents the graph was built with, or nil when it was not built from bindings
+func (g *ImpersonationGraph) Parents() map[string]string {
+	if g == nil {
+		return nil
+	}
+	return g.parents
+}
+
+// parentMap returns the scope parents the graph was built with, falling back to those recorded by bindings
+func (g *ImpersonationGraph) parentMap(bindings []parser.IAMBinding) map[string]string {
+	if parents := g.Parents(); parents != nil {
+		return parents
+	}
+	return buildParentMap(bindings)
+}
@@ -0,0 +1,283 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestBuildImpersonationGraph_InheritedEdges(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	bindings := []parser.IAMBinding{
+		{
+			ResourceID: "app-prod", ResourceType: "google_project_iam_member", ResourceLevel: "project",
+			Role: "roles/iam.serviceAccountTokenCreator", Members: []string{"user:alice@example.com"},
+			ParentID: "folders/123", ParentType: "folder",
+		},
+		{
+			ResourceID: "123", ResourceType: "google_folder_iam_member", ResourceLevel: "folder",
+			Role: "roles/editor", Members: []string{"group:ops@example.com"},
+		},
+		{
+			ResourceID: "other", ResourceType: "google_project_iam_member", ResourceLevel: "project",
+			Role: "roles/viewer", Members: []string{"serviceAccount:reporter@other.iam.gserviceaccount.com"},
+		},
+		{
+			ResourceID:   "projects/app-prod/serviceAccounts/deployer@app-prod.iam.gserviceaccount.com",
+			ResourceType: "google_service_account_iam_member", ResourceLevel: "resource",
+			Role: "roles/iam.serviceAccountUser", Members: []string{"user:alice@example.com"},
+		},
+	}
+
+	graph := BuildImpersonationGraph(bindings)
+
+	deployer := "serviceAccount:deployer@app-prod.iam.gserviceaccount.com"
+	want := map[string][]ImpersonationEdge{
+		"user:alice@example.com": {
+			{
+				Source: "user:alice@example.com", Target: deployer,
+				Role: "roles/iam.serviceAccountUser", Capability: definitions.CapabilityActAs,
+				Scope: "resource:projects/app-prod/serviceAccounts/deployer@app-prod.iam.gserviceaccount.com",
+			},
+			{
+				Source: "user:alice@example.com", Target: deployer,
+				Role: "roles/iam.serviceAccountTokenCreator", Capability: definitions.CapabilityTokenCreation,
+				Scope: "project:app-prod", Inherited: true,
+			},
+		},
+		"group:ops@example.com": {
+			{
+				Source: "group:ops@example.com", Target: deployer,
+				Role: "roles/editor", Capability: definitions.CapabilityActAs,
+				Scope: "folder:123", Inherited: true, DeployRole: "roles/editor",
+			},
+		},
+	}
+	if !reflect.DeepEqual(graph.Graph, want) {
+		t.Errorf("Graph = %+v, want %+v", graph.Graph, want)
+	}
+
+	// alice cannot deploy compute in app-prod, so the token creator grant explains the hop
+	if edge, _ := graph.Edge("user:alice@example.com", deployer); edge.Role != "roles/iam.serviceAccountTokenCreator" {
+		t.Errorf("Edge(alice, deployer) = %+v, want the token creator grant", edge)
+	}
+	if targets := graph.Targets("user:alice@example.com"); !reflect.DeepEqual(targets, []string{deployer}) {
+		t.Errorf("Targets(alice) = %v, want [%s]", targets, deployer)
+	}
+}
+
+func TestBuildImpersonationGraphWithParents_TreeParents(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	// No binding records the folder of app-prod or the organization of folder 222: both links come only
+	// from the google_project and google_folder declarations
+	bindings := []parser.IAMBinding{
+		{
+			ResourceID: "folders/222", ResourceType: "google_folder_iam_member", ResourceLevel: "folder",
+			Role: "roles/iam.serviceAccountTokenCreator", Members: []string{"user:alice@example.com"},
+		},
+		{
+			ResourceID: "111", ResourceType: "google_organization_iam_member", ResourceLevel: "organization",
+			Role: "roles/iam.serviceAccountUser", Members: []string{"user:bob@example.com"},
+		},
+		{
+			ResourceID: "app-prod", ResourceType: "google_project_iam_member", ResourceLevel: "project",
+			Role: "roles/owner", Members: []string{"serviceAccount:deployer@app-prod.iam.gserviceaccount.com"},
+		},
+	}
+	tree := NewResourceTree()
+	for _, node := range FindHierarchyNodes([]parser.ResourceInstance{
+		{Type: ProjectType, Values: map[string]string{"project_id": "app-prod", "folder_id": "folders/222"}},
+		{Type: FolderType, Values: map[string]string{"name": "folders/222", "parent": "organizations/111"}},
+	}) {
+		tree.AddNode(node)
+	}
+
+	deployer := "serviceAccount:deployer@app-prod.iam.gserviceaccount.com"
+	if graph := BuildImpersonationGraph(bindings); len(graph.Graph) != 0 {
+		t.Fatalf("Graph = %+v, want no edges without the tree", graph.Graph)
+	}
+
+	graph := BuildImpersonationGraphWithParents(bindings, CanImpersonate, ParentMap(bindings, tree))
+	tests := []struct {
+		source string
+		role   string
+		scope  string
+	}{
+		{"user:alice@example.com", "roles/iam.serviceAccountTokenCreator", "folder:222"},
+		{"user:bob@example.com", "roles/iam.serviceAccountUser", "organization:111"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			edge, ok := graph.Edge(tt.source, deployer)
+			if !ok || edge.Role != tt.role || edge.Scope != tt.scope || !edge.Inherited {
+				t.Errorf("Edge(%s, deployer) = %+v, %v, want %s inherited from %s", tt.source, edge, ok, tt.role, tt.scope)
+			}
+		})
+	}
+}
+
+func TestAddServiceAccounts(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	bindings := []parser.IAMBinding{
+		{
+			ResourceID: "app-prod", ResourceType: "google_project_iam_member", ResourceLevel: "project",
+			Role: "roles/iam.serviceAccountTokenCreator", Members: []string{"user:alice@example.com"},
+		},
+	}
+	graph := BuildImpersonationGraph(bindings)
+	if len(graph.Graph) != 0 {
+		t.Fatalf("Graph = %+v, want no edges before service accounts are declared", graph.Graph)
+	}
+
+	resources := []parser.ResourceInstance{
+		{Type: ServiceAccountType, Address: "google_service_account.batch", Project: "app-prod", Values: map[string]string{"account_id": "batch"}},
+		{Type: ServiceAccountType, Address: "google_service_account.other", Values: map[string]string{"account_id": "other", "project": "other"}},
+		{Type: ServiceAccountKeyType, Address: "google_service_account_key.batch"},
+	}
+	graph.AddServiceAccounts(resources, bindings, definitions.GetCanImpersonateFunc())
+
+	batch := "serviceAccount:batch@app-prod.iam.gserviceaccount.com"
+	want := map[string][]ImpersonationEdge{
+		"user:alice@example.com": {
+			{
+				Source: "user:alice@example.com", Target: batch,
+				Role: "roles/iam.serviceAccountTokenCreator", Capability: definitions.CapabilityTokenCreation,
+				Scope: "project:app-prod", Inherited: true,
+			},
+		},
+	}
+	if !reflect.DeepEqual(graph.Graph, want) {
+		t.Errorf("Graph = %+v, want %+v", graph.Graph, want)
+	}
+}
+
+func TestServiceAccountProject(t *testing.T) {
+	tests := map[string]string{
+		"sa@my-project.iam.gserviceaccount.com":     "my-project",
+		"my-project@appspot.gserviceaccount.com":    "my-project",
+		"123-compute@developer.gserviceaccount.com": "",
+		"not-an-email": "",
+	}
+	for email, want := range tests {
+		if got := serviceAccountProject(email); got != want {
+			t.Errorf("serviceAccountProject(%q) = %q, want %q", email, got, want)
+		}
+	}
+}
+
+func TestAnalyzeTransitiveAccess_ChainType(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	sa := func(name string) string {
+		return "projects/app/serviceAccounts/" + name + "@app.iam.gserviceaccount.com"
+	}
+	bindings := []parser.IAMBinding{
+		{ResourceID: sa("runner"), ResourceLevel: "resource", Role: "roles/iam.serviceAccountUser", Members: []string{"user:dev@example.com", "user:intern@example.com"}},
+		{ResourceID: sa("minter"), ResourceLevel: "resource", Role: "roles/iam.serviceAccountTokenCreator", Members: []string{"user:dev@example.com"}},
+		{ResourceID: "app", ResourceLevel: "project", Role: "roles/run.developer", Members: []string{"user:dev@example.com"}},
+		// ops deploys through its group; lead holds actAs through its group and deploys on its own
+		{ResourceID: sa("runner"), ResourceLevel: "resource", Role: "roles/iam.serviceAccountUser", Members: []string{"user:ops@example.com", "group:leads@example.com"}},
+		{ResourceID: "app", ResourceLevel: "project", Role: "roles/run.developer", Members: []string{"group:deployers@example.com", "user:lead@example.com"}},
+		{ResourceID: "runner-data", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource", Role: "roles/storage.admin", Members: []string{"serviceAccount:runner@app.iam.gserviceaccount.com"}},
+		{ResourceID: "minter-data", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource", Role: "roles/storage.admin", Members: []string{"serviceAccount:minter@app.iam.gserviceaccount.com"}},
+	}
+
+	directAccess := Analyze(bindings)
+	graph := BuildImpersonationGraph(bindings)
+	graph.AddGroups(NewGroupMemberships(map[string][]string{
+		"deployers@example.com": {"ops@example.com"},
+		"leads@example.com":     {"lead@example.com"},
+	}))
+
+	tests := []struct {
+		account  string
+		resource string
+		want     string
+	}{
+		{"dev@example.com", "minter-data", ChainDirectImpersonation},
+		{"dev@example.com", "runner-data", ChainActAsViaCompute},
+		{"intern@example.com", "runner-data", ChainActAsUnconfirmed},
+		{"ops@example.com", "runner-data", ChainActAsViaCompute},
+		{"lead@example.com", "runner-data", ChainActAsViaCompute},
+	}
+	for _, tt := range tests {
+		result := AnalyzeTransitiveAccess(tt.account, directAccess, graph)
+		if result == nil || result.TransitiveAccess[tt.resource] == nil {
+			t.Errorf("%s: no transitive access to %s", tt.account, tt.resource)
+			continue
+		}
+		if got := result.TransitiveAccess[tt.resource].ChainType; got != tt.want {
+			t.Errorf("%s → %s: ChainType = %q, want %q", tt.account, tt.resource, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeTransitiveAccess_HierarchicalAccess(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	sa := "serviceAccount:deployer@app.iam.gserviceaccount.com"
+	bindings := []parser.IAMBinding{
+		{
+			ResourceID: "projects/app/serviceAccounts/deployer@app.iam.gserviceaccount.com", ResourceType: "google_service_account_iam_member",
+			ResourceLevel: "resource", Role: "roles/iam.serviceAccountTokenCreator", Members: []string{"user:alice@example.com"},
+		},
+		{
+			ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", ParentType: "folder", ParentID: "folders/123",
+			Role: "roles/viewer", Members: []string{"user:alice@example.com"},
+		},
+		{
+			ResourceID: "123", ResourceType: "google_folder_iam_member", ResourceLevel: "folder",
+			Role: "roles/editor", Members: []string{sa}, TerraformAddr: "google_folder_iam_member.deployer",
+		},
+		{
+			ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", ParentType: "folder", ParentID: "folders/123",
+			Role: "roles/viewer", Members: []string{sa},
+		},
+	}
+
+	graph := BuildImpersonationGraph(bindings)
+	graph.AddHierarchy(AnalyzeHierarchy(bindings))
+	result := AnalyzeTransitiveAccess("alice@example.com", Analyze(bindings), graph)
+
+	folder, ok := result.HierarchicalAccess["folder:123"]
+	if !ok {
+		t.Fatalf("expected the deployer's folder grant to reach alice, got %v", result.HierarchicalAccess)
+	}
+	if !reflect.DeepEqual(folder.Roles(), []string{"roles/editor"}) || !folder.ResourceTypes["*"] {
+		t.Errorf("folder access = roles %v, types %v; want roles/editor on all resources", folder.Roles(), folder.ResourceTypes)
+	}
+	if !reflect.DeepEqual(folder.ViaChain, []string{sa}) || folder.ChainType != ChainDirectImpersonation {
+		t.Errorf("folder access via %v (%s), want the deployer directly", folder.ViaChain, folder.ChainType)
+	}
+
+	project, ok := result.HierarchicalAccess["project:app"]
+	if !ok {
+		t.Fatalf("expected the folder grant inherited by project app to reach alice")
+	}
+	if !reflect.DeepEqual(project.Roles(), []string{"roles/editor"}) {
+		t.Errorf("project access roles = %v, want only the inherited roles/editor (alice already holds roles/viewer)", project.Roles())
+	}
+	if inherited := project.Entries[0].InheritedFrom; inherited == nil || *inherited != (Scope{Type: "folder", ID: "123"}) {
+		t.Errorf("project entry inherited from %v, want folder 123", inherited)
+	}
+
+	sorted := result.SortedHierarchicalAccess()
+	if len(sorted) != 2 || sorted[0].Scope.Type != "folder" || sorted[1].Scope.Type != "project" {
+		t.Errorf("SortedHierarchicalAccess() should list the folder before the project, got %+v", sorted)
+	}
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// InheritedGrant is a project, folder or organization binding that also covers a resource
+// with resource-level bindings, because the resource sits below that scope
+type InheritedGrant struct {
+	Binding       parser.IAMBinding `json:"binding"`
+	ResourceID    string            `json:"resource_id"`
+	ResourceType  string            `json:"resource_type"` // IAM resource type of the resource's own bindings
+	InheritedFrom Scope             `json:"inherited_from"`
+}
+
+// BaseResourceType returns the resource type an IAM resource type grants on
+// (e.g. "google_storage_bucket_iam_member" → "google_storage_bucket"), or the type unchanged
+func BaseResourceType(iamType string) string {
+	base, _, _ := strings.Cut(iamType, "_iam_")
+	return base
+}
+
+// InheritedGrants returns, for every resource whose bindings record an owning project, the bindings on
+// that project and its known ancestors whose role reaches the resource's type. Roles without hierarchy
+// info are not assumed to reach any resource. Results are sorted by resource ID, nearest scope first.
+func InheritedGrants(bindings []parser.IAMBinding) []InheritedGrant {
+	return InheritedGrantsWithParents(bindings, nil)
+}
+
+// InheritedGrantsWithParents is InheritedGrants with the scope parents, as returned by ParentMap.
+// Nil parents are read from the bindings.
+func InheritedGrantsWithParents(bindings []parser.IAMBinding, parents map[string]string) []InheritedGrant {
+	if parents == nil {
+		parents = buildParentMap(bindings)
+	}
+
+	resourceTypes := make(map[string]string)
+	byScope := make(map[string][]parser.IAMBinding)
+	for _, b := range bindings {
+		switch {
+		case b.ResourceLevel == "resource" && b.ParentID != "":
+			if _, seen := resourceTypes[b.ResourceID]; !seen {
+				resourceTypes[b.ResourceID] = b.ResourceType
+			}
+		case isHierarchyLevel(b.ResourceLevel) && b.Role != "":
+			key := scopeKey(b.ResourceLevel, b.ResourceID)
+			byScope[key] = append(byScope[key], b)
+		}
+	}
+
+	ids := make([]string, 0, len(resourceTypes))
+	for id := range resourceTypes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var grants []InheritedGrant
+	for _, id := range ids {
+		resourceType := resourceTypes[id]
+		base := BaseResourceType(resourceType)
+		scopes := ancestorScopes(scopeKey("resource", id), parents)
+		for _, scope := range scopes[1:] {
+			for _, b := range byScope[scope] {
+				hierarchy := definitions.GetRoleHierarchy(b.Role)
+				if hierarchy == nil || !(containsString(hierarchy.ResourceTypes, base) || containsString(hierarchy.ResourceTypes, "*")) {
+					continue
+				}
+				grants = append(grants, InheritedGrant{
+					Binding:       b,
+					ResourceID:    id,
+					ResourceType:  resourceType,
+					InheritedFrom: Scope{Type: b.ResourceLevel, ID: b.ResourceID},
+				})
+			}
+		}
+	}
+	return grants
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestInheritedGrants(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	bindings := []parser.IAMBinding{
+		{ResourceID: "111", ResourceType: "google_organization_iam_member", ResourceLevel: "organization", Role: "roles/storage.admin", Members: []string{"group:ops@example.com"}},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", ParentType: "organization", ParentID: "111", Role: "roles/storage.objectViewer", Members: []string{"user:bob@example.com"}},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", ParentType: "organization", ParentID: "111", Role: "roles/bigquery.dataViewer", Members: []string{"user:carol@example.com"}},
+		{ResourceID: "data", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource", ParentType: "project", ParentID: "app", Role: "roles/storage.objectViewer", Members: []string{"user:alice@example.com"}},
+		{ResourceID: "loose", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource", ParentType: "project", Role: "roles/storage.objectViewer", Members: []string{"user:alice@example.com"}},
+	}
+
+	grants := InheritedGrants(bindings)
+	if len(grants) != 2 {
+		t.Fatalf("InheritedGrants() returned %d grants, want 2: %+v", len(grants), grants)
+	}
+	if g := grants[0]; g.ResourceID != "data" || g.Binding.Role != "roles/storage.objectViewer" || g.InheritedFrom != (Scope{Type: "project", ID: "app"}) {
+		t.Errorf("first grant = %+v, want bob's objectViewer from project app", g)
+	}
+	if g := grants[1]; g.ResourceID != "data" || g.Binding.Role != "roles/storage.admin" || g.InheritedFrom != (Scope{Type: "organization", ID: "111"}) {
+		t.Errorf("second grant = %+v, want ops' storage.admin from organization 111", g)
+	}
+	if grants[0].ResourceType != "google_storage_bucket_iam_member" {
+		t.Errorf("ResourceType = %q, want the bucket IAM type", grants[0].ResourceType)
+	}
+
+	// The bucket grant is covered by the organization's storage.admin once the bucket is placed in its project
+	redundant := FindRedundantGrants(append(bindings, parser.IAMBinding{
+		ResourceID: "data", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource", ParentType: "project", ParentID: "app",
+		Role: "roles/storage.objectViewer", Members: []string{"group:ops@example.com"},
+	}))
+	found := false
+	for _, r := range redundant {
+		if r.Principal == "group:ops@example.com" && r.Grant.ScopeID == "data" && r.CoveredBy.ScopeID == "111" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the bucket grant to ops to be covered by the organization grant, got %+v", redundant)
+	}
+}
+
+func TestBaseResourceType(t *testing.T) {
+	if got := BaseResourceType("google_storage_bucket_iam_member"); got != "google_storage_bucket" {
+		t.Errorf("BaseResourceType() = %q, want google_storage_bucket", got)
+	}
+	if got := BaseResourceType("google_storage_bucket"); got != "google_storage_bucket" {
+		t.Errorf("BaseResourceType() = %q, want the type unchanged", got)
+	}
+}
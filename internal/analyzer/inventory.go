@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// InventoryResource is a non-IAM resource declared in Terraform that grants on its project,
+// folder or organization reach
+type InventoryResource struct {
+	Type    string `json:"type"`              // Terraform resource type (e.g. "google_storage_bucket")
+	ID      string `json:"id"`                // Value of the definition's resource_id attribute, or the address when it is unknown
+	Project string `json:"project,omitempty"` // Project the resource is created in, if known
+	Address string `json:"address"`           // Terraform address of the resource
+}
+
+// InventoryResourceTypes returns the resource types FindInventory needs parsed for the given definitions
+func InventoryResourceTypes(defs []definitions.InventoryDefinition) []string {
+	types := make([]string, 0, len(defs))
+	for _, def := range defs {
+		types = append(types, def.Type)
+	}
+	return types
+}
+
+// FindInventory returns the resources of the defined inventory types, sorted by project, type and ID.
+// A resource without a project attribute is placed in the provider's default project; one whose
+// project attribute references a resource that cannot be resolved is left without a project.
+func FindInventory(resources []parser.ResourceInstance, defs []definitions.InventoryDefinition) []InventoryResource {
+	byType := make(map[string]definitions.InventoryDefinition, len(defs))
+	for _, def := range defs {
+		byType[def.Type] = def
+	}
+
+	var inventory []InventoryResource
+	for _, r := range resources {
+		def, ok := byType[r.Type]
+		if !ok {
+			continue
+		}
+		id := r.Values[def.ResourceID]
+		if id == "" {
+			id = r.Address
+		}
+		project := projectFromName(r.Values[def.Project])
+		if project == "" && r.References[def.Project] == "" {
+			project = r.Project
+		}
+		inventory = append(inventory, InventoryResource{Type: r.Type, ID: id, Project: project, Address: r.Address})
+	}
+
+	sort.Slice(inventory, func(i, j int) bool {
+		a, b := inventory[i], inventory[j]
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.ID < b.ID
+	})
+	return inventory
+}
+
+// projectFromName returns the project of a project ID or of a resource name under it
+// (e.g. "projects/app/locations/eu/keyRings/main" → "app")
+func projectFromName(name string) string {
+	rest, ok := strings.CutPrefix(name, "projects/")
+	if !ok {
+		return name
+	}
+	project, _, _ := strings.Cut(rest, "/")
+	return project
+}
+
+// InventoryIn returns the resources of a project whose type is one of types; "*" matches every type
+func InventoryIn(inventory []InventoryResource, project string, types []string) []InventoryResource {
+	var matches []InventoryResource
+	for _, r := range inventory {
+		if r.Project == project && (containsString(types, "*") || containsString(types, r.Type)) {
+			matches = append(matches, r)
+		}
+	}
+	return matches
+}
+
+// ExpandHierarchicalAccess lists, on every project-level entry, the inventory resources the grant reaches.
+// Organization and folder grants reach resources through the entries inherited by their projects.
+func ExpandHierarchicalAccess(result *HierarchyAnalysisResult, inventory []InventoryResource) {
+	for i := range result.HierarchicalAccess {
+		entry := &result.HierarchicalAccess[i]
+		if entry.Scope.Type == "project" {
+			entry.Resources = InventoryIn(inventory, entry.Scope.ID, entry.Grants.ResourceTypes)
+		}
+	}
+}
+
+// MergeInheritedAccess adds the inventory resources listed by ExpandHierarchicalAccess to the resource
+// access of each principal, so per-resource reports show inherited access next to direct access.
+// Roles the principal also holds directly on a resource are left as direct grants.
+func MergeInheritedAccess(results map[string]*PrincipalData, result *HierarchyAnalysisResult) {
+	for _, entry := range result.HierarchicalAccess {
+		if len(entry.Resources) == 0 {
+			continue
+		}
+		data, ok := results[entry.Principal]
+		if !ok {
+			data = &PrincipalData{
+				ResourceAccess:     make(map[string]*ResourceMetadata),
+				HierarchicalAccess: make(map[string]map[string]bool),
+			}
+			results[entry.Principal] = data
+		}
+
+		grantedOn := entry.Scope
+		if entry.InheritedFrom != nil {
+			grantedOn = *entry.InheritedFrom
+		}
+		for _, r := range entry.Resources {
+			meta, exists := data.ResourceAccess[r.ID]
+			if !exists {
+				meta = &ResourceMetadata{
+					Type:           r.Type,
+					Roles:          make(map[string]bool),
+					TerraformAddrs: make(map[string]string),
+				}
+				data.ResourceAccess[r.ID] = meta
+			}
+			if meta.Roles[entry.Role] {
+				continue
+			}
+			meta.Roles[entry.Role] = true
+			meta.TerraformAddrs[entry.Role] = entry.Source.ResourceAddress
+			if meta.InheritedFrom == nil {
+				meta.InheritedFrom = make(map[string]Scope)
+			}
+			meta.InheritedFrom[entry.Role] = grantedOn
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestFindInventory(t *testing.T) {
+	defs := []definitions.InventoryDefinition{
+		{Type: "google_storage_bucket", ResourceID: "name", Project: "project"},
+		{Type: "google_kms_crypto_key", ResourceID: "name", Project: "key_ring"},
+	}
+	resources := []parser.ResourceInstance{
+		{Type: "google_storage_bucket", Address: "google_storage_bucket.logs", Project: "ops", Values: map[string]string{"name": "logs"}},
+		{Type: "google_storage_bucket", Address: "google_storage_bucket.data", Values: map[string]string{"name": "data", "project": "app"}},
+		{Type: "google_kms_crypto_key", Address: "google_kms_crypto_key.main", Values: map[string]string{"name": "main", "key_ring": "projects/app/locations/eu/keyRings/ring"}},
+		{
+			Type: "google_kms_crypto_key", Address: "google_kms_crypto_key.other", Project: "ops",
+			Values: map[string]string{"name": "other"}, References: map[string]string{"key_ring": "google_kms_key_ring.other"},
+		},
+		{Type: "google_storage_bucket", Address: "google_storage_bucket.per_env", Values: map[string]string{"project": "app"}},
+		{Type: "google_pubsub_topic", Address: "google_pubsub_topic.events", Values: map[string]string{"name": "events"}},
+	}
+
+	want := []InventoryResource{
+		{Type: "google_kms_crypto_key", ID: "other", Address: "google_kms_crypto_key.other"},
+		{Type: "google_kms_crypto_key", ID: "main", Project: "app", Address: "google_kms_crypto_key.main"},
+		{Type: "google_storage_bucket", ID: "data", Project: "app", Address: "google_storage_bucket.data"},
+		{Type: "google_storage_bucket", ID: "google_storage_bucket.per_env", Project: "app", Address: "google_storage_bucket.per_env"},
+		{Type: "google_storage_bucket", ID: "logs", Project: "ops", Address: "google_storage_bucket.logs"},
+	}
+	if got := FindInventory(resources, defs); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindInventory() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFindInventory_Plan(t *testing.T) {
+	// The bucket's project is created by the same plan: its project is unknown, not the provider's
+	defs := []definitions.InventoryDefinition{{Type: "google_storage_bucket", ResourceID: "name", Project: "project"}}
+	plan := parser.TerraformPlan{
+		PlannedValues: parser.PlannedValues{RootModule: parser.Module{Resources: []parser.Resource{
+			{Address: "google_storage_bucket.data", Mode: "managed", Type: "google_storage_bucket", Values: map[string]interface{}{"name": "data"}},
+			{Address: "google_storage_bucket.logs", Mode: "managed", Type: "google_storage_bucket", Values: map[string]interface{}{"name": "logs"}},
+		}}},
+		Configuration: parser.PlanConfiguration{
+			ProviderConfig: map[string]parser.PlanProviderConfig{
+				"google": {Name: "google", Expressions: map[string]parser.PlanExpression{"project": {ConstantValue: "ops"}}},
+			},
+			RootModule: parser.PlanConfigModule{Resources: []parser.PlanConfigResource{
+				{
+					Address: "google_storage_bucket.data", Mode: "managed",
+					Expressions: map[string]interface{}{"project": map[string]interface{}{"references": []interface{}{"google_project.app.project_id", "google_project.app"}}},
+				},
+			}},
+		},
+	}
+	resources, err := parser.ParsePlanResources(writePlan(t, plan), InventoryResourceTypes(defs))
+	if err != nil {
+		t.Fatalf("ParsePlanResources() error = %v", err)
+	}
+
+	want := []InventoryResource{
+		{Type: "google_storage_bucket", ID: "data", Address: "google_storage_bucket.data"},
+		{Type: "google_storage_bucket", ID: "logs", Project: "ops", Address: "google_storage_bucket.logs"},
+	}
+	if got := FindInventory(resources, defs); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindInventory() = %+v, want %+v", got, want)
+	}
+}
+
+func TestExpandAndMergeInheritedAccess(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	bindings := []parser.IAMBinding{
+		{ResourceID: "111", ResourceType: "google_organization_iam_member", ResourceLevel: "organization", Role: "roles/storage.objectViewer", Members: []string{"user:alice@example.com"}, TerraformAddr: "google_organization_iam_member.alice"},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/bigquery.dataViewer", Members: []string{"user:bob@example.com"}, TerraformAddr: "google_project_iam_member.bob"},
+		{ResourceID: "data", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource", Role: "roles/storage.objectViewer", Members: []string{"user:alice@example.com"}},
+	}
+	tree := NewResourceTree()
+	tree.AddNode(HierarchyNode{ID: "app", Type: "project", ParentID: "111", ParentType: "organization"})
+	inventory := []InventoryResource{
+		{Type: "google_bigquery_dataset", ID: "events", Project: "app", Address: "google_bigquery_dataset.events"},
+		{Type: "google_storage_bucket", ID: "data", Project: "app", Address: "google_storage_bucket.data"},
+		{Type: "google_storage_bucket", ID: "logs", Project: "app", Address: "google_storage_bucket.logs"},
+		{Type: "google_storage_bucket", ID: "elsewhere", Project: "ops", Address: "google_storage_bucket.elsewhere"},
+	}
+
+	result := AnalyzeHierarchyWithTree(bindings, tree)
+	ExpandHierarchicalAccess(result, inventory)
+
+	for _, entry := range result.HierarchicalAccess {
+		var ids []string
+		for _, r := range entry.Resources {
+			ids = append(ids, r.ID)
+		}
+		switch {
+		case entry.Scope.Type == "organization":
+			if len(ids) != 0 {
+				t.Errorf("organization entry should not list resources, got %v", ids)
+			}
+		case entry.Principal == "user:alice@example.com":
+			if !reflect.DeepEqual(ids, []string{"data", "logs"}) {
+				t.Errorf("alice's inherited entry on app lists %v, want [data logs]", ids)
+			}
+		case entry.Principal == "user:bob@example.com":
+			if !reflect.DeepEqual(ids, []string{"events"}) {
+				t.Errorf("bob's entry on app lists %v, want [events]", ids)
+			}
+		}
+	}
+
+	results := Analyze(bindings)
+	MergeInheritedAccess(results, result)
+
+	alice := results["user:alice@example.com"].ResourceAccess
+	if alice["data"].InheritedFrom != nil {
+		t.Errorf("alice's direct grant on data should stay direct, got inherited from %v", alice["data"].InheritedFrom)
+	}
+	logs, ok := alice["logs"]
+	if !ok || !logs.Roles["roles/storage.objectViewer"] {
+		t.Fatalf("alice should reach logs through the organization grant, got %+v", alice)
+	}
+	if got := logs.InheritedFrom["roles/storage.objectViewer"]; got != (Scope{Type: "organization", ID: "111"}) {
+		t.Errorf("logs inherited from %v, want organization 111", got)
+	}
+	if logs.TerraformAddrs["roles/storage.objectViewer"] != "google_organization_iam_member.alice" {
+		t.Errorf("logs terraform address = %q, want the organization binding", logs.TerraformAddrs["roles/storage.objectViewer"])
+	}
+	if _, ok := alice["elsewhere"]; ok {
+		t.Errorf("alice should not reach buckets in projects outside the tree")
+	}
+	if got := results["user:bob@example.com"].ResourceAccess["events"].InheritedFrom["roles/bigquery.dataViewer"]; got != (Scope{Type: "project", ID: "app"}) {
+		t.Errorf("events inherited from %v, want project app", got)
+	}
+}
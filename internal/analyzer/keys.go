@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// Terraform resource types that hold service account credentials
+const (
+	ServiceAccountKeyType = "google_service_account_key"
+	ServiceAccountType    = "google_service_account"
+)
+
+// KeyResourceTypes are the resource types FindServiceAccountKeys needs parsed
+var KeyResourceTypes = []string{ServiceAccountKeyType, ServiceAccountType}
+
+// ServiceAccountKey is a long-lived key declared in Terraform.
+// Its private key ends up in the Terraform state, so anyone who can read the state or
+// its outputs holds the service account's access.
+type ServiceAccountKey struct {
+	ServiceAccount string `json:"service_account"` // "serviceAccount:email", or "" if it could not be resolved
+	Address        string `json:"address"`         // Terraform address of the key resource
+}
+
+// FindServiceAccountKeys returns the key resources among resources, resolving each key's service account.
+// service_account_id may be an email, a "projects/p/serviceAccounts/email" path or a reference to a
+// google_service_account, whose email is derived from its account_id and project.
+func FindServiceAccountKeys(resources []parser.ResourceInstance) []ServiceAccountKey {
+	accounts := make(map[string]parser.ResourceInstance)
+	for _, r := range resources {
+		if r.Type == ServiceAccountType {
+			accounts[r.Address] = r
+		}
+	}
+
+	var keys []ServiceAccountKey
+	for _, r := range resources {
+		if r.Type != ServiceAccountKeyType {
+			continue
+		}
+		key := ServiceAccountKey{Address: r.Address}
+		if email := extractServiceAccountEmail(r.Values["service_account_id"]); email != "" {
+			key.ServiceAccount = "serviceAccount:" + email
+		} else if account, ok := accounts[r.References["service_account_id"]]; ok {
+			if email := serviceAccountEmail(account); email != "" {
+				key.ServiceAccount = "serviceAccount:" + email
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// serviceAccountEmail derives the email of a google_service_account resource
+func serviceAccountEmail(account parser.ResourceInstance) string {
+	if email := account.Values["email"]; email != "" {
+		return email
+	}
+	accountID := account.Values["account_id"]
+	project := account.Values["project"]
+	if project == "" {
+		project = account.Project
+	}
+	if accountID == "" || project == "" {
+		return ""
+	}
+	return accountID + "@" + project + ".iam.gserviceaccount.com"
+}
+
+// AddKeys records key resources on the graph so analyses can flag reachable service accounts with keys
+func (g *ImpersonationGraph) AddKeys(keys []ServiceAccountKey) {
+	g.Keys = append(g.Keys, keys...)
+}
+
+// KeysFor returns the keys of a service account principal
+func (g *ImpersonationGraph) KeysFor(principal string) []ServiceAccountKey {
+	var keys []ServiceAccountKey
+	for _, k := range g.Keys {
+		if k.ServiceAccount != "" && strings.EqualFold(k.ServiceAccount, principal) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
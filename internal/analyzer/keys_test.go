@@ -0,0 +1,44 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestFindServiceAccountKeys(t *testing.T) {
+	resources := []parser.ResourceInstance{
+		{Type: ServiceAccountType, Address: "google_service_account.ci", Project: "app", Values: map[string]string{"account_id": "ci"}},
+		{Type: ServiceAccountKeyType, Address: "google_service_account_key.ci", References: map[string]string{"service_account_id": "google_service_account.ci"}},
+		{Type: ServiceAccountKeyType, Address: "google_service_account_key.legacy", Values: map[string]string{"service_account_id": "projects/app/serviceAccounts/legacy@app.iam.gserviceaccount.com"}},
+		{Type: ServiceAccountKeyType, Address: "google_service_account_key.unknown", References: map[string]string{"service_account_id": "module.x"}},
+	}
+
+	want := []ServiceAccountKey{
+		{ServiceAccount: "serviceAccount:ci@app.iam.gserviceaccount.com", Address: "google_service_account_key.ci"},
+		{ServiceAccount: "serviceAccount:legacy@app.iam.gserviceaccount.com", Address: "google_service_account_key.legacy"},
+		{Address: "google_service_account_key.unknown"},
+	}
+	if got := FindServiceAccountKeys(resources); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindServiceAccountKeys() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFindServiceAccountKeys_Plan(t *testing.T) {
+	// The key's service_account_id is unknown until the account is created; the configuration names the account
+	resources := parsePlanResources(t, KeyResourceTypes, []parser.Resource{
+		{Address: "google_service_account.ci", Mode: "managed", Type: ServiceAccountType, Values: map[string]interface{}{"account_id": "ci", "project": "app"}},
+		{Address: "google_service_account_key.ci", Mode: "managed", Type: ServiceAccountKeyType, Values: map[string]interface{}{}},
+	}, []parser.PlanConfigResource{
+		{
+			Address: "google_service_account_key.ci", Mode: "managed",
+			Expressions: map[string]interface{}{"service_account_id": map[string]interface{}{"references": []interface{}{"google_service_account.ci.name", "google_service_account.ci"}}},
+		},
+	})
+
+	want := []ServiceAccountKey{{ServiceAccount: "serviceAccount:ci@app.iam.gserviceaccount.com", Address: "google_service_account_key.ci"}}
+	if got := FindServiceAccountKeys(resources); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindServiceAccountKeys() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,480 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Path modes, selecting which impersonation paths FindResourcePaths reports
+const (
+	PathModeShortest = "shortest" // the shortest path to each resource
+	PathModeAll      = "all"      // every simple path up to the depth limit
+	PathModeMinCut   = "min-cut"  // every simple path, with the smallest sets of bindings that sever them all
+)
+
+// PathModes lists the valid path modes
+var PathModes = []string{PathModeShortest, PathModeAll, PathModeMinCut}
+
+// DefaultMaxPathDepth is the number of hops paths are followed to when no limit is given
+const DefaultMaxPathDepth = 5
+
+// MaxImpersonationPaths is the most paths EnumerateImpersonationPaths returns; dense graphs have far more
+// simple paths than can be listed, so enumeration stops there and the result is marked truncated
+const MaxImpersonationPaths = 1000
+
+// maxExactCutCandidates and maxCutRoutes bound the exhaustive search for minimal cut sets, by the bindings
+// involved and by the routes they form; beyond either a greedy cut is reported and marked approximate
+const (
+	maxExactCutCandidates = 12
+	maxCutRoutes          = 500
+)
+
+// ImpersonationPath is one chain of impersonation hops from the analyzed principal
+type ImpersonationPath struct {
+	Chain     []string            `json:"chain"`      // Service accounts reached by each hop
+	Edges     []ImpersonationEdge `json:"edges"`      // The edge behind each hop of Chain
+	ChainType string              `json:"chain_type"` // One of the Chain* constants
+
+	hopBindings [][]string // per hop, every binding that allows it; a hop is only severed when all are removed
+}
+
+// ResourcePaths is the impersonation paths through which a principal reaches a resource
+type ResourcePaths struct {
+	ResourceID     string              `json:"resource_id"`
+	ResourceType   string              `json:"resource_type"`
+	Roles          []string            `json:"roles"` // Roles gained over any of the paths
+	Paths          []ImpersonationPath `json:"paths"`
+	MinimalCuts    [][]string          `json:"minimal_cuts,omitempty"`    // For min-cut mode, the smallest sets of bindings whose removal severs every path
+	CutApproximate bool                `json:"cut_approximate,omitempty"` // True when too many bindings are involved for an exhaustive search and a greedy cut is reported
+	Truncated      bool                `json:"truncated,omitempty"`       // True when enumeration stopped at MaxImpersonationPaths, so paths, and the cuts over them, may be incomplete
+}
+
+// pathStep is a hop an account can make: the service account reached, the edge behind it and every binding allowing it
+type pathStep struct {
+	target   string
+	edge     ImpersonationEdge
+	bindings []string
+}
+
+// EnumerateImpersonationPaths returns the simple impersonation paths from a principal of up to maxDepth hops
+// (DefaultMaxPathDepth when maxDepth is not positive), following the grants of the groups each account belongs to
+// and passing over hops deny policies revoke. Paths are ordered by length, then by chain. At most
+// MaxImpersonationPaths are returned, the shortest first; truncated reports whether any were left out.
+func EnumerateImpersonationPaths(principal string, graph *ImpersonationGraph, maxDepth int) ([]ImpersonationPath, bool) {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxPathDepth
+	}
+
+	steps := graph.pathSteps()
+	var paths []ImpersonationPath
+	level := []ImpersonationPath{{}}
+	for depth := 0; depth < maxDepth && len(level) > 0; depth++ {
+		var next []ImpersonationPath
+		for _, path := range level {
+			for _, step := range steps(path.end(principal)) {
+				if step.target == principal || isCircularReference(path.Chain, step.target) {
+					continue
+				}
+				if len(paths) == MaxImpersonationPaths {
+					return paths, true
+				}
+				extended := path.extend(step)
+				paths = append(paths, extended)
+				next = append(next, extended)
+			}
+		}
+		level = next
+	}
+	return paths, false
+}
+
+// ShortestImpersonationPaths returns, for every service account a principal can reach within maxDepth hops
+// (DefaultMaxPathDepth when maxDepth is not positive), the first of its shortest paths by chain, following groups
+// and deny policies like EnumerateImpersonationPaths. Paths are ordered by length, then by chain.
+func ShortestImpersonationPaths(principal string, graph *ImpersonationGraph, maxDepth int) []ImpersonationPath {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxPathDepth
+	}
+
+	steps := graph.pathSteps()
+	visited := map[string]bool{principal: true}
+	var paths []ImpersonationPath
+	level := []ImpersonationPath{{}}
+	for depth := 0; depth < maxDepth && len(level) > 0; depth++ {
+		var next []ImpersonationPath
+		for _, path := range level {
+			for _, step := range steps(path.end(principal)) {
+				if visited[step.target] {
+					continue
+				}
+				visited[step.target] = true
+				extended := path.extend(step)
+				paths = append(paths, extended)
+				next = append(next, extended)
+			}
+		}
+		level = next
+	}
+	return paths
+}
+
+// pathSteps returns a function listing the hops an account can make, directly or through its groups, sorted by
+// target. An account's hops do not depend on the path leading to it, so they are worked out once per account.
+func (g *ImpersonationGraph) pathSteps() func(current string) []pathStep {
+	cache := make(map[string][]pathStep)
+	return func(current string) []pathStep {
+		if steps, ok := cache[current]; ok {
+			return steps
+		}
+		sources := []string{current}
+		groupPaths := map[string][]string{}
+		for _, path := range g.Groups.GroupPaths(current) {
+			group := path[len(path)-1]
+			sources = append(sources, group)
+			groupPaths[group] = path
+		}
+
+		var steps []pathStep
+		reached := make(map[string]bool)
+		for _, source := range sources {
+			for _, target := range g.Targets(source) {
+				if reached[target] {
+					continue
+				}
+				edge, _, ok := g.allowedEdge(current, source, target)
+				if !ok {
+					continue
+				}
+				reached[target] = true
+				edge.ViaGroups = groupPaths[source]
+				steps = append(steps, pathStep{target: target, edge: edge, bindings: g.hopBindings(current, sources, target)})
+			}
+		}
+		sort.Slice(steps, func(i, j int) bool { return steps[i].target < steps[j].target })
+		cache[current] = steps
+		return steps
+	}
+}
+
+// end returns the account at the end of the path, or principal for the empty path it starts from
+func (p ImpersonationPath) end(principal string) string {
+	if len(p.Chain) == 0 {
+		return principal
+	}
+	return p.Chain[len(p.Chain)-1]
+}
+
+// extend returns a copy of the path followed by one more hop
+func (p ImpersonationPath) extend(step pathStep) ImpersonationPath {
+	edges := append(append([]ImpersonationEdge{}, p.Edges...), step.edge)
+	return ImpersonationPath{
+		Chain:       append(append([]string{}, p.Chain...), step.target),
+		Edges:       edges,
+		ChainType:   classifyChain(edges),
+		hopBindings: append(append([][]string{}, p.hopBindings...), step.bindings),
+	}
+}
+
+// FindResourcePaths returns, per resource, the impersonation paths through which a principal gains roles it does
+// not hold directly, sorted by resource ID. In shortest mode only the first shortest path of each resource is kept;
+// in min-cut mode the minimal cut sets of each resource are computed over all its paths.
+func FindResourcePaths(principal string, directAccess map[string]*PrincipalData, graph *ImpersonationGraph, mode string, maxDepth int) []ResourcePaths {
+	direct := directAccess[principal]
+	byResource := make(map[string]*ResourcePaths)
+	roles := make(map[string]map[string]bool)
+
+	var paths []ImpersonationPath
+	truncated := false
+	if mode == PathModeShortest {
+		paths = ShortestImpersonationPaths(principal, graph, maxDepth)
+	} else {
+		paths, truncated = EnumerateImpersonationPaths(principal, graph, maxDepth)
+	}
+	for _, path := range paths {
+		target := directAccess[path.Chain[len(path.Chain)-1]]
+		if target == nil {
+			continue
+		}
+		holder := path.Chain[len(path.Chain)-1]
+		for resID, meta := range target.ResourceAccess {
+			var grants []string
+			for role := range meta.Roles {
+				if direct != nil && direct.ResourceAccess[resID] != nil && direct.ResourceAccess[resID].Roles[role] {
+					continue
+				}
+				if roles[resID] == nil {
+					roles[resID] = make(map[string]bool)
+				}
+				roles[resID][role] = true
+				grants = append(grants, grantBinding(meta, role, resID, holder))
+			}
+			if len(grants) == 0 {
+				continue
+			}
+			rp, ok := byResource[resID]
+			if !ok {
+				rp = &ResourcePaths{ResourceID: resID, ResourceType: meta.Type, Truncated: truncated}
+				byResource[resID] = rp
+			}
+			rp.Paths = append(rp.Paths, path.withFinalGrant(grants))
+		}
+	}
+
+	result := make([]ResourcePaths, 0, len(byResource))
+	for resID, rp := range byResource {
+		for role := range roles[resID] {
+			rp.Roles = append(rp.Roles, role)
+		}
+		sort.Strings(rp.Roles)
+		sortPaths(rp.Paths)
+
+		switch mode {
+		case PathModeShortest:
+			rp.Paths = rp.Paths[:1]
+		case PathModeMinCut:
+			rp.MinimalCuts, rp.CutApproximate = minimalCuts(rp.Paths)
+		}
+		result = append(result, *rp)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ResourceID < result[j].ResourceID })
+	return result
+}
+
+// EdgeBinding identifies the binding behind an edge: its Terraform address, or a description of the grant
+// when the address is unknown
+func EdgeBinding(e ImpersonationEdge) string {
+	if e.SourceAddress != "" {
+		return e.SourceAddress
+	}
+	return fmt.Sprintf("%s on %s for %s", e.Role, e.Scope, e.Source)
+}
+
+// grantBinding identifies the binding granting a role on a resource to the account at the end of a path: its
+// Terraform address, or a description of the grant when the address is unknown
+func grantBinding(meta *ResourceMetadata, role, resourceID, holder string) string {
+	if addr := meta.TerraformAddrs[role]; addr != "" {
+		return addr
+	}
+	return fmt.Sprintf("%s on %s for %s", role, resourceID, holder)
+}
+
+// withFinalGrant returns a copy of the path ending with a last hop carried by the given grants of the resource it
+// reaches, so cuts may remove the grant itself rather than the impersonation bindings leading to it
+func (p ImpersonationPath) withFinalGrant(grants []string) ImpersonationPath {
+	seen := make(map[string]bool)
+	var last []string
+	for _, g := range grants {
+		if !seen[g] {
+			seen[g] = true
+			last = append(last, g)
+		}
+	}
+	sort.Strings(last)
+	p.hopBindings = append(append([][]string{}, p.hopBindings...), last)
+	return p
+}
+
+// hopBindings returns the bindings of every edge from sources to target that deny policies leave to actor, sorted
+func (g *ImpersonationGraph) hopBindings(actor string, sources []string, target string) []string {
+	seen := make(map[string]bool)
+	var bindings []string
+	for _, source := range sources {
+		for _, e := range g.Graph[source] {
+			if e.Target != target {
+				continue
+			}
+			if denial := g.Deny.DeniedEdge(actor, e); denial != nil && denial.Revoked {
+				continue
+			}
+			if binding := EdgeBinding(e); !seen[binding] {
+				seen[binding] = true
+				bindings = append(bindings, binding)
+			}
+		}
+	}
+	sort.Strings(bindings)
+	return bindings
+}
+
+// routes expands a path into the sets of bindings that each carry it end to end, one binding per hop
+func (p ImpersonationPath) routes() []map[string]bool {
+	routes := []map[string]bool{{}}
+	for _, alternatives := range p.hopBindings {
+		var next []map[string]bool
+		for _, route := range routes {
+			for _, binding := range alternatives {
+				extended := make(map[string]bool, len(route)+1)
+				for b := range route {
+					extended[b] = true
+				}
+				extended[binding] = true
+				next = append(next, extended)
+			}
+		}
+		routes = next
+	}
+	return routes
+}
+
+// minimalCuts returns every smallest set of bindings that includes a binding of each route of each path.
+// With more than maxCutRoutes routes or maxExactCutCandidates bindings involved, a single greedy cut is
+// returned and marked approximate.
+func minimalCuts(paths []ImpersonationPath) ([][]string, bool) {
+	routeCount := 0
+	for _, p := range paths {
+		n := 1
+		for _, alternatives := range p.hopBindings {
+			if n *= len(alternatives); n > maxCutRoutes {
+				break
+			}
+		}
+		if routeCount += n; routeCount > maxCutRoutes {
+			return [][]string{greedyCut(paths)}, true
+		}
+	}
+
+	var pathBindings []map[string]bool
+	candidateSet := make(map[string]bool)
+	for _, p := range paths {
+		for _, route := range p.routes() {
+			pathBindings = append(pathBindings, route)
+			for binding := range route {
+				candidateSet[binding] = true
+			}
+		}
+	}
+	candidates := make([]string, 0, len(candidateSet))
+	for c := range candidateSet {
+		candidates = append(candidates, c)
+	}
+	sort.Strings(candidates)
+
+	if len(candidates) > maxExactCutCandidates {
+		return [][]string{greedyCut(paths)}, true
+	}
+
+	severs := func(cut []string) bool {
+		for _, bindings := range pathBindings {
+			hit := false
+			for _, c := range cut {
+				if bindings[c] {
+					hit = true
+					break
+				}
+			}
+			if !hit {
+				return false
+			}
+		}
+		return true
+	}
+
+	for size := 1; size <= len(candidates); size++ {
+		var cuts [][]string
+		forEachCombination(candidates, size, func(cut []string) {
+			if severs(cut) {
+				cuts = append(cuts, append([]string{}, cut...))
+			}
+		})
+		if len(cuts) > 0 {
+			return cuts, false
+		}
+	}
+	return nil, false
+}
+
+// greedyCut repeatedly severs the hop on the most paths not yet severed, removing every binding that allows it
+func greedyCut(paths []ImpersonationPath) []string {
+	cut := make(map[string]bool)
+	severed := make([]bool, len(paths))
+	remaining := len(paths)
+	for remaining > 0 {
+		counts := make(map[string]int)
+		hops := make(map[string][]string)
+		for i, p := range paths {
+			if severed[i] {
+				continue
+			}
+			seen := make(map[string]bool)
+			for _, hop := range p.hopBindings {
+				key := strings.Join(hop, "\x00")
+				if !seen[key] {
+					seen[key] = true
+					counts[key]++
+					hops[key] = hop
+				}
+			}
+		}
+		best := ""
+		for key, count := range counts {
+			if count > counts[best] || (count == counts[best] && key < best) {
+				best = key
+			}
+		}
+		if counts[best] == 0 {
+			break
+		}
+		for _, binding := range hops[best] {
+			cut[binding] = true
+		}
+		for i, p := range paths {
+			if !severed[i] && p.severedBy(cut) {
+				severed[i] = true
+				remaining--
+			}
+		}
+	}
+
+	bindings := make([]string, 0, len(cut))
+	for binding := range cut {
+		bindings = append(bindings, binding)
+	}
+	sort.Strings(bindings)
+	return bindings
+}
+
+// severedBy reports whether removing the bindings of cut severs one of the path's hops
+func (p ImpersonationPath) severedBy(cut map[string]bool) bool {
+	for _, alternatives := range p.hopBindings {
+		all := true
+		for _, binding := range alternatives {
+			if !cut[binding] {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// forEachCombination calls fn with every combination of size items, in lexical order
+func forEachCombination(items []string, size int, fn func([]string)) {
+	combination := make([]string, 0, size)
+	var pick func(start int)
+	pick = func(start int) {
+		if len(combination) == size {
+			fn(combination)
+			return
+		}
+		for i := start; i <= len(items)-(size-len(combination)); i++ {
+			combination = append(combination, items[i])
+			pick(i + 1)
+			combination = combination[:len(combination)-1]
+		}
+	}
+	pick(0)
+}
+
+// sortPaths orders paths by length, then by chain
+func sortPaths(paths []ImpersonationPath) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		if len(paths[i].Chain) != len(paths[j].Chain) {
+			return len(paths[i].Chain) < len(paths[j].Chain)
+		}
+		return strings.Join(paths[i].Chain, "\x00") < strings.Join(paths[j].Chain, "\x00")
+	})
+}
@@ -0,0 +1,145 @@
+package analyzer
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestFindResourcePaths(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	impersonate := func(addr, member, sa string) parser.IAMBinding {
+		return parser.IAMBinding{
+			ResourceID: "projects/app/serviceAccounts/" + sa + "@app.iam.gserviceaccount.com", ResourceType: "google_service_account_iam_member",
+			ResourceLevel: "resource", Role: "roles/iam.serviceAccountTokenCreator", Members: []string{member}, TerraformAddr: addr,
+		}
+	}
+	// alice reaches c through a and through b
+	bindings := []parser.IAMBinding{
+		impersonate("alice_a", "user:alice@example.com", "a"),
+		impersonate("alice_b", "user:alice@example.com", "b"),
+		impersonate("a_c", "serviceAccount:a@app.iam.gserviceaccount.com", "c"),
+		impersonate("b_c", "serviceAccount:b@app.iam.gserviceaccount.com", "c"),
+		{
+			ResourceID: "data", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource",
+			Role: "roles/storage.admin", Members: []string{"serviceAccount:c@app.iam.gserviceaccount.com"}, TerraformAddr: "c_data",
+		},
+	}
+	graph := BuildImpersonationGraph(bindings)
+	directAccess := Analyze(bindings)
+
+	pathsTo := func(mode string, maxDepth int) *ResourcePaths {
+		for _, rp := range FindResourcePaths("user:alice@example.com", directAccess, graph, mode, maxDepth) {
+			if rp.ResourceID == "data" {
+				return &rp
+			}
+		}
+		return nil
+	}
+
+	all := pathsTo(PathModeAll, 0)
+	if all == nil || len(all.Paths) != 2 {
+		t.Fatalf("all mode should find both routes to data, got %+v", all)
+	}
+	if !reflect.DeepEqual(all.Roles, []string{"roles/storage.admin"}) {
+		t.Errorf("Roles = %v, want [roles/storage.admin]", all.Roles)
+	}
+
+	if shortest := pathsTo(PathModeShortest, 0); shortest == nil || len(shortest.Paths) != 1 {
+		t.Errorf("shortest mode should keep one path, got %+v", shortest)
+	}
+
+	if limited := pathsTo(PathModeAll, 1); limited != nil {
+		t.Errorf("data is two hops away and should not be reached with a depth limit of 1, got %+v", limited)
+	}
+
+	cut := pathsTo(PathModeMinCut, 0)
+	// Removing c's own grant on data severs both paths at once
+	want := [][]string{{"c_data"}}
+	if cut == nil || !reflect.DeepEqual(cut.MinimalCuts, want) || cut.CutApproximate {
+		t.Errorf("MinimalCuts = %v, want %v", cut.MinimalCuts, want)
+	}
+
+	// Once c also reaches data through a second binding, severing the grant takes both
+	bindings = append(bindings, parser.IAMBinding{
+		ResourceID: "data", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource",
+		Role: "roles/storage.objectAdmin", Members: []string{"serviceAccount:c@app.iam.gserviceaccount.com"}, TerraformAddr: "c_data_objects",
+	})
+	graph = BuildImpersonationGraph(bindings)
+	directAccess = Analyze(bindings)
+	cut = pathsTo(PathModeMinCut, 0)
+	want = [][]string{{"a_c", "alice_b"}, {"a_c", "b_c"}, {"alice_a", "alice_b"}, {"alice_a", "b_c"}, {"c_data", "c_data_objects"}}
+	if cut == nil || !reflect.DeepEqual(cut.MinimalCuts, want) {
+		t.Errorf("MinimalCuts = %v, want %v", cut.MinimalCuts, want)
+	}
+}
+
+func TestMinimalCuts_ParallelBindings(t *testing.T) {
+	// Two bindings allow the same hop, so removing one of them leaves the path open
+	paths := []ImpersonationPath{{Chain: []string{"sa"}, hopBindings: [][]string{{"direct", "via_group"}}}}
+	cuts, approximate := minimalCuts(paths)
+	if approximate || !reflect.DeepEqual(cuts, [][]string{{"direct", "via_group"}}) {
+		t.Errorf("minimalCuts() = %v, %v; want both bindings in a single cut", cuts, approximate)
+	}
+}
+
+// denseGraphBindings returns bindings letting alice and n service accounts impersonate every service account of
+// the project, each account holding the storage admin role on a bucket of its own
+func denseGraphBindings(n int) []parser.IAMBinding {
+	members := []string{"user:alice@example.com"}
+	var bindings []parser.IAMBinding
+	for i := 0; i < n; i++ {
+		sa := fmt.Sprintf("serviceAccount:sa%02d@app.iam.gserviceaccount.com", i)
+		members = append(members, sa)
+		bindings = append(bindings, parser.IAMBinding{
+			ResourceID: fmt.Sprintf("bucket-%02d", i), ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource",
+			Role: "roles/storage.admin", Members: []string{sa}, TerraformAddr: fmt.Sprintf("google_storage_bucket_iam_member.sa%02d", i),
+		})
+	}
+	return append(bindings, parser.IAMBinding{
+		ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project",
+		Role: "roles/iam.serviceAccountTokenCreator", Members: members, TerraformAddr: "google_project_iam_member.token_creators",
+	})
+}
+
+func TestFindResourcePaths_DenseGraph(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	bindings := denseGraphBindings(22)
+	graph := BuildImpersonationGraph(bindings)
+	directAccess := Analyze(bindings)
+
+	for _, mode := range PathModes {
+		t.Run(mode, func(t *testing.T) {
+			start := time.Now()
+			result := FindResourcePaths("user:alice@example.com", directAccess, graph, mode, 0)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("FindResourcePaths(%s) took %v", mode, elapsed)
+			}
+			if len(result) != 22 {
+				t.Fatalf("FindResourcePaths(%s) reached %d buckets, want 22", mode, len(result))
+			}
+			for _, rp := range result {
+				if rp.Truncated != (mode != PathModeShortest) {
+					t.Errorf("%s: Truncated = %v", rp.ResourceID, rp.Truncated)
+				}
+				if mode == PathModeShortest && (len(rp.Paths) != 1 || len(rp.Paths[0].Chain) != 1) {
+					t.Errorf("%s: shortest paths = %+v, want the single direct hop", rp.ResourceID, rp.Paths)
+				}
+			}
+		})
+	}
+
+	if paths, truncated := EnumerateImpersonationPaths("user:alice@example.com", graph, 0); len(paths) != MaxImpersonationPaths || !truncated {
+		t.Errorf("EnumerateImpersonationPaths() = %d paths, truncated %v; want %d, truncated", len(paths), truncated, MaxImpersonationPaths)
+	}
+}
@@ -0,0 +1,381 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// accessLevelRank orders the access levels of hierarchical_roles from least to most privileged
+var accessLevelRank = map[string]int{"read": 1, "write": 2, "admin": 3}
+
+// Persona is what the principals of a persona policy need: the bindings the policy requires of them
+type Persona struct {
+	Name     string
+	Matches  func(principal string) bool
+	Required []PersonaBinding
+}
+
+// PersonaBinding is a binding a persona requires: a role on the resources matching a pattern
+type PersonaBinding struct {
+	Role          string
+	ResourceType  string // Terraform resource type of the resources; any when empty
+	MatchResource func(resourceID string) bool
+}
+
+// RoleSuggestion is a narrower role, bound on a scope or resource, covering needed resources
+type RoleSuggestion struct {
+	Role          string   `json:"role"`
+	AccessLevel   string   `json:"access_level,omitempty"`
+	Scope         Scope    `json:"scope"`                    // scope or resource the role is bound on
+	ResourceType  string   `json:"resource_type"`            // Terraform IAM resource type of the proposed binding
+	ResourceTypes []string `json:"resource_types,omitempty"` // types of the needed resources the role covers there
+	Resources     []string `json:"resources,omitempty"`      // IDs of the needed resources the role covers there
+	Terraform     string   `json:"terraform,omitempty"`
+}
+
+// Recommendation narrows one principal's grant of a broad role to the resources its persona needs
+type Recommendation struct {
+	Principal   string              `json:"principal"`
+	Role        string              `json:"role"`
+	Scope       Scope               `json:"scope"`
+	Binding     string              `json:"binding"`
+	Location    string              `json:"location,omitempty"`
+	Condition   string              `json:"condition,omitempty"`
+	Persona     string              `json:"persona,omitempty"`  // name of the persona applied, if any
+	Required    bool                `json:"required,omitempty"` // the persona requires the broad role itself
+	AccessLevel string              `json:"access_level"`       // access level of the broad role
+	Resources   []InventoryResource `json:"resources"`          // declared resources the grant reaches that are needed
+	Suggestions []RoleSuggestion    `json:"suggestions"`
+	Uncovered   []string            `json:"uncovered_types,omitempty"` // needed types no narrower predefined role covers
+}
+
+// need is a role needed on a resource reached by a grant, or on the granted scope itself
+type need struct {
+	resource InventoryResource
+	role     string // role required by the persona, or "" for the narrowest role of the resource's type
+	onScope  bool
+}
+
+// IsBroadRole reports whether a role is broad enough to be narrowed: roles/owner, roles/editor and service
+// admin roles such as roles/storage.admin
+func IsBroadRole(role string) bool {
+	return role == definitions.OwnerRole || role == "roles/editor" || (strings.HasPrefix(role, "roles/") && strings.HasSuffix(role, ".admin"))
+}
+
+// Recommend suggests, for every grant of a broad role, narrower roles on the lowest scopes that still cover
+// what the principal needs. The resources an organization, folder or project grant reaches come from the
+// inventory listed on result's project entries (see ExpandHierarchicalAccess); a grant on a resource reaches
+// that resource. A principal matching a persona needs the persona's required bindings the grant gives;
+// any other principal needs the broad role's access level on every resource reached. Each suggestion carries
+// a proposed Terraform binding, written with the IAM member resource types of defs. personas are matched in order.
+func Recommend(bindings []parser.IAMBinding, result *HierarchyAnalysisResult, inventory []InventoryResource, defs []parser.ResourceDefinition, personas []Persona) []Recommendation {
+	// Inventory resources reached by each grant of a hierarchy level, keyed by principal, role, address and bound scope
+	reached := make(map[string][]InventoryResource)
+	for _, entry := range result.HierarchicalAccess {
+		bound := entry.Scope
+		if entry.InheritedFrom != nil {
+			bound = *entry.InheritedFrom
+		}
+		key := entry.Principal + "|" + entry.Role + "|" + entry.Source.ResourceAddress + "|" + scopeKey(bound.Type, bound.ID)
+		reached[key] = append(reached[key], entry.Resources...)
+	}
+
+	memberAttrs := make(map[string]string)
+	for _, def := range defs {
+		if strings.HasSuffix(def.Type, "_iam_member") && def.FieldMappings.ResourceID != "" && !strings.Contains(def.FieldMappings.ResourceID, ",") {
+			memberAttrs[def.Type] = def.FieldMappings.ResourceID
+		}
+	}
+	roles := definitions.GetRulesConfig().HierarchicalRoles
+
+	var recs []Recommendation
+	seen := make(map[string]bool)
+	for _, b := range bindings {
+		if !IsBroadRole(b.Role) || (!isHierarchyLevel(b.ResourceLevel) && b.ResourceLevel != "resource") {
+			continue
+		}
+		for _, member := range b.Members {
+			key := member + "|" + b.Role + "|" + b.TerraformAddr + "|" + scopeKey(b.ResourceLevel, b.ResourceID)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			rec := Recommendation{
+				Principal:   member,
+				Role:        b.Role,
+				Scope:       Scope{Type: b.ResourceLevel, ID: b.ResourceID},
+				Binding:     b.TerraformAddr,
+				Location:    b.Location,
+				AccessLevel: "admin",
+			}
+			if b.Condition != nil {
+				rec.Condition = b.Condition.Expression
+			}
+			if _, h := definitions.MatchRoleHierarchy(b.Role); h != nil && accessLevelRank[h.AccessLevel] > 0 {
+				rec.AccessLevel = h.AccessLevel
+			}
+
+			candidates := reached[key]
+			if b.ResourceLevel == "resource" {
+				candidates = []InventoryResource{boundResource(b, inventory)}
+			}
+
+			var needs []need
+			if p := matchPersona(personas, member); p != nil {
+				rec.Persona = p.Name
+				needs, rec.Required = personaNeeds(*p, b, candidates)
+			} else {
+				for _, r := range candidates {
+					needs = append(needs, need{resource: r})
+				}
+			}
+
+			added := make(map[string]bool)
+			for _, n := range needs {
+				if !n.onScope && !added[n.resource.Type+"|"+n.resource.ID] {
+					added[n.resource.Type+"|"+n.resource.ID] = true
+					rec.Resources = append(rec.Resources, n.resource)
+				}
+			}
+			if rec.Resources == nil {
+				rec.Resources = []InventoryResource{}
+			}
+			rec.Suggestions, rec.Uncovered = suggestRoles(rec, b, needs, roles, memberAttrs)
+			recs = append(recs, rec)
+		}
+	}
+
+	sort.SliceStable(recs, func(i, j int) bool {
+		a, b := recs[i], recs[j]
+		if a.Principal != b.Principal {
+			return a.Principal < b.Principal
+		}
+		if a.Scope != b.Scope {
+			return scopeKey(a.Scope.Type, a.Scope.ID) < scopeKey(b.Scope.Type, b.Scope.ID)
+		}
+		return a.Role < b.Role
+	})
+	return recs
+}
+
+// matchPersona returns the first persona a principal belongs to, or nil
+func matchPersona(personas []Persona, principal string) *Persona {
+	for i := range personas {
+		if personas[i].Matches(principal) {
+			return &personas[i]
+		}
+	}
+	return nil
+}
+
+// personaNeeds returns the bindings required by a persona that a grant of b gives: on the granted scope itself,
+// or on the reached resources matching them. required is true when the persona requires the broad role itself.
+func personaNeeds(p Persona, b parser.IAMBinding, reached []InventoryResource) (needs []need, required bool) {
+	for _, rb := range p.Required {
+		_, h := definitions.MatchRoleHierarchy(rb.Role)
+		reaches := func(r InventoryResource) bool {
+			return rb.MatchResource(r.ID) && (rb.ResourceType == "" || rb.ResourceType == r.Type) &&
+				h != nil && (containsString(h.ResourceTypes, r.Type) || containsString(h.ResourceTypes, "*"))
+		}
+		onScope := b.ResourceLevel != "resource" && rb.ResourceType == "" && rb.MatchResource(b.ResourceID)
+
+		if definitions.RoleIncludes(rb.Role, b.Role) {
+			for _, r := range reached {
+				onScope = onScope || reaches(r)
+			}
+			required = required || onScope
+			continue
+		}
+		if !grantedWithin(rb.Role, b.Role) {
+			continue
+		}
+		if onScope {
+			needs = append(needs, need{resource: InventoryResource{ID: b.ResourceID}, role: rb.Role, onScope: true})
+		}
+		for _, r := range reached {
+			if reaches(r) {
+				needs = append(needs, need{resource: r, role: rb.Role})
+			}
+		}
+	}
+	return needs, required
+}
+
+// grantedWithin reports whether a grant of broad gives everything role gives: broad includes role, or reaches
+// every resource type role does at the same or a higher access level
+func grantedWithin(role, broad string) bool {
+	if definitions.RoleIncludes(broad, role) {
+		return true
+	}
+	_, b := definitions.MatchRoleHierarchy(broad)
+	_, r := definitions.MatchRoleHierarchy(role)
+	if b == nil || r == nil || accessLevelRank[r.AccessLevel] > accessLevelRank[b.AccessLevel] {
+		return false
+	}
+	for _, t := range r.ResourceTypes {
+		if !containsString(b.ResourceTypes, "*") && !containsString(b.ResourceTypes, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// boundResource returns the inventory resource a resource-level binding is made on, or one described by the
+// binding itself when the resource is not declared
+func boundResource(b parser.IAMBinding, inventory []InventoryResource) InventoryResource {
+	resourceType := BaseResourceType(b.ResourceType)
+	for _, r := range inventory {
+		if r.Type == resourceType && r.ID == b.ResourceID {
+			return r
+		}
+	}
+	return InventoryResource{Type: resourceType, ID: b.ResourceID}
+}
+
+// suggestRoles binds each need's role, or the narrowest predefined role of the broad role's access level for
+// its type, on the lowest scope covering it. Roles bound on resources are suggested on each resource when the
+// type has an IAM member resource; others on each resource's project. Needs on the granted scope stay there.
+func suggestRoles(rec Recommendation, b parser.IAMBinding, needs []need, roles map[string]definitions.RoleHierarchy, memberAttrs map[string]string) ([]RoleSuggestion, []string) {
+	byKey := make(map[string]*RoleSuggestion)
+	var order []string
+	var uncovered []string
+	for _, n := range needs {
+		r := n.resource
+		role := n.role
+		var def definitions.RoleHierarchy
+		if role == "" {
+			var ok bool
+			if role, def, ok = narrowerRole(rec.Role, r.Type, rec.AccessLevel, roles); !ok {
+				if !containsString(uncovered, r.Type) {
+					uncovered = append(uncovered, r.Type)
+				}
+				continue
+			}
+		} else if _, h := definitions.MatchRoleHierarchy(role); h != nil {
+			def = *h
+		}
+
+		scope, iamType := rec.Scope, iamMemberType(rec.Scope.Type, b.ResourceType)
+		switch {
+		case n.onScope, b.ResourceLevel == "resource":
+		case def.TargetLevel == "resource" && memberAttrs[r.Type+"_iam_member"] != "":
+			scope, iamType = Scope{Type: "resource", ID: r.ID}, r.Type+"_iam_member"
+		case r.Project != "":
+			scope, iamType = Scope{Type: "project", ID: r.Project}, iamMemberType("project", "")
+		}
+
+		key := role + "|" + scopeKey(scope.Type, scope.ID)
+		s, exists := byKey[key]
+		if !exists {
+			s = &RoleSuggestion{Role: role, AccessLevel: def.AccessLevel, Scope: scope, ResourceType: iamType}
+			byKey[key] = s
+			order = append(order, key)
+		}
+		if n.onScope {
+			continue
+		}
+		if !containsString(s.ResourceTypes, r.Type) {
+			s.ResourceTypes = append(s.ResourceTypes, r.Type)
+		}
+		if !containsString(s.Resources, r.ID) {
+			s.Resources = append(s.Resources, r.ID)
+		}
+	}
+
+	suggestions := make([]RoleSuggestion, 0, len(order))
+	for _, key := range order {
+		s := *byKey[key]
+		if attr := memberAttrs[s.ResourceType]; attr != "" {
+			s.Terraform = proposedBinding(s.ResourceType, attr, s.Scope.ID, s.Role, rec.Principal, b.Condition)
+		}
+		suggestions = append(suggestions, s)
+	}
+	sort.Strings(uncovered)
+	return suggestions, uncovered
+}
+
+// narrowerRole returns the predefined role granting access to resourceType with the given access level,
+// preferring roles other than service admin roles, then roles covering fewer resource types, then by name.
+// roles/owner, roles/editor, role patterns and roles including broad are never suggested.
+func narrowerRole(broad, resourceType, accessLevel string, roles map[string]definitions.RoleHierarchy) (string, definitions.RoleHierarchy, bool) {
+	best := ""
+	var bestDef definitions.RoleHierarchy
+	less := func(role string, def definitions.RoleHierarchy) bool {
+		if IsBroadRole(role) != IsBroadRole(best) {
+			return !IsBroadRole(role)
+		}
+		if len(def.ResourceTypes) != len(bestDef.ResourceTypes) {
+			return len(def.ResourceTypes) < len(bestDef.ResourceTypes)
+		}
+		return role < best
+	}
+	for role, def := range roles {
+		if def.AccessLevel != accessLevel || !containsString(def.ResourceTypes, resourceType) {
+			continue
+		}
+		if role == definitions.OwnerRole || role == "roles/editor" || definitions.IsRolePattern(role) || definitions.RoleIncludes(role, broad) {
+			continue
+		}
+		if best == "" || less(role, def) {
+			best, bestDef = role, def
+		}
+	}
+	return best, bestDef, best != ""
+}
+
+// iamMemberType returns the IAM member resource type for bindings on a scope level; resource-level bindings keep
+// the member variant of their own IAM resource type
+func iamMemberType(level, resourceType string) string {
+	switch level {
+	case "organization", "folder", "project":
+		return "google_" + level + "_iam_member"
+	}
+	if strings.Contains(resourceType, "_iam_") {
+		return BaseResourceType(resourceType) + "_iam_member"
+	}
+	return resourceType
+}
+
+// nonIdentifier matches the characters not allowed in a Terraform resource name
+var nonIdentifier = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// proposedBinding writes a Terraform IAM member resource granting role to member on id
+func proposedBinding(resourceType, attr, id, role, member string, condition *parser.Condition) string {
+	account := member
+	if _, email, ok := strings.Cut(member, ":"); ok {
+		account = email
+	}
+	account, _, _ = strings.Cut(account, "@")
+	name := strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(account+"_"+strings.TrimPrefix(role, "roles/")+"_"+id), "_"), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "r_" + name
+	}
+
+	width := len(attr)
+	if width < len("member") {
+		width = len("member")
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "resource %q %q {\n", resourceType, name)
+	fmt.Fprintf(&sb, "  %-*s = %q\n", width, attr, id)
+	fmt.Fprintf(&sb, "  %-*s = %q\n", width, "role", role)
+	fmt.Fprintf(&sb, "  %-*s = %q\n", width, "member", member)
+	if condition != nil {
+		title := condition.Title
+		if title == "" {
+			title = "condition"
+		}
+		sb.WriteString("\n  condition {\n")
+		fmt.Fprintf(&sb, "    title      = %q\n", title)
+		fmt.Fprintf(&sb, "    expression = %q\n", condition.Expression)
+		sb.WriteString("  }\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestRecommend(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	defs, err := definitions.LoadResourceDefinitions()
+	if err != nil {
+		t.Fatalf("LoadResourceDefinitions() error = %v", err)
+	}
+
+	ci := "serviceAccount:ci@app.iam.gserviceaccount.com"
+	bindings := []parser.IAMBinding{
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", ParentType: "folder", ParentID: "10", Role: "roles/owner", Members: []string{ci}, TerraformAddr: "ci_owner", Location: "main.tf:1"},
+		{ResourceID: "10", ResourceType: "google_folder_iam_member", ResourceLevel: "folder", Role: "roles/editor", Members: []string{"user:ops@example.com"}, TerraformAddr: "ops_editor"},
+		{ResourceID: "raw", ResourceType: "google_storage_bucket_iam_binding", ResourceLevel: "resource", Role: "roles/storage.admin", Members: []string{"group:data@example.com"}, TerraformAddr: "data_raw"},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/storage.objectViewer", Members: []string{"user:viewer@example.com"}, TerraformAddr: "viewer"},
+		{ResourceID: "empty", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/editor", Members: []string{"user:idle@example.com"}, TerraformAddr: "idle"},
+	}
+	inventory := []InventoryResource{
+		{Type: "google_bigquery_dataset", ID: "events", Project: "app", Address: "google_bigquery_dataset.events"},
+		{Type: "google_storage_bucket", ID: "logs", Project: "app", Address: "google_storage_bucket.logs"},
+	}
+	result := AnalyzeHierarchy(bindings)
+	ExpandHierarchicalAccess(result, inventory)
+	personas := []Persona{
+		{
+			Name:    "Operators",
+			Matches: func(p string) bool { return p == "user:ops@example.com" },
+			Required: []PersonaBinding{
+				{Role: "roles/storage.objectViewer", MatchResource: func(id string) bool { return id == "logs" }},
+				{Role: "roles/viewer", MatchResource: func(id string) bool { return id == "10" }},
+			},
+		},
+		{
+			Name:     "Idle",
+			Matches:  func(p string) bool { return p == "user:idle@example.com" },
+			Required: []PersonaBinding{{Role: "roles/editor", MatchResource: func(id string) bool { return id == "empty" }}},
+		},
+	}
+
+	recs := Recommend(bindings, result, inventory, defs, personas)
+	var got []string
+	for _, r := range recs {
+		var suggestions []string
+		for _, s := range r.Suggestions {
+			suggestions = append(suggestions, s.Role+"@"+s.Scope.Type+":"+s.Scope.ID)
+		}
+		got = append(got, r.Principal+" "+r.Role+" "+r.AccessLevel+" "+strings.Join(suggestions, ","))
+	}
+	want := []string{
+		"group:data@example.com roles/storage.admin admin roles/storage.objectAdmin@resource:raw",
+		"serviceAccount:ci@app.iam.gserviceaccount.com roles/owner admin roles/bigquery.dataOwner@resource:events,roles/storage.objectAdmin@resource:logs",
+		"user:idle@example.com roles/editor write ",
+		"user:ops@example.com roles/editor write roles/storage.objectViewer@resource:logs,roles/viewer@folder:10",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("Recommend() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if recs[0].Suggestions[0].ResourceType != "google_storage_bucket_iam_member" {
+		t.Errorf("a resource-level binding should be narrowed in place, got %+v", recs[0].Suggestions[0])
+	}
+	if recs[3].Persona != "Operators" || len(recs[3].Resources) != 1 {
+		t.Errorf("the Operators persona should limit the needed resources to the bucket, got %+v", recs[3])
+	}
+	if !recs[2].Required || len(recs[2].Suggestions) != 0 {
+		t.Errorf("a persona requiring the broad role should keep it, got %+v", recs[2])
+	}
+	tf := recs[1].Suggestions[1].Terraform
+	for _, line := range []string{`resource "google_storage_bucket_iam_member" "ci_storage_objectadmin_logs" {`, `  bucket = "logs"`, `  member = "` + ci + `"`} {
+		if !strings.Contains(tf, line+"\n") {
+			t.Errorf("proposed binding missing %q:\n%s", line, tf)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// Kinds of redundant grants
+const (
+	RedundantDuplicate = "duplicate" // the same role, scope and condition granted by another binding
+	RedundantInherited = "inherited" // the same role granted on an ancestor scope
+	RedundantSubsumed  = "subsumed"  // the role is included in a broader role on the same or an ancestor scope
+	RedundantGroup     = "group"     // the role, or one including it, is held through a group on the same or an ancestor scope
+)
+
+// GrantRef identifies a single role grant to a principal
+type GrantRef struct {
+	Role            string `json:"role"`
+	ScopeType       string `json:"scope_type"` // organization, folder, project, resource
+	ScopeID         string `json:"scope_id"`
+	ResourceAddress string `json:"resource_address"`
+	Location        string `json:"location,omitempty"`  // "file:line" of the binding, for HCL input
+	Condition       string `json:"condition,omitempty"` // IAM condition expression of the binding
+}
+
+// RedundantGrant is a grant that adds no access: the same grant made by another binding, or one whose access
+// is already given by a broader role or a group on the same scope or on an ancestor scope
+type RedundantGrant struct {
+	Principal string   `json:"principal"`
+	Grant     GrantRef `json:"grant"`
+	CoveredBy GrantRef `json:"covered_by"`
+	Kind      string   `json:"kind"`                 // One of the Redundant* constants
+	ViaGroups []string `json:"via_groups,omitempty"` // For group coverage, the groups leading to the covering grant's group, innermost first
+}
+
+// FindRedundantGrants reports, per principal, grants made redundant by another grant of a
+// role that includes them, on the same scope or on an ancestor folder or organization.
+// Identical roles on the same scope and mutually-including roles on the same scope are not reported.
+func FindRedundantGrants(bindings []parser.IAMBinding) []RedundantGrant {
+	return FindRedundantGrantsWithGroups(bindings, nil)
+}
+
+// FindRedundantGrantsWithGroups reports, per principal, grants that add no access:
+//   - the same role, scope and condition granted again by another binding (e.g. duplicate _iam_members across files)
+//   - the same role granted on an ancestor scope, including a project holding a resource
+//   - a role included in a broader one on the same or an ancestor scope
+//   - a role already held, or included in a role held, through one of the principal's groups
+//
+// Only unconditional grants, or grants with the same condition, cover another; equivalent roles on the same scope
+// are not reported. Each grant is reported once, against its nearest cover, the principal's own grants first.
+func FindRedundantGrantsWithGroups(bindings []parser.IAMBinding, groups *GroupMemberships) []RedundantGrant {
+	return FindRedundantGrantsWithParents(bindings, groups, nil)
+}
+
+// FindRedundantGrantsWithParents is FindRedundantGrantsWithGroups with the scope parents, as returned by
+// ParentMap. Nil parents are read from the bindings.
+func FindRedundantGrantsWithParents(bindings []parser.IAMBinding, groups *GroupMemberships, parents map[string]string) []RedundantGrant {
+	if parents == nil {
+		parents = buildParentMap(bindings)
+	}
+	grantsByPrincipal := make(map[string][]GrantRef)
+	first := make(map[string]GrantRef)
+	var result []RedundantGrant
+
+	for _, b := range bindings {
+		if b.Role == "" {
+			continue
+		}
+		grant := GrantRef{Role: b.Role, ScopeType: b.ResourceLevel, ScopeID: b.ResourceID, ResourceAddress: b.TerraformAddr, Location: b.Location}
+		if b.Condition != nil {
+			grant.Condition = b.Condition.Expression
+		}
+		for _, member := range b.Members {
+			key := member + "|" + scopeKey(grant.ScopeType, grant.ScopeID) + "|" + grant.Role + "|" + grant.Condition
+			if original, seen := first[key]; seen {
+				if original.ResourceAddress != grant.ResourceAddress {
+					result = append(result, RedundantGrant{Principal: member, Grant: grant, CoveredBy: original, Kind: RedundantDuplicate})
+				}
+				continue
+			}
+			first[key] = grant
+			grantsByPrincipal[member] = append(grantsByPrincipal[member], grant)
+		}
+	}
+
+	for principal, grants := range grantsByPrincipal {
+		paths := groups.GroupPaths(principal)
+		for _, grant := range grants {
+			scopes := ancestorScopes(scopeKey(grant.ScopeType, grant.ScopeID), parents)
+			if covering, ok := findCoveringGrant(grant, grants, scopes, true); ok {
+				kind := RedundantSubsumed
+				if covering.Role == grant.Role {
+					kind = RedundantInherited
+				}
+				result = append(result, RedundantGrant{Principal: principal, Grant: grant, CoveredBy: covering, Kind: kind})
+				continue
+			}
+			for _, path := range paths {
+				if covering, ok := findCoveringGrant(grant, grantsByPrincipal[path[len(path)-1]], scopes, false); ok {
+					result = append(result, RedundantGrant{Principal: principal, Grant: grant, CoveredBy: covering, Kind: RedundantGroup, ViaGroups: path})
+					break
+				}
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Principal != b.Principal {
+			return a.Principal < b.Principal
+		}
+		if a.Grant.ScopeID != b.Grant.ScopeID {
+			return a.Grant.ScopeID < b.Grant.ScopeID
+		}
+		if a.Grant.Role != b.Grant.Role {
+			return a.Grant.Role < b.Grant.Role
+		}
+		return a.Grant.ResourceAddress < b.Grant.ResourceAddress
+	})
+	return result
+}
+
+// buildParentMap returns the known parents of hierarchy nodes and of resources: "type:id" -> "type:id".
+// Resources are keyed "resource:<id>" and map to the project they belong to.
+func buildParentMap(bindings []parser.IAMBinding) map[string]string {
+	parents := make(map[string]string)
+	for _, b := range bindings {
+		if (isHierarchyLevel(b.ResourceLevel) || b.ResourceLevel == "resource") && b.ParentID != "" && b.ParentType != "" {
+			parents[scopeKey(b.ResourceLevel, b.ResourceID)] = scopeKey(b.ParentType, b.ParentID)
+		}
+	}
+	return parents
+}
+
+// ParentMap returns the known parents of hierarchy nodes and of resources, "type:id" -> "type:id": those the
+// bindings record, filled in with the parents of the tree, built from google_project and google_folder
+// declarations and the config hierarchy. A nil tree leaves only the parents recorded by the bindings.
+func ParentMap(bindings []parser.IAMBinding, tree *ResourceTree) map[string]string {
+	parents := buildParentMap(bindings)
+	if tree != nil {
+		for scope, parent := range tree.Parents() {
+			if _, ok := parents[scope]; !ok {
+				parents[scope] = parent
+			}
+		}
+	}
+	return parents
+}
+
+// scopeKey builds a "type:id" key for a hierarchy node, dropping the
+// "folders/" and "organizations/" prefixes so both ID forms compare equal
+func scopeKey(scopeType, id string) string {
+	id = strings.TrimPrefix(id, "folders/")
+	id = strings.TrimPrefix(id, "organizations/")
+	return scopeType + ":" + id
+}
+
+// ancestorScopes returns the scope itself followed by its known ancestors, nearest first
+func ancestorScopes(scope string, parents map[string]string) []string {
+	scopes := []string{scope}
+	seen := map[string]bool{scope: true}
+	for {
+		parent, ok := parents[scope]
+		if !ok || seen[parent] {
+			return scopes
+		}
+		seen[parent] = true
+		scopes = append(scopes, parent)
+		scope = parent
+	}
+}
+
+// findCoveringGrant returns the nearest grant in scopes whose role includes grant's role and whose condition,
+// if any, is grant's. Among the principal's own grants (self), the same or an equivalent role on grant's own
+// scope is grant itself and does not cover it.
+func findCoveringGrant(grant GrantRef, grants []GrantRef, scopes []string, self bool) (GrantRef, bool) {
+	for i, scope := range scopes {
+		for _, other := range grants {
+			if scopeKey(other.ScopeType, other.ScopeID) != scope || (other.Condition != "" && other.Condition != grant.Condition) {
+				continue
+			}
+			if self && i == 0 && (other.Role == grant.Role || definitions.RoleIncludes(grant.Role, other.Role)) {
+				continue // same grant, or equivalent roles on the same scope
+			}
+			if definitions.RoleIncludes(other.Role, grant.Role) {
+				return other, true
+			}
+		}
+	}
+	return GrantRef{}, false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestFindRedundantGrantsWithGroups(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	hours := &parser.Condition{Title: "hours", Expression: "request.time.getHours(\"UTC\") < 18"}
+	bindings := []parser.IAMBinding{
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", ParentType: "folder", ParentID: "10", Role: "roles/storage.objectViewer", Members: []string{"user:alice@example.com"}, TerraformAddr: "alice_project", Location: "iam.tf:1"},
+		{ResourceID: "logs", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource", ParentType: "project", ParentID: "app", Role: "roles/storage.objectViewer", Members: []string{"user:alice@example.com"}, TerraformAddr: "alice_logs", Location: "storage.tf:4"},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/viewer", Members: []string{"user:bob@example.com"}, TerraformAddr: "bob_viewer", Location: "iam.tf:7"},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/viewer", Members: []string{"user:bob@example.com"}, TerraformAddr: "bob_viewer_again", Location: "team.tf:2"},
+		{ResourceID: "10", ResourceType: "google_folder_iam_member", ResourceLevel: "folder", Role: "roles/editor", Members: []string{"group:devs@example.com"}, TerraformAddr: "devs_editor"},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/viewer", Members: []string{"user:carol@example.com"}, TerraformAddr: "carol_viewer"},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/owner", Members: []string{"user:dave@example.com"}, TerraformAddr: "dave_owner", Condition: hours},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/editor", Members: []string{"user:dave@example.com"}, TerraformAddr: "dave_editor"},
+	}
+	groups := NewGroupMemberships(map[string][]string{"devs@example.com": {"carol@example.com"}})
+
+	var got []string
+	redundant := FindRedundantGrantsWithGroups(bindings, groups)
+	for _, r := range redundant {
+		got = append(got, r.Principal+" "+r.Grant.ResourceAddress+" "+r.Kind+" "+r.CoveredBy.ResourceAddress)
+	}
+	want := []string{
+		"user:alice@example.com alice_logs inherited alice_project",
+		"user:bob@example.com bob_viewer_again duplicate bob_viewer",
+		"user:carol@example.com carol_viewer group devs_editor",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("FindRedundantGrantsWithGroups() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if r := redundant[1]; r.Grant.Location != "team.tf:2" || r.CoveredBy.Location != "iam.tf:7" {
+		t.Errorf("a duplicate should carry both source locations, got %+v", r)
+	}
+	if r := redundant[2]; len(r.ViaGroups) != 1 || r.ViaGroups[0] != "group:devs@example.com" {
+		t.Errorf("ViaGroups = %v, want [group:devs@example.com]", r.ViaGroups)
+	}
+
+	// Without groups, carol's grant is the only one giving her access
+	if got := FindRedundantGrants(bindings); len(got) != 2 {
+		t.Errorf("FindRedundantGrants() returned %d grants, want 2: %+v", len(got), got)
+	}
+}
@@ -0,0 +1,163 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// Route is one way a principal reaches a resource or another principal
+type Route struct {
+	Impersonation *ImpersonationPath `json:"impersonation,omitempty"` // Service accounts impersonated on the way, if any
+	Grant         *ResourceAccessor  `json:"grant,omitempty"`         // For resource targets, the final grant on the resource, held by the last account of the chain
+	GroupPath     []string           `json:"group_path,omitempty"`    // For group targets, the groups leading to the target, innermost first
+}
+
+// NearMiss is a binding next to the gap between two unconnected principals or a principal and a resource
+type NearMiss struct {
+	Principal string `json:"principal"` // Principal the binding is granted to
+	Target    string `json:"target"`    // Resource or service account the binding is on
+	Role      string `json:"role"`
+	Binding   string `json:"binding"`   // Terraform address of the binding, or a description of the grant
+	Reachable bool   `json:"reachable"` // True when the analyzed principal can already reach Principal
+}
+
+// PathResult is every route from a principal to a resource or principal, or what almost connects them
+type PathResult struct {
+	From       string     `json:"from"`
+	To         string     `json:"to"`
+	Routes     []Route    `json:"routes"`
+	Truncated  bool       `json:"truncated,omitempty"`   // True when impersonation paths were cut off at MaxImpersonationPaths, so routes may be missing
+	Reachable  []string   `json:"reachable,omitempty"`   // When there is no route, the accounts the principal can reach
+	NearMisses []NearMiss `json:"near_misses,omitempty"` // When there is no route, the bindings closest to connecting them
+}
+
+// FindRoutes returns every route from a principal to a resource ID or principal within maxDepth impersonation hops.
+// Routes to a resource end in a grant on it: held by the principal itself (directly, through a group or inherited),
+// or by a service account at the end of an impersonation chain. Routes to a principal are impersonation chains
+// ending at it, or group membership when it is a group. Without a route, the bindings that grant access to the target,
+// and those that grant impersonation of the service accounts holding it, are reported as near misses.
+func FindRoutes(from, to string, bindings []parser.IAMBinding, directAccess map[string]*PrincipalData, graph *ImpersonationGraph, maxDepth int) *PathResult {
+	result := &PathResult{From: from, To: to, Routes: []Route{}}
+	paths, truncated := EnumerateImpersonationPaths(from, graph, maxDepth)
+	result.Truncated = truncated
+
+	var grants []ResourceAccessor
+	if IsPrincipal(to) {
+		for _, p := range graph.Groups.GroupPaths(from) {
+			if p[len(p)-1] == to {
+				result.Routes = append(result.Routes, Route{GroupPath: p})
+			}
+		}
+		for i, p := range paths {
+			if p.Chain[len(p.Chain)-1] == to {
+				result.Routes = append(result.Routes, Route{Impersonation: &paths[i]})
+			}
+		}
+	} else {
+		all := func(string) bool { return true }
+		grants = resourceGrants(bindings, directAccess, graph, func(id string) bool { return id == to }, all)
+		sortAccessors(grants)
+		byHolder := make(map[string][]ResourceAccessor)
+		for _, g := range grants {
+			byHolder[g.Principal] = append(byHolder[g.Principal], g)
+		}
+		for i := range byHolder[from] {
+			result.Routes = append(result.Routes, Route{Grant: &byHolder[from][i]})
+		}
+		for i, p := range paths {
+			holder := byHolder[p.Chain[len(p.Chain)-1]]
+			for j := range holder {
+				result.Routes = append(result.Routes, Route{Impersonation: &paths[i], Grant: &holder[j]})
+			}
+		}
+	}
+	if len(result.Routes) > 0 {
+		return result
+	}
+
+	// No route: what the principal can reach, and the bindings closest to the target
+	reachable := map[string]bool{from: true}
+	for _, p := range ShortestImpersonationPaths(from, graph, maxDepth) {
+		reachable[p.Chain[len(p.Chain)-1]] = true
+	}
+	for principal := range reachable {
+		if principal != from {
+			result.Reachable = append(result.Reachable, principal)
+		}
+	}
+	sort.Strings(result.Reachable)
+
+	targets := []string{to}
+	seen := make(map[string]bool)
+	for _, g := range grants {
+		if len(g.GroupPath) > 0 {
+			continue // the group's own binding is reported
+		}
+		key := g.Principal + "|" + g.Role + "|" + g.Address
+		if !seen[key] {
+			seen[key] = true
+			result.NearMisses = append(result.NearMisses, NearMiss{Principal: g.Principal, Target: to, Role: g.Role, Binding: g.Address, Reachable: reachable[g.Principal]})
+		}
+		if GetPrincipalType(g.Principal) == "serviceAccount" {
+			targets = append(targets, g.Principal)
+		}
+	}
+	for _, target := range targets {
+		for source, edges := range graph.Graph {
+			for _, e := range edges {
+				if e.Target != target {
+					continue
+				}
+				key := source + "|" + e.Role + "|" + EdgeBinding(e)
+				if !seen[key] {
+					seen[key] = true
+					result.NearMisses = append(result.NearMisses, NearMiss{Principal: source, Target: target, Role: e.Role, Binding: EdgeBinding(e), Reachable: reachable[source]})
+				}
+			}
+		}
+	}
+	sort.SliceStable(result.NearMisses, func(i, j int) bool {
+		a, b := result.NearMisses[i], result.NearMisses[j]
+		if a.Reachable != b.Reachable {
+			return a.Reachable
+		}
+		if a.Target != b.Target {
+			return a.Target == to
+		}
+		if a.Principal != b.Principal {
+			return a.Principal < b.Principal
+		}
+		return a.Role < b.Role
+	})
+	return result
+}
+
+// IsPrincipal reports whether an ID is a principal ("user:...", "serviceAccount:...") rather than a resource
+func IsPrincipal(id string) bool {
+	switch GetPrincipalType(id) {
+	case "user", "serviceAccount", "group", "domain", "principal", "principalSet":
+		return true
+	}
+	return false
+}
+
+// ResolvePrincipal returns the principal an ID names: the ID itself when it has a principal prefix, otherwise
+// the principal in directAccess whose email is the ID, or "" when there is none
+func ResolvePrincipal(id string, directAccess map[string]*PrincipalData) string {
+	if IsPrincipal(id) {
+		return id
+	}
+	var matches []string
+	for principal := range directAccess {
+		if _, email, ok := strings.Cut(principal, ":"); ok && strings.EqualFold(email, id) {
+			matches = append(matches, principal)
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Strings(matches)
+	return matches[0]
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestFindRoutes(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	bindings := []parser.IAMBinding{
+		{ResourceID: "data", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource", ParentType: "project", ParentID: "app", Role: "roles/storage.objectViewer", Members: []string{"group:devs@example.com"}, TerraformAddr: "bucket_devs"},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/storage.admin", Members: []string{"serviceAccount:deployer@app.iam.gserviceaccount.com"}, TerraformAddr: "project_deployer"},
+		{
+			ResourceID: "projects/app/serviceAccounts/deployer@app.iam.gserviceaccount.com", ResourceType: "google_service_account_iam_member", ResourceLevel: "resource",
+			Role: "roles/iam.serviceAccountTokenCreator", Members: []string{"user:alice@example.com", "serviceAccount:ci@app.iam.gserviceaccount.com"}, TerraformAddr: "deployer_creators",
+		},
+	}
+	groups := NewGroupMemberships(map[string][]string{"devs@example.com": {"alice@example.com"}})
+	graph := BuildImpersonationGraph(bindings)
+	graph.AddGroups(groups)
+	directAccess := AnalyzeWithGroups(bindings, groups)
+
+	describe := func(r Route) string {
+		var parts []string
+		parts = append(parts, r.GroupPath...)
+		if r.Impersonation != nil {
+			parts = append(parts, r.Impersonation.Chain...)
+		}
+		if r.Grant != nil {
+			parts = append(parts, r.Grant.Role+" "+r.Grant.Via)
+		}
+		return strings.Join(parts, " → ")
+	}
+
+	t.Run("resource", func(t *testing.T) {
+		result := FindRoutes("user:alice@example.com", "data", bindings, directAccess, graph, 0)
+		var got []string
+		for _, r := range result.Routes {
+			got = append(got, describe(r))
+		}
+		want := []string{
+			"roles/storage.objectViewer group",
+			"serviceAccount:deployer@app.iam.gserviceaccount.com → roles/storage.admin inherited",
+		}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("routes =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+		if len(result.NearMisses) != 0 {
+			t.Errorf("near misses reported alongside routes: %v", result.NearMisses)
+		}
+	})
+
+	t.Run("principal", func(t *testing.T) {
+		result := FindRoutes("user:alice@example.com", "group:devs@example.com", bindings, directAccess, graph, 0)
+		if len(result.Routes) != 1 || len(result.Routes[0].GroupPath) != 1 {
+			t.Errorf("expected a group membership route, got %+v", result.Routes)
+		}
+		result = FindRoutes("serviceAccount:ci@app.iam.gserviceaccount.com", "serviceAccount:deployer@app.iam.gserviceaccount.com", bindings, directAccess, graph, 0)
+		if len(result.Routes) != 1 || result.Routes[0].Impersonation == nil || result.Routes[0].Impersonation.Edges[0].SourceAddress != "deployer_creators" {
+			t.Errorf("expected an impersonation route through deployer_creators, got %+v", result.Routes)
+		}
+	})
+
+	t.Run("near misses", func(t *testing.T) {
+		result := FindRoutes("user:bob@example.com", "data", bindings, directAccess, graph, 0)
+		if len(result.Routes) != 0 {
+			t.Fatalf("bob should have no route, got %+v", result.Routes)
+		}
+		var got []string
+		for _, m := range result.NearMisses {
+			got = append(got, m.Principal+" "+m.Binding)
+		}
+		want := []string{
+			"group:devs@example.com bucket_devs",
+			"serviceAccount:deployer@app.iam.gserviceaccount.com project_deployer",
+			"serviceAccount:ci@app.iam.gserviceaccount.com deployer_creators",
+			"user:alice@example.com deployer_creators",
+		}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("near misses =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+	})
+
+	if got := ResolvePrincipal("alice@example.com", directAccess); got != "user:alice@example.com" {
+		t.Errorf("ResolvePrincipal() = %q, want user:alice@example.com", got)
+	}
+}
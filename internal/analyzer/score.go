@@ -0,0 +1,363 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// ScoreWeights tunes how much each factor adds to a blast-radius score
+type ScoreWeights struct {
+	AccessLevels      map[string]float64 `json:"access_levels"`      // access_level of a role (read, write, admin, impersonate) → weight of a grant
+	Scopes            map[string]float64 `json:"scopes"`             // level a grant is made on (organization, folder, project, resource) → multiplier
+	ReachableResource float64            `json:"reachable_resource"` // added per resource reached
+	Sensitive         float64            `json:"sensitive"`          // multiplier for grants on sensitive resources
+	Impersonation     float64            `json:"impersonation"`      // added per service account the principal can impersonate
+	External          float64            `json:"external"`           // added for external principals, and per external principal reaching a resource
+	Public            float64            `json:"public"`             // added for allUsers and allAuthenticatedUsers, and for resources they reach
+}
+
+// DefaultScoreWeights returns the weights used when the config sets none
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{
+		AccessLevels:      map[string]float64{"read": 1, "write": 3, "impersonate": 4, "admin": 5},
+		Scopes:            map[string]float64{"organization": 8, "folder": 4, "project": 2, "resource": 1},
+		ReachableResource: 0.5,
+		Sensitive:         2,
+		Impersonation:     3,
+		External:          5,
+		Public:            10,
+	}
+}
+
+// SensitivityTag marks the resources whose ID matches a regex as sensitive
+type SensitivityTag struct {
+	Resource string // Regex matched against resource IDs; "*" matches all
+	Tag      string // Label reported for the resources, e.g. "pii"
+}
+
+// ScoreBreakdown is how much each factor adds to a principal's score
+type ScoreBreakdown struct {
+	Access        float64 `json:"access"`        // grants, by access level, scope and sensitivity
+	Breadth       float64 `json:"breadth"`       // resources reached
+	Impersonation float64 `json:"impersonation"` // service accounts reached
+	External      float64 `json:"external,omitempty"`
+	Public        float64 `json:"public,omitempty"`
+}
+
+// PrincipalScore is the blast-radius score of a principal: what it can reach, directly or by impersonation
+type PrincipalScore struct {
+	Principal          string         `json:"principal"`
+	Score              float64        `json:"score"`
+	Breakdown          ScoreBreakdown `json:"breakdown"`
+	TopAccessLevel     string         `json:"top_access_level,omitempty"` // highest-weighted access level held
+	TopScope           string         `json:"top_scope,omitempty"`        // broadest level a grant is held on
+	Resources          int            `json:"resources"`                  // resources reached
+	Accounts           int            `json:"accounts"`                   // service accounts it can impersonate
+	SensitiveResources []string       `json:"sensitive_resources,omitempty"`
+	External           bool           `json:"external,omitempty"`
+	Public             bool           `json:"public,omitempty"`
+}
+
+// ResourceScore is the exposure score of a resource: who can reach it, and how
+type ResourceScore struct {
+	ResourceID     string   `json:"resource_id"`
+	ResourceType   string   `json:"resource_type"`
+	Score          float64  `json:"score"`
+	TopAccessLevel string   `json:"top_access_level,omitempty"` // highest-weighted access level any principal holds
+	Principals     int      `json:"principals"`                 // principals that reach it
+	External       int      `json:"external,omitempty"`         // external principals that reach it
+	Public         bool     `json:"public,omitempty"`           // reached by allUsers or allAuthenticatedUsers
+	Tags           []string `json:"tags,omitempty"`             // sensitivity tags
+}
+
+// Scorer scores the principals and resources of an analysis
+type Scorer struct {
+	weights    ScoreWeights
+	principals map[string]*PrincipalScore
+	resources  map[string]*ResourceScore
+}
+
+type sensitivityMatcher struct {
+	re  *regexp.Regexp // nil matches all
+	tag string
+}
+
+// NewScorer scores every principal in directAccess and every resource they reach. A principal's grants are its own,
+// through groups and inherited from projects, folders and organizations, and those of every service account it can
+// impersonate. directAccess should come from AnalyzeWithGroups, and graph should carry the groups and hierarchy of
+// the bindings. An error is returned for a tag whose pattern is not a valid regex.
+func NewScorer(weights ScoreWeights, tags []SensitivityTag, bindings []parser.IAMBinding, directAccess map[string]*PrincipalData, graph *ImpersonationGraph) (*Scorer, error) {
+	var matchers []sensitivityMatcher
+	for _, t := range tags {
+		m := sensitivityMatcher{tag: t.Tag}
+		if m.tag == "" {
+			m.tag = "sensitive"
+		}
+		if t.Resource != "*" {
+			re, err := regexp.Compile(t.Resource)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sensitive resource pattern %q: %v", t.Resource, err)
+			}
+			m.re = re
+		}
+		matchers = append(matchers, m)
+	}
+	tagsOf := func(resourceID string) []string {
+		var found []string
+		for _, m := range matchers {
+			if (m.re == nil || m.re.MatchString(resourceID)) && !containsString(found, m.tag) {
+				found = append(found, m.tag)
+			}
+		}
+		sort.Strings(found)
+		return found
+	}
+
+	all := func(string) bool { return true }
+	grants := make(map[string][]ResourceAccessor)
+	for _, a := range resourceGrants(bindings, directAccess, graph, all, all) {
+		grants[a.Principal] = append(grants[a.Principal], a)
+	}
+
+	s := &Scorer{weights: weights, principals: make(map[string]*PrincipalScore), resources: make(map[string]*ResourceScore)}
+	type reach struct {
+		weight float64 // highest grant weight before sensitivity
+		level  string
+	}
+	resourceReach := make(map[string]map[string]reach) // resource → principal → reach
+
+	for principal := range directAccess {
+		ps := &PrincipalScore{Principal: principal, External: isExternal(principal), Public: isPublic(principal)}
+		accounts := reachableAccounts(principal, graph)
+		ps.Accounts = len(accounts)
+
+		best := make(map[string]reach)
+		for _, holder := range append([]string{principal}, accounts...) {
+			for _, a := range grants[holder] {
+				level := roleAccessLevel(a.Role)
+				scope := grantScope(a)
+				w := weights.AccessLevels[level] * weights.Scopes[scope]
+				if r, ok := best[a.ResourceID]; !ok || w > r.weight {
+					best[a.ResourceID] = reach{weight: w, level: level}
+				}
+				if ps.TopScope == "" || hierarchyOrder[scope] < hierarchyOrder[ps.TopScope] {
+					ps.TopScope = scope
+				}
+				if ps.TopAccessLevel == "" || weights.AccessLevels[level] > weights.AccessLevels[ps.TopAccessLevel] {
+					ps.TopAccessLevel = level
+				}
+				if rs, ok := s.resources[a.ResourceID]; !ok {
+					s.resources[a.ResourceID] = &ResourceScore{ResourceID: a.ResourceID, ResourceType: a.ResourceType, Tags: tagsOf(a.ResourceID)}
+				} else if a.ResourceType < rs.ResourceType {
+					rs.ResourceType = a.ResourceType // the same for every run, whatever the order grants are seen in
+				}
+			}
+		}
+
+		for resID, r := range best {
+			w := r.weight
+			if len(s.resources[resID].Tags) > 0 {
+				w *= weights.Sensitive
+				ps.SensitiveResources = append(ps.SensitiveResources, resID)
+			}
+			ps.Breakdown.Access += w
+			if resourceReach[resID] == nil {
+				resourceReach[resID] = make(map[string]reach)
+			}
+			resourceReach[resID][principal] = r
+		}
+		sort.Strings(ps.SensitiveResources)
+		ps.Resources = len(best)
+		ps.Breakdown.Breadth = float64(ps.Resources) * weights.ReachableResource
+		ps.Breakdown.Impersonation = float64(ps.Accounts) * weights.Impersonation
+		if ps.External {
+			ps.Breakdown.External = weights.External
+		}
+		if ps.Public {
+			ps.Breakdown.Public = weights.Public
+		}
+		ps.Breakdown = roundBreakdown(ps.Breakdown)
+		ps.Score = roundScore(ps.Breakdown.Access + ps.Breakdown.Breadth + ps.Breakdown.Impersonation + ps.Breakdown.External + ps.Breakdown.Public)
+		s.principals[principal] = ps
+	}
+
+	for resID, rs := range s.resources {
+		total := 0.0
+		for principal, r := range resourceReach[resID] {
+			total += r.weight
+			if rs.TopAccessLevel == "" || weights.AccessLevels[r.level] > weights.AccessLevels[rs.TopAccessLevel] {
+				rs.TopAccessLevel = r.level
+			}
+			if isExternal(principal) {
+				rs.External++
+			}
+			if isPublic(principal) {
+				rs.Public = true
+			}
+		}
+		if len(rs.Tags) > 0 {
+			total *= weights.Sensitive
+		}
+		total += float64(rs.External) * weights.External
+		if rs.Public {
+			total += weights.Public
+		}
+		rs.Principals = len(resourceReach[resID])
+		rs.Score = roundScore(total)
+	}
+	return s, nil
+}
+
+// Principal returns the score of a principal; principals without access score zero
+func (s *Scorer) Principal(principal string) PrincipalScore {
+	if s == nil || s.principals[principal] == nil {
+		return PrincipalScore{Principal: principal}
+	}
+	return *s.principals[principal]
+}
+
+// Resource returns the score of a resource; resources no principal reaches score zero
+func (s *Scorer) Resource(resourceID string) ResourceScore {
+	if s == nil || s.resources[resourceID] == nil {
+		return ResourceScore{ResourceID: resourceID}
+	}
+	return *s.resources[resourceID]
+}
+
+// Weights returns the weights the scores were computed with
+func (s *Scorer) Weights() ScoreWeights {
+	if s == nil {
+		return ScoreWeights{}
+	}
+	return s.weights
+}
+
+// RankPrincipals returns every principal's score, highest first, ties by principal
+func (s *Scorer) RankPrincipals() []PrincipalScore {
+	if s == nil {
+		return nil
+	}
+	ranked := make([]PrincipalScore, 0, len(s.principals))
+	for _, ps := range s.principals {
+		ranked = append(ranked, *ps)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Principal < ranked[j].Principal
+	})
+	return ranked
+}
+
+// RankResources returns every resource's score, highest first, ties by resource ID
+func (s *Scorer) RankResources() []ResourceScore {
+	if s == nil {
+		return nil
+	}
+	ranked := make([]ResourceScore, 0, len(s.resources))
+	for _, rs := range s.resources {
+		ranked = append(ranked, *rs)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].ResourceID < ranked[j].ResourceID
+	})
+	return ranked
+}
+
+// roleAccessLevel returns the access_level the rules give a role; roles without rules are classed as
+// impersonate when they grant impersonation, otherwise guessed from the role name
+func roleAccessLevel(role string) string {
+	if _, h := definitions.MatchRoleHierarchy(role); h != nil && h.AccessLevel != "" {
+		return h.AccessLevel
+	}
+	if definitions.GrantsImpersonation(role) {
+		return "impersonate"
+	}
+	return suggestRoleHierarchy(role).AccessLevel
+}
+
+// grantScope returns the level a grant is made on: the scope it is inherited from, or the level of its binding
+func grantScope(a ResourceAccessor) string {
+	if a.InheritedFrom != nil {
+		return a.InheritedFrom.Type
+	}
+	switch {
+	case strings.HasPrefix(a.ResourceType, "google_organization_iam"):
+		return "organization"
+	case strings.HasPrefix(a.ResourceType, "google_folder_iam"):
+		return "folder"
+	case strings.HasPrefix(a.ResourceType, "google_project_iam"):
+		return "project"
+	}
+	return "resource"
+}
+
+// reachableAccounts returns the service accounts a principal can impersonate, directly or through a chain,
+// following the grants of the groups each account belongs to and passing over hops deny policies revoke
+func reachableAccounts(principal string, graph *ImpersonationGraph) []string {
+	visited := map[string]bool{principal: true}
+	queue := []string{principal}
+	var accounts []string
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		sources := []string{current}
+		for _, path := range graph.Groups.GroupPaths(current) {
+			sources = append(sources, path[len(path)-1])
+		}
+		for _, source := range sources {
+			for _, target := range graph.Targets(source) {
+				if visited[target] {
+					continue
+				}
+				if _, _, ok := graph.allowedEdge(current, source, target); !ok {
+					continue
+				}
+				visited[target] = true
+				accounts = append(accounts, target)
+				queue = append(queue, target)
+			}
+		}
+	}
+	sort.Strings(accounts)
+	return accounts
+}
+
+// isExternal reports whether a principal is outside the organization's own identities:
+// a federated or GKE workload identity, or a whole domain
+func isExternal(principal string) bool {
+	if _, ok := ParseExternalIdentity(principal); ok {
+		return true
+	}
+	return GetPrincipalType(principal) == "domain"
+}
+
+// isPublic reports whether a principal stands for anyone on the internet
+func isPublic(principal string) bool {
+	return principal == "allUsers" || principal == "allAuthenticatedUsers"
+}
+
+func roundBreakdown(b ScoreBreakdown) ScoreBreakdown {
+	return ScoreBreakdown{
+		Access:        roundScore(b.Access),
+		Breadth:       roundScore(b.Breadth),
+		Impersonation: roundScore(b.Impersonation),
+		External:      roundScore(b.External),
+		Public:        roundScore(b.Public),
+	}
+}
+
+// roundScore rounds a score to one decimal
+func roundScore(score float64) float64 {
+	return math.Round(score*10) / 10
+}
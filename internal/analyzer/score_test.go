@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestScorer(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	bindings := []parser.IAMBinding{
+		{ResourceID: "prod-data", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource", Role: "roles/storage.admin", Members: []string{"serviceAccount:deployer@app.iam.gserviceaccount.com"}, TerraformAddr: "deployer_admin"},
+		{ResourceID: "prod-data", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource", Role: "roles/storage.objectViewer", Members: []string{"allUsers"}, TerraformAddr: "public_read"},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/viewer", Members: []string{"domain:partner.com"}, TerraformAddr: "partner_viewer"},
+		{
+			ResourceID: "projects/app/serviceAccounts/deployer@app.iam.gserviceaccount.com", ResourceType: "google_service_account_iam_member", ResourceLevel: "resource",
+			Role: "roles/iam.serviceAccountTokenCreator", Members: []string{"user:alice@example.com"}, TerraformAddr: "alice_deployer",
+		},
+	}
+	graph := BuildImpersonationGraph(bindings)
+	directAccess := AnalyzeWithGroups(bindings, nil)
+	tags := []SensitivityTag{{Resource: "^prod-", Tag: "pii"}}
+
+	scorer, err := NewScorer(DefaultScoreWeights(), tags, bindings, directAccess, graph)
+	if err != nil {
+		t.Fatalf("NewScorer() error = %v", err)
+	}
+
+	// alice: impersonate (4) on the deployer, admin (5) on the sensitive bucket (x2), two resources, one account
+	alice := scorer.Principal("user:alice@example.com")
+	if alice.Score != 4+10+1+3 || alice.Accounts != 1 || alice.Resources != 2 || alice.TopAccessLevel != "admin" {
+		t.Errorf("alice = %+v, want score 18 over 2 resources and 1 account", alice)
+	}
+	if len(alice.SensitiveResources) != 1 || alice.SensitiveResources[0] != "prod-data" {
+		t.Errorf("alice sensitive resources = %v, want [prod-data]", alice.SensitiveResources)
+	}
+
+	// partner.com: read (1) on a project (x2), external
+	partner := scorer.Principal("domain:partner.com")
+	if partner.Score != 2+0.5+5 || !partner.External || partner.TopScope != "project" {
+		t.Errorf("partner = %+v, want external score 7.5 on project scope", partner)
+	}
+	public := scorer.Principal("allUsers")
+	if !public.Public || public.Breakdown.Public != 10 {
+		t.Errorf("allUsers = %+v, want public", public)
+	}
+
+	// prod-data: admin held by the deployer and alice, read by allUsers, sensitive, public
+	data := scorer.Resource("prod-data")
+	if data.Score != (5+5+1)*2+10 || data.Principals != 3 || !data.Public || len(data.Tags) != 1 {
+		t.Errorf("prod-data = %+v, want score 32 from 3 principals", data)
+	}
+
+	ranked := scorer.RankPrincipals()
+	if len(ranked) != 4 || ranked[0].Principal != "user:alice@example.com" {
+		t.Errorf("RankPrincipals() first = %+v, want alice", ranked[0])
+	}
+	if ranked := scorer.RankResources(); ranked[0].ResourceID != "prod-data" {
+		t.Errorf("RankResources() first = %+v, want prod-data", ranked[0])
+	}
+
+	if _, err := NewScorer(DefaultScoreWeights(), []SensitivityTag{{Resource: "prod-("}}, bindings, directAccess, graph); err == nil {
+		t.Error("NewScorer() expected an error for an invalid pattern")
+	}
+}
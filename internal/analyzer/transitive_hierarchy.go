@@ -0,0 +1,127 @@
+package analyzer
+
+import "sort"
+
+// HierarchicalVia is project, folder or organization access obtained through impersonation
+type HierarchicalVia struct {
+	Scope         Scope
+	ResourceTypes map[string]bool           // Resource types the access reaches below the scope ("*" for all)
+	Entries       []HierarchicalAccessEntry // The impersonated account's hierarchy entries on the scope, when the graph has a hierarchy analysis
+	ViaChain      []string                  // Chain of impersonation, as in AccessVia
+	ViaEdges      []ImpersonationEdge       // The edge behind each hop of ViaChain
+	ChainType     string                    // One of the Chain* constants
+}
+
+// Roles returns the roles behind the access, sorted
+func (h *HierarchicalVia) Roles() []string {
+	seen := make(map[string]bool)
+	var roles []string
+	for _, e := range h.Entries {
+		if !seen[e.Role] {
+			seen[e.Role] = true
+			roles = append(roles, e.Role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// AddHierarchy records the hierarchy analysis of the bindings the graph was built from, so transitive
+// analyses carry the project, folder and organization entries of every impersonated account
+func (g *ImpersonationGraph) AddHierarchy(result *HierarchyAnalysisResult) {
+	g.Hierarchy = result
+}
+
+// hierarchyEntries returns the hierarchy entries of a principal and of the groups it belongs to
+func (g *ImpersonationGraph) hierarchyEntries(principal string) []HierarchicalAccessEntry {
+	if g.Hierarchy == nil {
+		return nil
+	}
+	holders := map[string]bool{principal: true}
+	for _, path := range g.Groups.GroupPaths(principal) {
+		holders[path[len(path)-1]] = true
+	}
+	var entries []HierarchicalAccessEntry
+	for _, e := range g.Hierarchy.HierarchicalAccess {
+		if holders[e.Principal] {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// mergeTransitiveHierarchy adds the project, folder and organization access of an impersonated account
+// that the analyzed principal does not already hold. A scope keeps the first (shortest) chain that reaches it.
+func mergeTransitiveHierarchy(result *TransitiveAccess, graph *ImpersonationGraph, target string, directAccess map[string]*PrincipalData, chain []string, edges []ImpersonationEdge) {
+	reached := make(map[string]*HierarchicalVia)
+	via := func(scope Scope) *HierarchicalVia {
+		key := scopeKey(scope.Type, scope.ID)
+		if _, taken := result.HierarchicalAccess[key]; taken {
+			return nil
+		}
+		if reached[key] == nil {
+			reached[key] = &HierarchicalVia{
+				Scope:         scope,
+				ResourceTypes: make(map[string]bool),
+				ViaChain:      chain,
+				ViaEdges:      edges,
+				ChainType:     classifyChain(edges),
+			}
+		}
+		return reached[key]
+	}
+
+	if targetAccess, ok := directAccess[target]; ok {
+		for project, types := range targetAccess.HierarchicalAccess {
+			for rt := range types {
+				if result.DirectAccess != nil && result.DirectAccess.HierarchicalAccess[project][rt] {
+					continue
+				}
+				if v := via(Scope{Type: "project", ID: project}); v != nil {
+					v.ResourceTypes[rt] = true
+				}
+			}
+		}
+	}
+
+	held := make(map[string]bool)
+	for _, e := range graph.hierarchyEntries(result.Principal) {
+		held[scopeKey(e.Scope.Type, e.Scope.ID)+"|"+e.Role] = true
+	}
+	for _, e := range graph.hierarchyEntries(target) {
+		if held[scopeKey(e.Scope.Type, e.Scope.ID)+"|"+e.Role] {
+			continue
+		}
+		v := via(e.Scope)
+		if v == nil {
+			continue
+		}
+		v.Entries = append(v.Entries, e)
+		for _, rt := range e.Grants.ResourceTypes {
+			v.ResourceTypes[rt] = true
+		}
+	}
+
+	for key, v := range reached {
+		if len(v.ResourceTypes) > 0 || len(v.Entries) > 0 {
+			result.HierarchicalAccess[key] = v
+		}
+	}
+}
+
+// SortedHierarchicalAccess returns the hierarchical access gained through impersonation,
+// organizations first, then folders and projects, each sorted by ID
+func (t *TransitiveAccess) SortedHierarchicalAccess() []*HierarchicalVia {
+	vias := make([]*HierarchicalVia, 0, len(t.HierarchicalAccess))
+	for _, v := range t.HierarchicalAccess {
+		vias = append(vias, v)
+	}
+	sort.Slice(vias, func(i, j int) bool {
+		a, b := vias[i].Scope, vias[j].Scope
+		if a.Type != b.Type {
+			return hierarchyOrder[a.Type] < hierarchyOrder[b.Type]
+		}
+		return a.ID < b.ID
+	})
+	return vias
+}
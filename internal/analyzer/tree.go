@@ -0,0 +1,230 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// Terraform resource types that declare hierarchy nodes
+const (
+	ProjectType = "google_project"
+	FolderType  = "google_folder"
+)
+
+// HierarchyResourceTypes are the resource types FindHierarchyNodes needs parsed
+var HierarchyResourceTypes = []string{ProjectType, FolderType}
+
+// ResourceTree is the organization, folder and project tree reconstructed from the parents
+// recorded on IAM bindings and from google_folder and google_project declarations
+type ResourceTree struct {
+	nodes    map[string]*HierarchyNode // scope key → node
+	children map[string][]string       // scope key → child scope keys
+}
+
+// NewResourceTree returns an empty tree
+func NewResourceTree() *ResourceTree {
+	return &ResourceTree{
+		nodes:    make(map[string]*HierarchyNode),
+		children: make(map[string][]string),
+	}
+}
+
+// BuildResourceTree builds the tree of the organizations, folders and projects bindings are granted on
+func BuildResourceTree(bindings []parser.IAMBinding) *ResourceTree {
+	tree := NewResourceTree()
+	tree.AddBindings(bindings)
+	return tree
+}
+
+// AddBindings adds the organizations, folders and projects bindings are granted on, with the parents they record
+func (t *ResourceTree) AddBindings(bindings []parser.IAMBinding) {
+	for _, b := range bindings {
+		if isHierarchyLevel(b.ResourceLevel) {
+			t.AddNode(HierarchyNode{ID: b.ResourceID, Type: b.ResourceLevel, ParentID: b.ParentID, ParentType: b.ParentType})
+		}
+	}
+}
+
+// AddNode adds a node, or fills in the parent of a node already in the tree that has none.
+// The parent is added as a node of its own.
+func (t *ResourceTree) AddNode(node HierarchyNode) {
+	key := scopeKey(node.Type, node.ID)
+	existing, ok := t.nodes[key]
+	if !ok {
+		existing = &HierarchyNode{ID: node.ID, Type: node.Type}
+		t.nodes[key] = existing
+	}
+	if existing.ParentID != "" || node.ParentID == "" || node.ParentType == "" {
+		return
+	}
+	parentKey := scopeKey(node.ParentType, node.ParentID)
+	if parentKey == key || containsString(t.ancestors(parentKey), key) {
+		return // would close a cycle
+	}
+	existing.ParentID, existing.ParentType = node.ParentID, node.ParentType
+	t.children[parentKey] = append(t.children[parentKey], key)
+	if _, ok := t.nodes[parentKey]; !ok {
+		t.nodes[parentKey] = &HierarchyNode{ID: node.ParentID, Type: node.ParentType}
+	}
+}
+
+// Node returns the node of a scope key
+func (t *ResourceTree) Node(scope string) (HierarchyNode, bool) {
+	node, ok := t.nodes[scope]
+	if !ok {
+		return HierarchyNode{}, false
+	}
+	return *node, true
+}
+
+// Nodes returns every node, sorted by type then ID
+func (t *ResourceTree) Nodes() []HierarchyNode {
+	nodes := make([]HierarchyNode, 0, len(t.nodes))
+	for _, node := range t.nodes {
+		nodes = append(nodes, *node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Type != nodes[j].Type {
+			return nodes[i].Type < nodes[j].Type
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+	return nodes
+}
+
+// HasParent reports whether the parent of a scope is known
+func (t *ResourceTree) HasParent(scope string) bool {
+	node, ok := t.nodes[scope]
+	return ok && node.ParentID != ""
+}
+
+// Parents returns scope key → parent scope key for every node with a known parent, in the form of buildParentMap
+func (t *ResourceTree) Parents() map[string]string {
+	parents := make(map[string]string)
+	for key, node := range t.nodes {
+		if node.ParentID != "" {
+			parents[key] = scopeKey(node.ParentType, node.ParentID)
+		}
+	}
+	return parents
+}
+
+// ancestors returns the scope followed by its ancestors, nearest first
+func (t *ResourceTree) ancestors(scope string) []string {
+	scopes := []string{scope}
+	for {
+		node, ok := t.nodes[scope]
+		if !ok || node.ParentID == "" {
+			return scopes
+		}
+		scope = scopeKey(node.ParentType, node.ParentID)
+		if containsString(scopes, scope) {
+			return scopes
+		}
+		scopes = append(scopes, scope)
+	}
+}
+
+// Descendants returns, for every node below a scope, the path of scopes leading to it from the scope,
+// outermost first and including both ends. Paths are listed breadth first, in sorted order at each level.
+// Example: organization:1 → [[organization:1 folder:10] [organization:1 folder:10 project:app]]
+func (t *ResourceTree) Descendants(scope string) [][]Scope {
+	if _, ok := t.nodes[scope]; !ok {
+		return nil
+	}
+
+	var paths [][]Scope
+	queue := [][]string{{scope}}
+	visited := map[string]bool{scope: true}
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+
+		children := append([]string{}, t.children[path[len(path)-1]]...)
+		sort.Strings(children)
+		for _, child := range children {
+			if visited[child] {
+				continue
+			}
+			visited[child] = true
+			next := append(append([]string{}, path...), child)
+			queue = append(queue, next)
+
+			scopes := make([]Scope, len(next))
+			for i, key := range next {
+				node := t.nodes[key]
+				scopes[i] = Scope{Type: node.Type, ID: node.ID}
+			}
+			paths = append(paths, scopes)
+		}
+	}
+	return paths
+}
+
+// FindHierarchyNodes returns the projects and folders declared by google_project and google_folder resources,
+// with their parent when it is set directly or through a reference to a declared folder
+// (e.g. folder_id = google_folder.team.name). A folder's ID is only known once it exists (e.g. in a plan of
+// already created folders); until then the folder is identified by its Terraform address.
+func FindHierarchyNodes(resources []parser.ResourceInstance) []HierarchyNode {
+	folderIDs := make(map[string]string) // address → folder ID
+	for _, r := range resources {
+		if r.Type == FolderType && r.Address != "" {
+			folderIDs[r.Address] = declaredFolderID(r)
+		}
+	}
+	// referencedFolder returns the ID of the folder an attribute refers to, or ""
+	referencedFolder := func(r parser.ResourceInstance, attr string) string {
+		ref := r.References[attr]
+		if id, ok := folderIDs[ref]; ok {
+			return id
+		}
+		if strings.HasPrefix(ref, FolderType+".") {
+			return ref
+		}
+		return ""
+	}
+
+	var nodes []HierarchyNode
+	for _, r := range resources {
+		var node HierarchyNode
+		switch r.Type {
+		case ProjectType:
+			node = HierarchyNode{ID: r.Values["project_id"], Type: "project"}
+			if folder := r.Values["folder_id"]; folder != "" {
+				node.ParentID, node.ParentType = strings.TrimPrefix(folder, "folders/"), "folder"
+			} else if folder := referencedFolder(r, "folder_id"); folder != "" {
+				node.ParentID, node.ParentType = folder, "folder"
+			} else if org := r.Values["org_id"]; org != "" {
+				node.ParentID, node.ParentType = strings.TrimPrefix(org, "organizations/"), "organization"
+			}
+		case FolderType:
+			node = HierarchyNode{ID: declaredFolderID(r), Type: "folder"}
+			if parent := r.Values["parent"]; parent != "" {
+				node.ParentType = parser.DetermineParentType("folder", parent)
+				node.ParentID = strings.TrimPrefix(strings.TrimPrefix(parent, "folders/"), "organizations/")
+			} else if folder := referencedFolder(r, "parent"); folder != "" {
+				node.ParentID, node.ParentType = folder, "folder"
+			}
+		default:
+			continue
+		}
+		if node.ID != "" {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// declaredFolderID returns the ID of a google_folder, or its Terraform address when the ID is not known yet
+func declaredFolderID(r parser.ResourceInstance) string {
+	id := r.Values["folder_id"]
+	if id == "" {
+		id = r.Values["name"]
+	}
+	if id == "" {
+		return r.Address
+	}
+	return strings.TrimPrefix(id, "folders/")
+}
@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestResourceTree_Descendants(t *testing.T) {
+	tree := NewResourceTree()
+	tree.AddNode(HierarchyNode{ID: "app", Type: "project", ParentID: "22", ParentType: "folder"})
+	tree.AddNode(HierarchyNode{ID: "folders/22", Type: "folder", ParentID: "111", ParentType: "organization"})
+	tree.AddNode(HierarchyNode{ID: "tools", Type: "project", ParentID: "111", ParentType: "organization"})
+	// A later parent for a node that already has one is ignored, as is one that would close a cycle
+	tree.AddNode(HierarchyNode{ID: "app", Type: "project", ParentID: "111", ParentType: "organization"})
+	tree.AddNode(HierarchyNode{ID: "111", Type: "organization", ParentID: "22", ParentType: "folder"})
+
+	want := [][]Scope{
+		{{Type: "organization", ID: "111"}, {Type: "folder", ID: "22"}},
+		{{Type: "organization", ID: "111"}, {Type: "project", ID: "tools"}},
+		{{Type: "organization", ID: "111"}, {Type: "folder", ID: "22"}, {Type: "project", ID: "app"}},
+	}
+	if got := tree.Descendants("organization:111"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Descendants(organization:111) = %v, want %v", got, want)
+	}
+	if tree.HasParent("organization:111") {
+		t.Errorf("organization:111 should have no parent")
+	}
+	if got := tree.Descendants("project:app"); got != nil {
+		t.Errorf("Descendants(project:app) = %v, want nil", got)
+	}
+}
+
+func TestFindHierarchyNodes(t *testing.T) {
+	resources := []parser.ResourceInstance{
+		{Type: ProjectType, Values: map[string]string{"project_id": "app", "folder_id": "folders/22"}},
+		{Type: ProjectType, Values: map[string]string{"project_id": "tools", "org_id": "111"}},
+		{Type: FolderType, Values: map[string]string{"name": "folders/22", "parent": "organizations/111"}},
+		{Type: FolderType, Values: map[string]string{"parent": "organizations/111"}}, // not created yet
+	}
+	want := []HierarchyNode{
+		{ID: "app", Type: "project", ParentID: "22", ParentType: "folder"},
+		{ID: "tools", Type: "project", ParentID: "111", ParentType: "organization"},
+		{ID: "22", Type: "folder", ParentID: "111", ParentType: "organization"},
+	}
+	if got := FindHierarchyNodes(resources); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindHierarchyNodes() = %v, want %v", got, want)
+	}
+}
+
+func TestFindHierarchyNodes_References(t *testing.T) {
+	// Folders created alongside the projects have no ID yet; references to them are resolved
+	// to their address so the tree still links them
+	resources := []parser.ResourceInstance{
+		{Type: FolderType, Address: "google_folder.eng", Values: map[string]string{"parent": "organizations/111"}},
+		{Type: FolderType, Address: "google_folder.team", Values: map[string]string{}, References: map[string]string{"parent": "google_folder.eng"}},
+		{Type: FolderType, Address: "google_folder.ops", Values: map[string]string{"name": "folders/33"}, References: map[string]string{"parent": "google_folder.eng"}},
+		{Type: ProjectType, Address: "google_project.app", Values: map[string]string{"project_id": "app"}, References: map[string]string{"folder_id": "google_folder.team"}},
+		{Type: ProjectType, Address: "google_project.jobs", Values: map[string]string{"project_id": "jobs"}, References: map[string]string{"folder_id": "google_folder.ops"}},
+	}
+	want := []HierarchyNode{
+		{ID: "google_folder.eng", Type: "folder", ParentID: "111", ParentType: "organization"},
+		{ID: "google_folder.team", Type: "folder", ParentID: "google_folder.eng", ParentType: "folder"},
+		{ID: "33", Type: "folder", ParentID: "google_folder.eng", ParentType: "folder"},
+		{ID: "app", Type: "project", ParentID: "google_folder.team", ParentType: "folder"},
+		{ID: "jobs", Type: "project", ParentID: "33", ParentType: "folder"},
+	}
+	if got := FindHierarchyNodes(resources); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindHierarchyNodes() = %v, want %v", got, want)
+	}
+}
+
+func TestFindHierarchyNodes_Plan(t *testing.T) {
+	// The folder is created by the plan, so the project's folder_id is unknown; the configuration names the folder
+	resources := parsePlanResources(t, HierarchyResourceTypes, []parser.Resource{
+		{Address: "google_folder.team", Mode: "managed", Type: FolderType, Values: map[string]interface{}{"parent": "organizations/111"}},
+		{Address: "google_project.app", Mode: "managed", Type: ProjectType, Values: map[string]interface{}{"project_id": "app"}},
+	}, []parser.PlanConfigResource{
+		{
+			Address: "google_project.app", Mode: "managed",
+			Expressions: map[string]interface{}{"folder_id": map[string]interface{}{"references": []interface{}{"google_folder.team.name", "google_folder.team"}}},
+		},
+	})
+
+	want := []HierarchyNode{
+		{ID: "google_folder.team", Type: "folder", ParentID: "111", ParentType: "organization"},
+		{ID: "app", Type: "project", ParentID: "google_folder.team", ParentType: "folder"},
+	}
+	if got := FindHierarchyNodes(resources); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindHierarchyNodes() = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzeHierarchyWithTree_Inherited(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	bindings := []parser.IAMBinding{
+		{ResourceID: "111", ResourceType: "google_organization_iam_member", ResourceLevel: "organization", Role: "roles/storage.objectViewer", Members: []string{"user:alice@example.com"}},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/bigquery.dataViewer", Members: []string{"user:bob@example.com"}},
+	}
+	tree := NewResourceTree()
+	tree.AddNode(HierarchyNode{ID: "22", Type: "folder", ParentID: "111", ParentType: "organization"})
+	tree.AddNode(HierarchyNode{ID: "app", Type: "project", ParentID: "22", ParentType: "folder"})
+
+	result := AnalyzeHierarchyWithTree(bindings, tree)
+
+	if len(result.Unknown) != 0 {
+		t.Errorf("Unknown = %v, want none: app's parent comes from the tree", result.Unknown)
+	}
+
+	var inherited []HierarchicalAccessEntry
+	for _, entry := range result.HierarchicalAccess {
+		if entry.InheritedFrom != nil {
+			inherited = append(inherited, entry)
+		}
+		if entry.Principal == "user:bob@example.com" && !entry.HierarchyKnown {
+			t.Errorf("bob's grant on app should have a known hierarchy")
+		}
+	}
+	if len(inherited) != 2 {
+		t.Fatalf("got %d inherited entries, want 2 (folder 22 and project app): %+v", len(inherited), inherited)
+	}
+	app := inherited[1]
+	wantPath := []Scope{{Type: "organization", ID: "111"}, {Type: "folder", ID: "22"}, {Type: "project", ID: "app"}}
+	if app.Scope != (Scope{Type: "project", ID: "app"}) || *app.InheritedFrom != (Scope{Type: "organization", ID: "111"}) || !reflect.DeepEqual(app.Path, wantPath) {
+		t.Errorf("inherited entry = %+v, want alice's org grant on app through folder 22", app)
+	}
+	if !reflect.DeepEqual(app.Grants.AffectedLevels, []string{"resource"}) {
+		t.Errorf("AffectedLevels = %v, want [resource]", app.Grants.AffectedLevels)
+	}
+}
+
+func TestParentMap(t *testing.T) {
+	bindings := []parser.IAMBinding{
+		{ResourceID: "data", ResourceLevel: "resource", ParentID: "app", ParentType: "project"},
+		{ResourceID: "tools", ResourceLevel: "project", ParentID: "33", ParentType: "folder"},
+		{ResourceID: "app", ResourceLevel: "project"},
+	}
+	tree := NewResourceTree()
+	tree.AddNode(HierarchyNode{ID: "app", Type: "project", ParentID: "22", ParentType: "folder"})
+	tree.AddNode(HierarchyNode{ID: "tools", Type: "project", ParentID: "44", ParentType: "folder"})
+	tree.AddNode(HierarchyNode{ID: "22", Type: "folder", ParentID: "111", ParentType: "organization"})
+
+	// Parents the bindings record win over the tree's
+	want := map[string]string{
+		"resource:data": "project:app",
+		"project:tools": "folder:33",
+		"project:app":   "folder:22",
+		"folder:22":     "organization:111",
+	}
+	if got := ParentMap(bindings, tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParentMap() = %v, want %v", got, want)
+	}
+	if got := ParentMap(bindings, nil); len(got) != 2 {
+		t.Errorf("ParentMap(nil tree) = %v, want the 2 parents of the bindings", got)
+	}
+}
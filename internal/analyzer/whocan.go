@@ -0,0 +1,191 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// Ways a principal can reach a resource, in the order who-can reports them
+const (
+	AccessDirect        = "direct"        // a binding on the resource names the principal
+	AccessGroup         = "group"         // a binding on the resource names a group the principal belongs to
+	AccessInherited     = "inherited"     // a binding on the resource's project, folder or organization
+	AccessImpersonation = "impersonation" // the principal can impersonate a service account with access
+)
+
+var accessOrder = map[string]int{AccessDirect: 0, AccessGroup: 1, AccessInherited: 2, AccessImpersonation: 3}
+
+// ResourceAccessor is a principal that holds a role on a resource, and how it gets it
+type ResourceAccessor struct {
+	Principal     string              `json:"principal"`
+	ResourceID    string              `json:"resource_id"`
+	ResourceType  string              `json:"resource_type"`
+	Role          string              `json:"role"`
+	Via           string              `json:"via"`                      // One of the Access* constants
+	GroupPath     []string            `json:"group_path,omitempty"`     // For group access, the groups leading to the binding's group, innermost first
+	InheritedFrom *Scope              `json:"inherited_from,omitempty"` // For inherited access, the scope the role is granted on
+	Address       string              `json:"address,omitempty"`        // Terraform address of the binding that grants the role
+	Chain         []string            `json:"chain,omitempty"`          // For impersonation, the service accounts of the shortest chain
+	Edges         []ImpersonationEdge `json:"edges,omitempty"`          // For impersonation, the edge behind each hop of Chain
+	ChainType     string              `json:"chain_type,omitempty"`     // For impersonation, one of the Chain* constants
+	Unverified    bool                `json:"unverified,omitempty"`     // The role's permissions are not fully declared, so it only may grant the permission looked for
+}
+
+// WhoCan returns every principal that holds a role on a resource whose ID matches matchResource: through a binding
+// on the resource, through a group it belongs to, through a grant on the resource's project, folder or organization,
+// or by impersonating, within maxDepth hops, a service account that does. Roles for which matchRole is false are
+// left out. Each way a principal holds a role is listed once, impersonation through the shortest chain.
+// directAccess should come from AnalyzeWithGroups, and graph should carry the groups and hierarchy of the bindings.
+func WhoCan(bindings []parser.IAMBinding, directAccess map[string]*PrincipalData, graph *ImpersonationGraph, matchResource, matchRole func(string) bool, maxDepth int) []ResourceAccessor {
+	accessors := resourceGrants(bindings, directAccess, graph, matchResource, matchRole)
+	seen := make(map[string]bool)
+	add := func(a ResourceAccessor) {
+		key := a.Principal + "|" + a.ResourceID + "|" + a.Role + "|" + a.Via
+		if !seen[key] {
+			seen[key] = true
+			accessors = append(accessors, a)
+		}
+	}
+
+	// Principals that can impersonate a service account holding the role
+	holders := make(map[string][]ResourceAccessor)
+	held := make(map[string]bool)
+	for _, a := range accessors {
+		holders[a.Principal] = append(holders[a.Principal], a)
+		held[a.Principal+"|"+a.ResourceID+"|"+a.Role] = true
+	}
+	principals := make([]string, 0, len(directAccess))
+	for principal := range directAccess {
+		principals = append(principals, principal)
+	}
+	sort.Strings(principals)
+	for _, principal := range principals {
+		for _, path := range ShortestImpersonationPaths(principal, graph, maxDepth) {
+			for _, h := range holders[path.Chain[len(path.Chain)-1]] {
+				if held[principal+"|"+h.ResourceID+"|"+h.Role] {
+					continue
+				}
+				add(ResourceAccessor{
+					Principal: principal, ResourceID: h.ResourceID, ResourceType: h.ResourceType, Role: h.Role, Via: AccessImpersonation,
+					Chain: path.Chain, Edges: path.Edges, ChainType: path.ChainType,
+				})
+			}
+		}
+	}
+
+	sortAccessors(accessors)
+	return accessors
+}
+
+// resourceGrants returns the principals holding a role on the matching resources without impersonation:
+// directly, through a group or through a grant on an ancestor. Each way a principal holds a role is listed once.
+func resourceGrants(bindings []parser.IAMBinding, directAccess map[string]*PrincipalData, graph *ImpersonationGraph, matchResource, matchRole func(string) bool) []ResourceAccessor {
+	var accessors []ResourceAccessor
+	seen := make(map[string]bool)
+	add := func(a ResourceAccessor) {
+		key := a.Principal + "|" + a.ResourceID + "|" + a.Role + "|" + a.Via
+		if !seen[key] {
+			seen[key] = true
+			accessors = append(accessors, a)
+		}
+	}
+
+	// Bindings on the resources, held by principals or by the groups they belong to
+	for principal, data := range directAccess {
+		for resID, meta := range data.ResourceAccess {
+			if !matchResource(resID) {
+				continue
+			}
+			for role := range meta.Roles {
+				if !matchRole(role) {
+					continue
+				}
+				a := ResourceAccessor{Principal: principal, ResourceID: resID, ResourceType: meta.Type, Role: role, Via: AccessDirect, Address: meta.TerraformAddrs[role]}
+				if scope, ok := meta.InheritedFrom[role]; ok {
+					a.Via, a.InheritedFrom = AccessInherited, &scope
+				} else if path := meta.GroupPaths[role]; len(path) > 0 {
+					a.Via, a.GroupPath = AccessGroup, path
+				}
+				add(a)
+			}
+		}
+	}
+
+	// Grants on ancestors, for resources with bindings of their own and for the declared inventory
+	inherited := func(principal, resID, resType, role, address string, scope Scope) {
+		a := ResourceAccessor{Principal: principal, ResourceID: resID, ResourceType: resType, Role: role, Via: AccessInherited, InheritedFrom: &scope, Address: address}
+		add(a)
+		for _, member := range groupMembers(graph.Groups, principal) {
+			m := a
+			m.Principal = member.principal
+			m.GroupPath = member.path
+			add(m)
+		}
+	}
+	for _, g := range InheritedGrantsWithParents(bindings, graph.Parents()) {
+		if !matchResource(g.ResourceID) || !matchRole(g.Binding.Role) {
+			continue
+		}
+		for _, member := range g.Binding.Members {
+			inherited(member, g.ResourceID, g.ResourceType, g.Binding.Role, g.Binding.TerraformAddr, g.InheritedFrom)
+		}
+	}
+	if graph.Hierarchy != nil {
+		for _, entry := range graph.Hierarchy.HierarchicalAccess {
+			if !matchRole(entry.Role) {
+				continue
+			}
+			grantedOn := entry.Scope
+			if entry.InheritedFrom != nil {
+				grantedOn = *entry.InheritedFrom
+			}
+			for _, r := range entry.Resources {
+				if matchResource(r.ID) {
+					inherited(entry.Principal, r.ID, r.Type, entry.Role, entry.Source.ResourceAddress, grantedOn)
+				}
+			}
+		}
+	}
+	return accessors
+}
+
+// sortAccessors orders accessors by resource, principal, way of access and role
+func sortAccessors(accessors []ResourceAccessor) {
+	sort.Slice(accessors, func(i, j int) bool {
+		a, b := accessors[i], accessors[j]
+		if a.ResourceID != b.ResourceID {
+			return a.ResourceID < b.ResourceID
+		}
+		if a.Principal != b.Principal {
+			return a.Principal < b.Principal
+		}
+		if a.Via != b.Via {
+			return accessOrder[a.Via] < accessOrder[b.Via]
+		}
+		return a.Role < b.Role
+	})
+}
+
+type groupMember struct {
+	principal string
+	path      []string
+}
+
+// groupMembers returns the principals that belong to a group directly or through nested groups,
+// each with its path of groups to it, innermost first
+func groupMembers(groups *GroupMemberships, group string) []groupMember {
+	if groups == nil || GetPrincipalType(group) != "group" {
+		return nil
+	}
+	var members []groupMember
+	for _, member := range groups.Members() {
+		for _, path := range groups.GroupPaths(member) {
+			if path[len(path)-1] == group {
+				members = append(members, groupMember{principal: member, path: path})
+				break
+			}
+		}
+	}
+	return members
+}
@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestWhoCan(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	bindings := []parser.IAMBinding{
+		{ResourceID: "data", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource", ParentType: "project", ParentID: "app", Role: "roles/storage.objectViewer", Members: []string{"user:alice@example.com", "group:devs@example.com"}, TerraformAddr: "bucket_viewers"},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/storage.admin", Members: []string{"group:ops@example.com"}, TerraformAddr: "project_ops"},
+		{ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project", Role: "roles/storage.admin", Members: []string{"serviceAccount:deployer@app.iam.gserviceaccount.com"}, TerraformAddr: "project_deployer"},
+		{
+			ResourceID: "projects/app/serviceAccounts/deployer@app.iam.gserviceaccount.com", ResourceType: "google_service_account_iam_member", ResourceLevel: "resource",
+			Role: "roles/iam.serviceAccountTokenCreator", Members: []string{"user:carol@example.com"}, TerraformAddr: "carol_deployer",
+		},
+		{ResourceID: "other", ResourceType: "google_storage_bucket_iam_member", ResourceLevel: "resource", ParentType: "project", ParentID: "app", Role: "roles/storage.objectViewer", Members: []string{"user:dave@example.com"}},
+	}
+	groups := NewGroupMemberships(map[string][]string{
+		"devs@example.com": {"bob@example.com"},
+		"ops@example.com":  {"erin@example.com"},
+	})
+	graph := BuildImpersonationGraph(bindings)
+	graph.AddGroups(groups)
+	directAccess := AnalyzeWithGroups(bindings, groups)
+
+	matchData := func(id string) bool { return id == "data" }
+	all := func(string) bool { return true }
+
+	var got []string
+	for _, a := range WhoCan(bindings, directAccess, graph, matchData, all, 0) {
+		got = append(got, a.Principal+" "+a.Role+" "+a.Via)
+	}
+	want := []string{
+		"group:devs@example.com roles/storage.objectViewer direct",
+		"group:ops@example.com roles/storage.admin inherited",
+		"serviceAccount:deployer@app.iam.gserviceaccount.com roles/storage.admin inherited",
+		"user:alice@example.com roles/storage.objectViewer direct",
+		"user:bob@example.com roles/storage.objectViewer group",
+		"user:carol@example.com roles/storage.admin impersonation",
+		"user:erin@example.com roles/storage.admin inherited",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("WhoCan() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	adminOnly := func(role string) bool { return role == "roles/storage.admin" }
+	for _, a := range WhoCan(bindings, directAccess, graph, matchData, adminOnly, 0) {
+		if a.Role != "roles/storage.admin" {
+			t.Errorf("role filter let %s through", a.Role)
+		}
+		if a.Principal == "user:erin@example.com" && (len(a.GroupPath) != 1 || a.InheritedFrom == nil || a.InheritedFrom.ID != "app") {
+			t.Errorf("erin should inherit through group:ops from project app, got %+v", a)
+		}
+		if a.Principal == "user:carol@example.com" && (len(a.Chain) != 1 || a.Edges[0].SourceAddress != "carol_deployer") {
+			t.Errorf("carol should reach data by impersonating the deployer, got %+v", a)
+		}
+	}
+}
+
+func TestWhoCan_DenseGraph(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	bindings := denseGraphBindings(22)
+	graph := BuildImpersonationGraph(bindings)
+	directAccess := Analyze(bindings)
+
+	start := time.Now()
+	accessors := WhoCan(bindings, directAccess, graph, func(id string) bool { return id == "bucket-21" }, func(string) bool { return true }, 0)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("WhoCan() took %v", elapsed)
+	}
+
+	// sa21 holds the role; alice and every other account impersonate it in one hop
+	impersonators := 0
+	for _, a := range accessors {
+		if a.Via == AccessImpersonation {
+			impersonators++
+			if len(a.Chain) != 1 {
+				t.Errorf("%s reaches bucket-21 through %v, want the direct hop", a.Principal, a.Chain)
+			}
+		}
+	}
+	if len(accessors) != 23 || impersonators != 22 {
+		t.Errorf("WhoCan() = %d accessors, %d through impersonation; want 23 and 22", len(accessors), impersonators)
+	}
+}
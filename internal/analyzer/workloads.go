@@ -0,0 +1,131 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// Workload is a resource declared in Terraform that runs as an attached service account
+type Workload struct {
+	Type           string `json:"type"`              // Terraform resource type (e.g. "google_compute_instance")
+	Address        string `json:"address"`           // Terraform address of the resource
+	Name           string `json:"name"`              // Value of the definition's resource_id attribute
+	Project        string `json:"project,omitempty"` // Project the resource is created in, if known
+	ServiceAccount string `json:"service_account"`   // Attached service account ("serviceAccount:email")
+}
+
+// WorkloadResourceTypes returns the resource types FindWorkloads needs parsed for the given definitions
+func WorkloadResourceTypes(defs []definitions.WorkloadDefinition) []string {
+	types := []string{ServiceAccountType}
+	for _, def := range defs {
+		types = append(types, def.Type)
+	}
+	return types
+}
+
+// FindWorkloads returns the resources of the defined workload types whose attached service account can be resolved.
+// The service account may be given as an email or as a reference to a google_service_account.
+// Resources running as the default compute service account (no service account set) are skipped.
+func FindWorkloads(resources []parser.ResourceInstance, defs []definitions.WorkloadDefinition) []Workload {
+	byType := make(map[string]definitions.WorkloadDefinition, len(defs))
+	for _, def := range defs {
+		byType[def.Type] = def
+	}
+	accounts := make(map[string]parser.ResourceInstance)
+	for _, r := range resources {
+		if r.Type == ServiceAccountType {
+			accounts[r.Address] = r
+		}
+	}
+
+	var workloads []Workload
+	for _, r := range resources {
+		def, ok := byType[r.Type]
+		if !ok {
+			continue
+		}
+		email := extractServiceAccountEmail(strings.TrimPrefix(r.Values[def.ServiceAccount], "serviceAccount:"))
+		if account, ok := accounts[r.References[def.ServiceAccount]]; ok && email == "" {
+			email = serviceAccountEmail(account)
+		}
+		if email == "" {
+			continue
+		}
+
+		project := r.Values["project"]
+		if project == "" {
+			project = r.Project
+		}
+		workloads = append(workloads, Workload{
+			Type:           r.Type,
+			Address:        r.Address,
+			Name:           r.Values[def.ResourceID],
+			Project:        project,
+			ServiceAccount: "serviceAccount:" + email,
+		})
+	}
+	return workloads
+}
+
+// AddWorkloadEdges adds an edge from every principal that can modify a workload to the service account
+// attached to it. A principal can modify a workload when it holds a write or admin role covering the
+// workload's type, either on the workload itself or on its project, folder or organization.
+func (g *ImpersonationGraph) AddWorkloadEdges(workloads []Workload, bindings []parser.IAMBinding, canImpersonate func(string, string) bool) {
+	parents := g.parentMap(bindings)
+
+	for _, w := range workloads {
+		var scopes []string
+		if w.Project != "" {
+			scopes = ancestorScopes(scopeKey("project", w.Project), parents)
+		}
+
+		for _, b := range bindings {
+			if !modifiesWorkload(b.Role, w.Type) {
+				continue
+			}
+			if isHierarchyLevel(b.ResourceLevel) {
+				if !containsString(scopes, scopeKey(b.ResourceLevel, b.ResourceID)) {
+					continue
+				}
+			} else if !bindsWorkload(b, w) {
+				continue
+			}
+
+			for _, member := range b.Members {
+				if member == w.ServiceAccount || !canImpersonate(GetPrincipalType(member), "serviceAccount") {
+					continue
+				}
+				edge := newEdge(b, member, w.ServiceAccount)
+				edge.Capability = definitions.CapabilityWorkloadControl
+				edge.Workload = w.Address
+				g.addEdge(edge)
+			}
+		}
+	}
+}
+
+// modifiesWorkload reports whether a role grants write or admin access to resources of the workload type
+func modifiesWorkload(role, workloadType string) bool {
+	hierarchy := definitions.GetRoleHierarchy(role)
+	if hierarchy == nil || (hierarchy.AccessLevel != "write" && hierarchy.AccessLevel != "admin") {
+		return false
+	}
+	return containsString(hierarchy.ResourceTypes, workloadType) || containsString(hierarchy.ResourceTypes, "*")
+}
+
+// bindsWorkload reports whether a resource-level binding is on the workload itself:
+// its IAM resource type is the workload type with an _iam_* suffix and the resource IDs name the same resource
+func bindsWorkload(b parser.IAMBinding, w Workload) bool {
+	base, _, ok := strings.Cut(b.ResourceType, "_iam_")
+	if !ok || base != w.Type || w.Name == "" {
+		return false
+	}
+	return lastPathSegment(b.ResourceID) == lastPathSegment(w.Name)
+}
+
+// lastPathSegment returns the final segment of a resource path such as "projects/p/zones/z/instances/vm"
+func lastPathSegment(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
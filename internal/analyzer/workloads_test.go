@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestFindWorkloads(t *testing.T) {
+	defs := []definitions.WorkloadDefinition{
+		{Type: "google_compute_instance", ResourceID: "name", ServiceAccount: "service_account.email"},
+		{Type: "google_cloud_run_v2_service", ResourceID: "name", ServiceAccount: "template.service_account"},
+	}
+	resources := []parser.ResourceInstance{
+		{Type: ServiceAccountType, Address: "google_service_account.vm", Project: "app", Values: map[string]string{"account_id": "vm"}},
+		{
+			Type: "google_compute_instance", Address: "google_compute_instance.vm", Project: "app",
+			Values: map[string]string{"name": "runner"}, References: map[string]string{"service_account.email": "google_service_account.vm"},
+		},
+		{
+			Type: "google_cloud_run_v2_service", Address: "google_cloud_run_v2_service.api",
+			Values: map[string]string{"name": "api", "project": "web", "template.service_account": "api@web.iam.gserviceaccount.com"},
+		},
+		{Type: "google_compute_instance", Address: "google_compute_instance.default_sa", Values: map[string]string{"name": "plain"}},
+	}
+
+	want := []Workload{
+		{Type: "google_compute_instance", Address: "google_compute_instance.vm", Name: "runner", Project: "app", ServiceAccount: "serviceAccount:vm@app.iam.gserviceaccount.com"},
+		{Type: "google_cloud_run_v2_service", Address: "google_cloud_run_v2_service.api", Name: "api", Project: "web", ServiceAccount: "serviceAccount:api@web.iam.gserviceaccount.com"},
+	}
+	if got := FindWorkloads(resources, defs); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindWorkloads() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFindWorkloads_Plan(t *testing.T) {
+	// The instance's service account is unknown until the account is created; the nested block's
+	// expression names the account
+	defs := []definitions.WorkloadDefinition{{Type: "google_compute_instance", ResourceID: "name", ServiceAccount: "service_account.email"}}
+	resources := parsePlanResources(t, []string{ServiceAccountType, "google_compute_instance"}, []parser.Resource{
+		{Address: "google_service_account.ci", Mode: "managed", Type: ServiceAccountType, Values: map[string]interface{}{"account_id": "ci", "project": "app"}},
+		{
+			Address: "google_compute_instance.vm", Mode: "managed", Type: "google_compute_instance",
+			Values: map[string]interface{}{"name": "runner", "project": "app", "service_account": []interface{}{map[string]interface{}{"scopes": []interface{}{"cloud-platform"}}}},
+		},
+	}, []parser.PlanConfigResource{
+		{
+			Address: "google_compute_instance.vm", Mode: "managed",
+			Expressions: map[string]interface{}{
+				"service_account": []interface{}{map[string]interface{}{
+					"email": map[string]interface{}{"references": []interface{}{"google_service_account.ci.email", "google_service_account.ci"}},
+				}},
+			},
+		},
+	})
+
+	want := []Workload{
+		{Type: "google_compute_instance", Address: "google_compute_instance.vm", Name: "runner", Project: "app", ServiceAccount: "serviceAccount:ci@app.iam.gserviceaccount.com"},
+	}
+	if got := FindWorkloads(resources, defs); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindWorkloads() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAddWorkloadEdges(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	runner := "serviceAccount:runner@app.iam.gserviceaccount.com"
+	workloads := []Workload{
+		{Type: "google_compute_instance", Address: "google_compute_instance.vm", Name: "vm", Project: "app", ServiceAccount: runner},
+	}
+	bindings := []parser.IAMBinding{
+		{
+			ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project",
+			Role: "roles/compute.instanceAdmin.v1", Members: []string{"user:bob@example.com"}, TerraformAddr: "google_project_iam_member.bob",
+		},
+		{
+			ResourceID: "projects/app/zones/europe-west1-b/instances/vm", ResourceType: "google_compute_instance_iam_member", ResourceLevel: "resource",
+			Role: "roles/compute.admin", Members: []string{"user:carol@example.com"},
+		},
+		{
+			ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project",
+			Role: "roles/compute.viewer", Members: []string{"user:dave@example.com"},
+		},
+		{
+			ResourceID: "other", ResourceType: "google_project_iam_member", ResourceLevel: "project",
+			Role: "roles/editor", Members: []string{"user:erin@example.com"},
+		},
+		{
+			ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project",
+			Role: "roles/owner", Members: []string{runner},
+		},
+	}
+
+	graph := &ImpersonationGraph{Graph: make(map[string][]ImpersonationEdge)}
+	graph.AddWorkloadEdges(workloads, bindings, CanImpersonate)
+
+	want := map[string][]ImpersonationEdge{
+		"user:bob@example.com": {{
+			Source: "user:bob@example.com", Target: runner, Role: "roles/compute.instanceAdmin.v1",
+			Capability: definitions.CapabilityWorkloadControl, Scope: "project:app",
+			SourceAddress: "google_project_iam_member.bob", Inherited: true, Workload: "google_compute_instance.vm",
+		}},
+		"user:carol@example.com": {{
+			Source: "user:carol@example.com", Target: runner, Role: "roles/compute.admin",
+			Capability: definitions.CapabilityWorkloadControl, Scope: "resource:projects/app/zones/europe-west1-b/instances/vm",
+			Workload: "google_compute_instance.vm",
+		}},
+	}
+	if !reflect.DeepEqual(graph.Graph, want) {
+		t.Errorf("Graph = %+v, want %+v", graph.Graph, want)
+	}
+
+	if chain := classifyChain(graph.Graph["user:bob@example.com"]); chain != ChainViaWorkload {
+		t.Errorf("classifyChain() = %q, want %q", chain, ChainViaWorkload)
+	}
+}
@@ -3,20 +3,70 @@ package config
 import (
 	"os"
 
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/schema"
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	CloudProvider      string          `yaml:"cloud_provider"`
-	Exclusions         []ExclusionRule `yaml:"exclusions"`
-	IgnoredDirectories []string        `yaml:"ignored_directories"`
-	AnalysisAccounts   []string        `yaml:"analysis_accounts"` // Accounts to analyze (emails without principal type)
+	CloudProvider      string            `yaml:"cloud_provider" schema:"enum=gcp"`
+	Exclusions         []ExclusionRule   `yaml:"exclusions"`
+	IgnoredDirectories []string          `yaml:"ignored_directories"`
+	AnalysisAccounts   []string          `yaml:"analysis_accounts"` // Accounts to analyze (emails without principal type)
+	Coverage           CoverageConfig    `yaml:"coverage,omitempty"`
+	GroupsFile         string            `yaml:"groups_file,omitempty"` // YAML or CSV export of group memberships, see LoadGroups
+	Hierarchy          map[string]string `yaml:"hierarchy,omitempty"`   // Child → parent for parts of the resource tree managed elsewhere, see HierarchyLinks
+	Scoring            ScoringConfig     `yaml:"scoring,omitempty"`
+}
+
+// ScoringConfig tunes the blast-radius scores; weights left unset keep their defaults
+type ScoringConfig struct {
+	Weights            ScoringWeights  `yaml:"weights,omitempty"`
+	SensitiveResources []SensitiveRule `yaml:"sensitive_resources,omitempty"`
+}
+
+// ScoringWeights overrides the weight of each scoring factor
+type ScoringWeights struct {
+	AccessLevels      AccessLevelWeights `yaml:"access_levels,omitempty"`
+	Scopes            ScopeWeights       `yaml:"scopes,omitempty"`
+	ReachableResource *float64           `yaml:"reachable_resource,omitempty"` // Added per resource reached
+	Sensitive         *float64           `yaml:"sensitive,omitempty"`          // Multiplier for grants on sensitive resources
+	Impersonation     *float64           `yaml:"impersonation,omitempty"`      // Added per service account reachable by impersonation
+	External          *float64           `yaml:"external,omitempty"`           // Added for external principals
+	Public            *float64           `yaml:"public,omitempty"`             // Added for allUsers and allAuthenticatedUsers
+}
+
+// AccessLevelWeights is the weight of a grant per access_level of its role
+type AccessLevelWeights struct {
+	Read        *float64 `yaml:"read,omitempty"`
+	Write       *float64 `yaml:"write,omitempty"`
+	Admin       *float64 `yaml:"admin,omitempty"`
+	Impersonate *float64 `yaml:"impersonate,omitempty"`
+}
+
+// ScopeWeights multiplies a grant's weight by the level it is made on
+type ScopeWeights struct {
+	Organization *float64 `yaml:"organization,omitempty"`
+	Folder       *float64 `yaml:"folder,omitempty"`
+	Project      *float64 `yaml:"project,omitempty"`
+	Resource     *float64 `yaml:"resource,omitempty"`
+}
+
+// SensitiveRule tags the resources whose ID matches a regex as sensitive
+type SensitiveRule struct {
+	Resource string `yaml:"resource" schema:"pattern,required"` // Regex pattern for Resource ID ("*" matches all)
+	Tag      string `yaml:"tag"`                                // Label reported for matching resources (e.g. "pii")
+}
+
+// CoverageConfig tunes which resource types the coverage command treats as IAM-like
+type CoverageConfig struct {
+	ResourcePatterns []string `yaml:"resource_patterns,omitempty" schema:"regex"` // Extra regexes for IAM-like resource types
+	IgnoredTypes     []string `yaml:"ignored_types,omitempty" schema:"regex"`     // Regexes for resource types to never report
 }
 
 type ExclusionRule struct {
-	Resource     string `yaml:"resource"`      // Regex pattern for Resource ID
-	ResourceType string `yaml:"resource_type"` // Regex pattern for Resource Type
-	Role         string `yaml:"role"`          // Regex pattern for Role
+	Resource     string `yaml:"resource" schema:"regex"`      // Regex pattern for Resource ID
+	ResourceType string `yaml:"resource_type" schema:"regex"` // Regex pattern for Resource Type
+	Role         string `yaml:"role" schema:"regex"`          // Regex pattern for Role
 }
 
 func Load(path string) (*Config, error) {
@@ -29,12 +79,21 @@ func Load(path string) (*Config, error) {
 	}
 
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if err := schema.Decode(path, data, &cfg); err != nil {
 		return nil, err
 	}
 	return &cfg, nil
 }
 
+// LintFile checks a configuration file and returns every issue found
+func LintFile(path string) []schema.Issue {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []schema.Issue{{File: path, Message: err.Error()}}
+	}
+	return append(schema.Check(path, data, &Config{}), lintHierarchy(path, data)...)
+}
+
 func CreateDefault(path string, provider string) error {
 	defaultConfig := Config{
 		CloudProvider: provider,
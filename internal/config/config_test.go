@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -108,3 +110,49 @@ func TestIsExcluded(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_Scoring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blast-radius.yaml")
+	data := `
+scoring:
+  weights:
+    access_levels:
+      admin: 10
+    scopes:
+      organization: 20
+    public: 50
+  sensitive_resources:
+    - resource: "^prod-"
+      tag: pii
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	w := cfg.Scoring.Weights
+	if w.AccessLevels.Admin == nil || *w.AccessLevels.Admin != 10 || w.AccessLevels.Read != nil {
+		t.Errorf("access_levels = %+v, want only admin set to 10", w.AccessLevels)
+	}
+	if w.Scopes.Organization == nil || *w.Scopes.Organization != 20 || w.Public == nil || *w.Public != 50 {
+		t.Errorf("weights = %+v, want organization 20 and public 50", w)
+	}
+	if len(cfg.Scoring.SensitiveResources) != 1 || cfg.Scoring.SensitiveResources[0].Tag != "pii" {
+		t.Errorf("sensitive_resources = %+v", cfg.Scoring.SensitiveResources)
+	}
+
+	// Unknown access levels and invalid patterns are rejected
+	for _, bad := range []string{
+		"scoring:\n  weights:\n    access_levels:\n      owner: 3\n",
+		"scoring:\n  sensitive_resources:\n    - resource: \"prod-(\"\n",
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("Load(%q) expected an error", bad)
+		}
+	}
+}
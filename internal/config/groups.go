@@ -0,0 +1,92 @@
+package config
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/schema"
+)
+
+// GroupsFile matches the structure of a groups YAML export
+type GroupsFile struct {
+	Groups map[string][]string `yaml:"groups"` // group email -> member emails or principals
+}
+
+// LoadGroups reads group memberships from a YAML or CSV export and returns group email -> members.
+// Members may carry a principal type prefix ("group:oncall@example.com"); bare emails are typed later.
+//
+// CSV files need a header row with a group column and a member column, e.g. the Workspace export
+// "Group Email,Member Email,Member Type". An optional type column (USER, GROUP, SERVICE_ACCOUNT) sets the prefix.
+func LoadGroups(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read groups file %s: %w", path, err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return parseGroupsCSV(path, string(data))
+	}
+
+	var file GroupsFile
+	if err := schema.Decode(path, data, &file); err != nil {
+		return nil, err
+	}
+	return file.Groups, nil
+}
+
+// memberTypePrefixes maps the member types of a CSV export to principal type prefixes
+var memberTypePrefixes = map[string]string{
+	"user":            "user:",
+	"group":           "group:",
+	"service_account": "serviceAccount:",
+	"serviceaccount":  "serviceAccount:",
+}
+
+// parseGroupsCSV reads a CSV export with group, member and optional type columns
+func parseGroupsCSV(path, data string) (map[string][]string, error) {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse groups file %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return map[string][]string{}, nil
+	}
+
+	groupCol, memberCol, typeCol := -1, -1, -1
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case strings.Contains(name, "type"):
+			if typeCol < 0 {
+				typeCol = i
+			}
+		case strings.Contains(name, "group") && groupCol < 0:
+			groupCol = i
+		case strings.Contains(name, "member") && memberCol < 0:
+			memberCol = i
+		}
+	}
+	if groupCol < 0 || memberCol < 0 {
+		return nil, fmt.Errorf("groups file %s: header needs a group column and a member column", path)
+	}
+
+	groups := make(map[string][]string)
+	for line, record := range records[1:] {
+		if groupCol >= len(record) || memberCol >= len(record) {
+			return nil, fmt.Errorf("groups file %s: line %d has too few columns", path, line+2)
+		}
+		group := strings.TrimSpace(record[groupCol])
+		member := strings.TrimSpace(record[memberCol])
+		if group == "" || member == "" {
+			continue
+		}
+		if typeCol >= 0 && typeCol < len(record) {
+			member = memberTypePrefixes[strings.ToLower(strings.TrimSpace(record[typeCol]))] + member
+		}
+		groups[group] = append(groups[group], member)
+	}
+	return groups, nil
+}
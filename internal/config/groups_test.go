@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadGroups(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	yamlPath := filepath.Join(tmpDir, "groups.yaml")
+	yamlData := `
+groups:
+  devs@example.com:
+    - alice@example.com
+    - group:oncall@example.com
+`
+	if err := os.WriteFile(yamlPath, []byte(yamlData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadGroups(yamlPath)
+	if err != nil {
+		t.Fatalf("LoadGroups(yaml) error = %v", err)
+	}
+	want := map[string][]string{"devs@example.com": {"alice@example.com", "group:oncall@example.com"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadGroups(yaml) = %v, want %v", got, want)
+	}
+
+	csvPath := filepath.Join(tmpDir, "groups.csv")
+	csvData := "Group Email,Member Email,Member Type,Member Role\n" +
+		"devs@example.com,alice@example.com,USER,MEMBER\n" +
+		"eng@example.com,devs@example.com,GROUP,MEMBER\n" +
+		"eng@example.com,ci@app.iam.gserviceaccount.com,SERVICE_ACCOUNT,MEMBER\n"
+	if err := os.WriteFile(csvPath, []byte(csvData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = LoadGroups(csvPath)
+	if err != nil {
+		t.Fatalf("LoadGroups(csv) error = %v", err)
+	}
+	want = map[string][]string{
+		"devs@example.com": {"user:alice@example.com"},
+		"eng@example.com":  {"group:devs@example.com", "serviceAccount:ci@app.iam.gserviceaccount.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadGroups(csv) = %v, want %v", got, want)
+	}
+
+	badPath := filepath.Join(tmpDir, "bad.csv")
+	if err := os.WriteFile(badPath, []byte("email,role\na@example.com,OWNER\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadGroups(badPath); err == nil {
+		t.Errorf("LoadGroups(bad csv) should fail without group and member columns")
+	}
+}
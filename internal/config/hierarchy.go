@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/schema"
+)
+
+// HierarchyLink places a project or folder under its parent, from the hierarchy section
+type HierarchyLink struct {
+	ChildType  string // "project" or "folder"
+	ChildID    string
+	ParentType string // "folder" or "organization"
+	ParentID   string
+}
+
+// hierarchyPrefixes maps resource name prefixes of the hierarchy section to node types
+var hierarchyPrefixes = map[string]string{
+	"projects/":      "project",
+	"folders/":       "folder",
+	"organizations/": "organization",
+}
+
+// HierarchyLinks returns the links of the hierarchy section, sorted by child.
+// Children are written as "projects/<id>" or "folders/<id>", parents as "folders/<id>" or "organizations/<id>".
+func (c *Config) HierarchyLinks() ([]HierarchyLink, error) {
+	children := make([]string, 0, len(c.Hierarchy))
+	for child := range c.Hierarchy {
+		children = append(children, child)
+	}
+	sort.Strings(children)
+
+	var links []HierarchyLink
+	for _, child := range children {
+		link, err := parseHierarchyLink(child, c.Hierarchy[child])
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// parseHierarchyLink parses one entry of the hierarchy section
+func parseHierarchyLink(child, parent string) (HierarchyLink, error) {
+	childType, childID := splitHierarchyName(child)
+	if childType != "project" && childType != "folder" {
+		return HierarchyLink{}, fmt.Errorf("hierarchy: %q must be projects/<id> or folders/<id>", child)
+	}
+	parentType, parentID := splitHierarchyName(parent)
+	if parentType != "folder" && parentType != "organization" {
+		return HierarchyLink{}, fmt.Errorf("hierarchy: parent %q of %q must be folders/<id> or organizations/<id>", parent, child)
+	}
+	return HierarchyLink{ChildType: childType, ChildID: childID, ParentType: parentType, ParentID: parentID}, nil
+}
+
+// splitHierarchyName splits "folders/123" into ("folder", "123"), or returns empty strings
+func splitHierarchyName(name string) (string, string) {
+	for prefix, nodeType := range hierarchyPrefixes {
+		if id, ok := strings.CutPrefix(name, prefix); ok && id != "" && !strings.Contains(id, "/") {
+			return nodeType, id
+		}
+	}
+	return "", ""
+}
+
+// lintHierarchy reports malformed entries of the hierarchy section
+func lintHierarchy(path string, data []byte) []schema.Issue {
+	section := schema.Lookup(schema.Root(data), "hierarchy")
+	if section == nil {
+		return nil
+	}
+	var issues []schema.Issue
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		key, value := section.Content[i], section.Content[i+1]
+		if _, err := parseHierarchyLink(key.Value, value.Value); err != nil {
+			issues = append(issues, schema.NodeIssue(path, key, "%v", err))
+		}
+	}
+	return issues
+}
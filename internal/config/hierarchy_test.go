@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHierarchyLinks(t *testing.T) {
+	cfg := &Config{Hierarchy: map[string]string{
+		"projects/legacy": "folders/900",
+		"folders/900":     "organizations/111",
+	}}
+	got, err := cfg.HierarchyLinks()
+	if err != nil {
+		t.Fatalf("HierarchyLinks() error = %v", err)
+	}
+	want := []HierarchyLink{
+		{ChildType: "folder", ChildID: "900", ParentType: "organization", ParentID: "111"},
+		{ChildType: "project", ChildID: "legacy", ParentType: "folder", ParentID: "900"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HierarchyLinks() = %v, want %v", got, want)
+	}
+
+	for _, bad := range []map[string]string{
+		{"legacy": "folders/900"},                  // child without a prefix
+		{"organizations/111": "folders/900"},       // organizations have no parent
+		{"projects/legacy": "projects/other"},      // projects cannot be parents
+		{"folders/900": "organizations/111/extra"}, // malformed parent
+	} {
+		if _, err := (&Config{Hierarchy: bad}).HierarchyLinks(); err == nil {
+			t.Errorf("HierarchyLinks(%v) expected an error", bad)
+		}
+	}
+}
+
+func TestLintFile_Hierarchy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blast-radius.yaml")
+	data := `cloud_provider: gcp
+hierarchy:
+  projects/legacy: folders/900
+  legacy: folders/900
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var hierarchyIssues []string
+	for _, issue := range LintFile(path) {
+		if strings.Contains(issue.Message, "hierarchy") {
+			hierarchyIssues = append(hierarchyIssues, issue.Message)
+		}
+	}
+	if len(hierarchyIssues) != 1 || !strings.Contains(hierarchyIssues[0], `"legacy"`) {
+		t.Errorf("LintFile() hierarchy issues = %v, want one about \"legacy\"", hierarchyIssues)
+	}
+}
@@ -0,0 +1,53 @@
+package definitions
+
+// What an escalation primitive gains the principal holding it
+const (
+	GainsOwner          = "owner"           // owner-equivalent access to the project, folder or organization the grant is made on
+	GainsServiceAccount = "service_account" // the access of the service account the grant gives a hold over
+	GainsServiceAgent   = "service_agent"   // the access of a Google-managed service agent of the granted projects
+)
+
+// OwnerRole is the role owner-equivalent access is measured against
+const OwnerRole = "roles/owner"
+
+// EscalationPrimitive is a known way to turn a grant into more access than the role itself describes
+type EscalationPrimitive struct {
+	Name               string   `yaml:"name" json:"name" schema:"required"`                                                          // e.g. "project_set_iam_policy"
+	Description        string   `yaml:"description" json:"description,omitempty"`                                                    // what the holder does to escalate
+	Gains              string   `yaml:"gains" json:"gains" schema:"required,enum=owner|service_account|service_agent"`               // one of the Gains* constants
+	Permissions        []string `yaml:"permissions,omitempty" json:"permissions,omitempty"`                                          // any of these permissions grants the primitive
+	Roles              []string `yaml:"roles,omitempty" json:"roles,omitempty"`                                                      // roles known to grant it, and roles including them
+	Levels             []string `yaml:"levels,omitempty" json:"levels,omitempty" schema:"enum=organization|folder|project|resource"` // levels the grant must be made on; any when empty
+	RequiresDeployRole bool     `yaml:"requires_deploy_role,omitempty" json:"requires_deploy_role,omitempty"`                        // for service_account gains, only with a deploy role in the account's project
+	RequiresCustomRole bool     `yaml:"requires_custom_role,omitempty" json:"requires_custom_role,omitempty"`                        // for owner gains, only for holders of a custom role defined on the scope
+	ServiceAgent       string   `yaml:"service_agent,omitempty" json:"service_agent,omitempty"`                                      // for service_agent gains, the email domain of the agent
+}
+
+// GrantedBy reports whether a role granted on a level gives the primitive: the level is allowed and the role
+// is listed, includes a listed role or grants one of the permissions
+func (p EscalationPrimitive) GrantedBy(role, level string) bool {
+	if len(p.Levels) > 0 && !containsString(p.Levels, level) {
+		return false
+	}
+	for _, r := range p.Roles {
+		if RoleIncludes(role, r) {
+			return true
+		}
+	}
+	for _, perm := range p.Permissions {
+		if RoleHasPermission(role, perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEscalationPrimitives returns the escalation primitives loaded by LoadRules, in catalogue order
+func GetEscalationPrimitives() []EscalationPrimitive {
+	return escalationCache
+}
+
+// IsOwnerEquivalent reports whether a role is roles/owner or includes it
+func IsOwnerEquivalent(role string) bool {
+	return RoleIncludes(role, OwnerRole)
+}
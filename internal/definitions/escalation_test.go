@@ -0,0 +1,86 @@
+package definitions
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEscalationPrimitives(t *testing.T) {
+	if err := LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	byName := make(map[string]EscalationPrimitive)
+	for _, p := range GetEscalationPrimitives() {
+		byName[p.Name] = p
+	}
+
+	tests := []struct {
+		primitive string
+		role      string
+		level     string
+		want      bool
+	}{
+		{"project_set_iam_policy", "roles/resourcemanager.projectIamAdmin", "project", true},
+		{"project_set_iam_policy", "roles/resourcemanager.projectIamAdmin", "resource", false},
+		{"service_account_key_creation", "roles/iam.serviceAccountKeyAdmin", "resource", true},
+		{"service_account_set_iam_policy", "roles/iam.serviceAccountAdmin", "project", true},
+		{"act_as_deploy", "roles/editor", "project", true},
+		{"cloud_build", "roles/viewer", "project", false},
+	}
+	for _, tt := range tests {
+		p, ok := byName[tt.primitive]
+		if !ok {
+			t.Fatalf("primitive %s missing from the catalogue", tt.primitive)
+		}
+		if got := p.GrantedBy(tt.role, tt.level); got != tt.want {
+			t.Errorf("%s.GrantedBy(%q, %q) = %v, want %v", tt.primitive, tt.role, tt.level, got, tt.want)
+		}
+	}
+	if !IsOwnerEquivalent("roles/owner") || IsOwnerEquivalent("roles/editor") {
+		t.Errorf("only roles/owner should be owner-equivalent")
+	}
+}
+
+func TestEscalationPrimitives_Overlay(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rules-escalation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	overlay := writeOverlay(t, tmpDir, "escalation.yaml", `
+escalation_primitives:
+  - name: "cloud_build"
+    gains: "service_agent"
+    roles: ["roles/custom.builder"]
+    service_agent: "cloudbuild.gserviceaccount.com"
+  - name: "custom_policy_admin"
+    gains: "owner"
+    permissions: ["custom.policies.setIamPolicy"]
+delete:
+  escalation_primitives: ["deployment_manager"]
+`)
+
+	if err := LoadRules(overlay); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	defer func() { _ = LoadRules() }()
+
+	names := make(map[string]EscalationPrimitive)
+	for _, p := range GetEscalationPrimitives() {
+		names[p.Name] = p
+	}
+	if _, ok := names["deployment_manager"]; ok {
+		t.Errorf("deployment_manager should have been deleted")
+	}
+	if p := names["cloud_build"]; !p.GrantedBy("roles/custom.builder", "project") || p.GrantedBy("roles/editor", "project") {
+		t.Errorf("cloud_build should be replaced by the overlay entry, got %+v", p)
+	}
+	if got := GetRuleOrigin(SectionEscalationPrimitives, "custom_policy_admin"); got != overlay {
+		t.Errorf("origin of custom_policy_admin = %q, want %q", got, overlay)
+	}
+	if got := GetRuleOrigin(SectionEscalationPrimitives, "project_set_iam_policy"); got != OriginBuiltin {
+		t.Errorf("origin of project_set_iam_policy = %q, want %q", got, OriginBuiltin)
+	}
+}
@@ -3,7 +3,8 @@ package definitions
 import (
 	_ "embed"
 	"fmt"
-	"os"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/schema"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,78 +14,151 @@ var embeddedRules []byte
 
 // RoleHierarchy defines access granted by a role
 type RoleHierarchy struct {
-	DisplayName   string   `yaml:"display_name"`   // Human-readable name (e.g., "BigQuery Dataset")
-	ResourceTypes []string `yaml:"resource_types"` // Terraform resource types this role grants access to
-	TargetLevel   string   `yaml:"target_level"`   // The natural level for this role: "resource", "project", etc.
-	AccessLevel   string   `yaml:"access_level"`   // read, write, admin, impersonate
+	DisplayName   string   `yaml:"display_name" json:"display_name"`                                                    // Human-readable name (e.g., "BigQuery Dataset")
+	ResourceTypes []string `yaml:"resource_types" json:"resource_types"`                                                // Terraform resource types this role grants access to
+	TargetLevel   string   `yaml:"target_level" json:"target_level" schema:"enum=organization|folder|project|resource"` // The natural level for this role: "resource", "project", etc.
+	AccessLevel   string   `yaml:"access_level" json:"access_level" schema:"enum=read|write|admin|impersonate"`         // read, write, admin, impersonate
+	Priority      int      `yaml:"priority,omitempty" json:"priority,omitempty"`                                        // Order among pattern keys; higher wins
 }
 
 // ImpersonationRule defines a valid impersonation path
 type ImpersonationRule struct {
-	SourceType string `yaml:"source"`
-	TargetType string `yaml:"target"`
+	SourceType string `yaml:"source" schema:"required"`
+	TargetType string `yaml:"target" schema:"required"`
+}
+
+// Key identifies the rule for overlays and origin lookups
+func (r ImpersonationRule) Key() string {
+	return r.SourceType + "->" + r.TargetType
 }
 
 // RulesConfig matches the structure of rules.yaml
 type RulesConfig struct {
-	HierarchicalRoles  map[string]RoleHierarchy `yaml:"hierarchical_roles"`
-	ImpersonationRoles []string                 `yaml:"impersonation_roles"`
-	ImpersonationRules []ImpersonationRule      `yaml:"impersonation_rules"`
+	HierarchicalRoles    map[string]RoleHierarchy `yaml:"hierarchical_roles"`
+	ImpersonationRoles   []string                 `yaml:"impersonation_roles" schema:"unique"`
+	ImpersonationRules   []ImpersonationRule      `yaml:"impersonation_rules"`
+	RoleIncludes         map[string][]string      `yaml:"role_includes,omitempty"`                              // role -> roles it fully contains
+	RolePermissions      map[string][]string      `yaml:"role_permissions,omitempty"`                           // role -> permissions it grants
+	DeployRoles          []string                 `yaml:"deploy_roles,omitempty" schema:"unique"`               // roles that can deploy compute running as a service account
+	EscalationPrimitives []EscalationPrimitive    `yaml:"escalation_primitives,omitempty" schema:"unique=name"` // known ways to turn a grant into more access
+	Delete               RulesDeletions           `yaml:"delete,omitempty"`                                     // only meaningful in overlay files
 }
 
 // Global caches
 var (
 	hierarchicalRolesCache  map[string]RoleHierarchy
+	rolePatternsCache       []rolePattern // pattern keys of hierarchicalRolesCache, in match order
 	impersonationRolesCache []string
 	impersonationRulesCache []ImpersonationRule
+	deployRolesCache        []string
+	escalationCache         []EscalationPrimitive
+	roleIncludesConfig      map[string][]string
+	rolePermissionsConfig   map[string][]string
+	ruleOriginsCache        map[string]map[string]string // section -> key -> origin
 )
 
-// LoadRules loads definitions from embedded YAML or a custom file
-func LoadRules(customPath string) error {
-	var data []byte
-	var err error
+// LoadRules loads the embedded rules and layers each custom overlay file on top, in order.
+// Overlays add or override entries by key and may delete built-in entries.
+func LoadRules(customPaths ...string) error {
+	var config RulesConfig
+	if err := yaml.Unmarshal(embeddedRules, &config); err != nil {
+		return fmt.Errorf("failed to parse rules: %w", err)
+	}
+	origins := newRuleOrigins(config)
 
-	if customPath != "" {
-		data, err = os.ReadFile(customPath)
+	for _, path := range customPaths {
+		if path == "" {
+			continue
+		}
+		data, err := readOverlay(path)
 		if err != nil {
-			return fmt.Errorf("failed to read custom rules file: %w", err)
+			return err
 		}
-	} else {
-		data = embeddedRules
+
+		var overlay RulesConfig
+		if err := schema.Decode(path, data, &overlay); err != nil {
+			return fmt.Errorf("failed to parse rules overlay:\n%w", err)
+		}
+		if issues := checkRolePatternKeys(path, data); len(issues) > 0 {
+			return fmt.Errorf("failed to parse rules overlay:\n%w", schema.Errors(issues))
+		}
+		applyRulesOverlay(&config, overlay, path, origins)
 	}
 
-	var config RulesConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return fmt.Errorf("failed to parse rules: %w", err)
+	patterns, err := buildRolePatterns(config.HierarchicalRoles)
+	if err != nil {
+		return err
 	}
 
+	roleIncludes, rolePermissions := buildRoleLattice(config.RoleIncludes, config.RolePermissions)
+
 	hierarchicalRolesCache = config.HierarchicalRoles
+	rolePatternsCache = patterns
 	impersonationRolesCache = config.ImpersonationRoles
 	impersonationRulesCache = config.ImpersonationRules
+	deployRolesCache = config.DeployRoles
+	escalationCache = config.EscalationPrimitives
+	roleIncludesCache = roleIncludes
+	rolePermissionsCache = rolePermissions
+	roleIncludesConfig = config.RoleIncludes
+	rolePermissionsConfig = config.RolePermissions
+	ruleOriginsCache = origins
 
 	return nil
 }
 
+// GetRulesConfig returns the effective (merged) rules currently loaded
+func GetRulesConfig() RulesConfig {
+	return RulesConfig{
+		HierarchicalRoles:    hierarchicalRolesCache,
+		ImpersonationRoles:   impersonationRolesCache,
+		ImpersonationRules:   impersonationRulesCache,
+		RoleIncludes:         roleIncludesConfig,
+		RolePermissions:      rolePermissionsConfig,
+		DeployRoles:          deployRolesCache,
+		EscalationPrimitives: escalationCache,
+	}
+}
+
+// GetRuleOrigin returns where a rule entry came from: "builtin" or the overlay file path
+func GetRuleOrigin(section, key string) string {
+	if ruleOriginsCache == nil {
+		return ""
+	}
+	return ruleOriginsCache[section][key]
+}
+
 // GetResourceTypesForRole returns the resource types that a role grants access to
 func GetResourceTypesForRole(role string) []string {
-	if hierarchicalRolesCache == nil {
-		return nil
-	}
-	if hierarchy, exists := hierarchicalRolesCache[role]; exists {
+	if hierarchy := GetRoleHierarchy(role); hierarchy != nil {
 		return hierarchy.ResourceTypes
 	}
 	return nil
 }
 
-// GetRoleHierarchy returns the full hierarchy info for a role
+// GetRoleHierarchy returns the full hierarchy info for a role.
+// An exact key always wins; otherwise the highest-priority matching pattern key is used.
 func GetRoleHierarchy(role string) *RoleHierarchy {
+	_, hierarchy := MatchRoleHierarchy(role)
+	return hierarchy
+}
+
+// MatchRoleHierarchy returns the hierarchical_roles key that applies to a role and its hierarchy info,
+// or "" and nil if no exact or pattern key matches
+func MatchRoleHierarchy(role string) (string, *RoleHierarchy) {
 	if hierarchicalRolesCache == nil {
-		return nil
+		return "", nil
 	}
-	if hierarchy, exists := hierarchicalRolesCache[role]; exists {
-		return &hierarchy
+	if hierarchy, exists := hierarchicalRolesCache[role]; exists && !IsRolePattern(role) {
+		return role, &hierarchy
 	}
-	return nil
+	for _, p := range rolePatternsCache {
+		if p.re.MatchString(role) {
+			hierarchy := p.hierarchy
+			return p.key, &hierarchy
+		}
+	}
+	return "", nil
 }
 
 // GetDisplayNameForResourceType returns a human-readable display name for a resource type
@@ -0,0 +1,34 @@
+package definitions
+
+import (
+	"os"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/schema"
+)
+
+// LintRulesFile checks a rules overlay file and returns every issue found
+func LintRulesFile(path string) []schema.Issue {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []schema.Issue{{File: path, Message: err.Error()}}
+	}
+	issues := append(schema.Check(path, data, &RulesConfig{}), checkRolePatternKeys(path, data)...)
+	schema.SortIssues(issues)
+	return issues
+}
+
+// LintDefinitionsFile checks a resource definitions overlay file and returns every issue found
+func LintDefinitionsFile(path string) []schema.Issue {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []schema.Issue{{File: path, Message: err.Error()}}
+	}
+	return schema.Check(path, data, &ResourceConfig{})
+}
+
+// LintBuiltin checks the embedded rules and resource definitions
+func LintBuiltin() []schema.Issue {
+	issues := schema.Check("builtin:rules.yaml", embeddedRules, &RulesConfig{})
+	issues = append(issues, checkRolePatternKeys("builtin:rules.yaml", embeddedRules)...)
+	return append(issues, schema.Check("builtin:resources.yaml", embeddedResources, &ResourceConfig{})...)
+}
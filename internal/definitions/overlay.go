@@ -0,0 +1,253 @@
+package definitions
+
+import (
+	"fmt"
+	"os"
+)
+
+// OriginBuiltin marks entries that come from the embedded YAML files
+const OriginBuiltin = "builtin"
+
+// Rule sections used as keys for origin lookups
+const (
+	SectionHierarchicalRoles    = "hierarchical_roles"
+	SectionImpersonationRoles   = "impersonation_roles"
+	SectionImpersonationRules   = "impersonation_rules"
+	SectionRoleIncludes         = "role_includes"
+	SectionRolePermissions      = "role_permissions"
+	SectionDeployRoles          = "deploy_roles"
+	SectionEscalationPrimitives = "escalation_primitives"
+)
+
+// ResourceDeletions lists built-in resource definitions an overlay removes
+type ResourceDeletions struct {
+	Definitions []string `yaml:"definitions" schema:"unique"` // resource types to remove
+	Workloads   []string `yaml:"workloads" schema:"unique"`   // workload types to remove
+	Inventory   []string `yaml:"inventory" schema:"unique"`   // inventory types to remove
+}
+
+// RulesDeletions lists built-in rule entries an overlay removes
+type RulesDeletions struct {
+	HierarchicalRoles    []string            `yaml:"hierarchical_roles"`
+	ImpersonationRoles   []string            `yaml:"impersonation_roles"`
+	ImpersonationRules   []ImpersonationRule `yaml:"impersonation_rules"`
+	RoleIncludes         []string            `yaml:"role_includes"`
+	RolePermissions      []string            `yaml:"role_permissions"`
+	DeployRoles          []string            `yaml:"deploy_roles"`
+	EscalationPrimitives []string            `yaml:"escalation_primitives"` // primitive names to remove
+}
+
+// readOverlay reads a custom overlay file
+func readOverlay(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overlay file %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// applyKeyedOverlay adds, overrides and deletes overlay entries by key, such as the type of resource, workload
+// and inventory definitions. Deletions apply first; overridden entries keep their position and new entries are
+// appended in file order. Origins records the file each added entry comes from.
+func applyKeyedOverlay[T any](entries, additions []T, deleted []string, key func(T) string, origin string, origins map[string]string) []T {
+	for _, k := range deleted {
+		delete(origins, k)
+	}
+
+	index := make(map[string]int)
+	result := make([]T, 0, len(entries)+len(additions))
+	for _, e := range entries {
+		if containsString(deleted, key(e)) {
+			continue
+		}
+		index[key(e)] = len(result)
+		result = append(result, e)
+	}
+
+	for _, e := range additions {
+		if i, exists := index[key(e)]; exists {
+			result[i] = e
+		} else {
+			index[key(e)] = len(result)
+			result = append(result, e)
+		}
+		origins[key(e)] = origin
+	}
+
+	return result
+}
+
+// applyRulesOverlay adds, overrides and deletes rule entries by key.
+// Hierarchical roles, role includes and role permissions are keyed by role, impersonation and deploy roles
+// by name, escalation primitives by their name and impersonation rules by their source/target pair.
+func applyRulesOverlay(base *RulesConfig, overlay RulesConfig, origin string, origins map[string]map[string]string) {
+	if base.HierarchicalRoles == nil {
+		base.HierarchicalRoles = make(map[string]RoleHierarchy)
+	}
+	if base.RoleIncludes == nil {
+		base.RoleIncludes = make(map[string][]string)
+	}
+	if base.RolePermissions == nil {
+		base.RolePermissions = make(map[string][]string)
+	}
+
+	// Deletions first so an overlay can replace a key in a single file
+	for _, role := range overlay.Delete.HierarchicalRoles {
+		delete(base.HierarchicalRoles, role)
+		delete(origins[SectionHierarchicalRoles], role)
+	}
+	for _, role := range overlay.Delete.RoleIncludes {
+		delete(base.RoleIncludes, role)
+		delete(origins[SectionRoleIncludes], role)
+	}
+	for _, role := range overlay.Delete.RolePermissions {
+		delete(base.RolePermissions, role)
+		delete(origins[SectionRolePermissions], role)
+	}
+	if len(overlay.Delete.ImpersonationRoles) > 0 {
+		base.ImpersonationRoles = removeStrings(base.ImpersonationRoles, overlay.Delete.ImpersonationRoles)
+		for _, role := range overlay.Delete.ImpersonationRoles {
+			delete(origins[SectionImpersonationRoles], role)
+		}
+	}
+	if len(overlay.Delete.DeployRoles) > 0 {
+		base.DeployRoles = removeStrings(base.DeployRoles, overlay.Delete.DeployRoles)
+		for _, role := range overlay.Delete.DeployRoles {
+			delete(origins[SectionDeployRoles], role)
+		}
+	}
+	if len(overlay.Delete.EscalationPrimitives) > 0 {
+		kept := make([]EscalationPrimitive, 0, len(base.EscalationPrimitives))
+		for _, p := range base.EscalationPrimitives {
+			if !containsString(overlay.Delete.EscalationPrimitives, p.Name) {
+				kept = append(kept, p)
+			}
+		}
+		base.EscalationPrimitives = kept
+		for _, name := range overlay.Delete.EscalationPrimitives {
+			delete(origins[SectionEscalationPrimitives], name)
+		}
+	}
+	if len(overlay.Delete.ImpersonationRules) > 0 {
+		base.ImpersonationRules = removeRules(base.ImpersonationRules, overlay.Delete.ImpersonationRules)
+		for _, rule := range overlay.Delete.ImpersonationRules {
+			delete(origins[SectionImpersonationRules], rule.Key())
+		}
+	}
+
+	for role, hierarchy := range overlay.HierarchicalRoles {
+		base.HierarchicalRoles[role] = hierarchy
+		origins[SectionHierarchicalRoles][role] = origin
+	}
+	for role, included := range overlay.RoleIncludes {
+		base.RoleIncludes[role] = included
+		origins[SectionRoleIncludes][role] = origin
+	}
+	for role, perms := range overlay.RolePermissions {
+		base.RolePermissions[role] = perms
+		origins[SectionRolePermissions][role] = origin
+	}
+	for _, role := range overlay.ImpersonationRoles {
+		if !containsString(base.ImpersonationRoles, role) {
+			base.ImpersonationRoles = append(base.ImpersonationRoles, role)
+		}
+		origins[SectionImpersonationRoles][role] = origin
+	}
+	for _, role := range overlay.DeployRoles {
+		if !containsString(base.DeployRoles, role) {
+			base.DeployRoles = append(base.DeployRoles, role)
+		}
+		origins[SectionDeployRoles][role] = origin
+	}
+	for _, p := range overlay.EscalationPrimitives {
+		replaced := false
+		for i := range base.EscalationPrimitives {
+			if base.EscalationPrimitives[i].Name == p.Name {
+				base.EscalationPrimitives[i], replaced = p, true
+			}
+		}
+		if !replaced {
+			base.EscalationPrimitives = append(base.EscalationPrimitives, p)
+		}
+		origins[SectionEscalationPrimitives][p.Name] = origin
+	}
+	for _, rule := range overlay.ImpersonationRules {
+		if !containsRule(base.ImpersonationRules, rule) {
+			base.ImpersonationRules = append(base.ImpersonationRules, rule)
+		}
+		origins[SectionImpersonationRules][rule.Key()] = origin
+	}
+}
+
+// newRuleOrigins creates an origin map with every section of cfg marked as built-in
+func newRuleOrigins(cfg RulesConfig) map[string]map[string]string {
+	origins := map[string]map[string]string{
+		SectionHierarchicalRoles:    make(map[string]string),
+		SectionImpersonationRoles:   make(map[string]string),
+		SectionImpersonationRules:   make(map[string]string),
+		SectionRoleIncludes:         make(map[string]string),
+		SectionRolePermissions:      make(map[string]string),
+		SectionDeployRoles:          make(map[string]string),
+		SectionEscalationPrimitives: make(map[string]string),
+	}
+	for role := range cfg.HierarchicalRoles {
+		origins[SectionHierarchicalRoles][role] = OriginBuiltin
+	}
+	for _, role := range cfg.ImpersonationRoles {
+		origins[SectionImpersonationRoles][role] = OriginBuiltin
+	}
+	for _, rule := range cfg.ImpersonationRules {
+		origins[SectionImpersonationRules][rule.Key()] = OriginBuiltin
+	}
+	for role := range cfg.RoleIncludes {
+		origins[SectionRoleIncludes][role] = OriginBuiltin
+	}
+	for role := range cfg.RolePermissions {
+		origins[SectionRolePermissions][role] = OriginBuiltin
+	}
+	for _, role := range cfg.DeployRoles {
+		origins[SectionDeployRoles][role] = OriginBuiltin
+	}
+	for _, p := range cfg.EscalationPrimitives {
+		origins[SectionEscalationPrimitives][p.Name] = OriginBuiltin
+	}
+	return origins
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeStrings(list []string, remove []string) []string {
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if !containsString(remove, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func containsRule(rules []ImpersonationRule, rule ImpersonationRule) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func removeRules(rules []ImpersonationRule, remove []ImpersonationRule) []ImpersonationRule {
+	result := make([]ImpersonationRule, 0, len(rules))
+	for _, r := range rules {
+		if !containsRule(remove, r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
@@ -0,0 +1,198 @@
+package definitions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeOverlay(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadRules_Overlays(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rules-overlay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	first := writeOverlay(t, tmpDir, "first.yaml", `
+hierarchical_roles:
+  "roles/custom.deployer":
+    display_name: "Cloud Run Service"
+    resource_types: ["google_cloud_run_v2_service"]
+    target_level: "resource"
+    access_level: "write"
+  "roles/viewer":
+    display_name: "Anything"
+    resource_types: ["*"]
+    target_level: "resource"
+    access_level: "read"
+impersonation_roles:
+  - "roles/custom.impersonator"
+delete:
+  hierarchical_roles: ["roles/browser"]
+  impersonation_rules:
+    - source: "principalSet"
+      target: "serviceAccount"
+`)
+	second := writeOverlay(t, tmpDir, "second.yaml", `
+hierarchical_roles:
+  "roles/custom.deployer":
+    display_name: "Cloud Run Service"
+    resource_types: ["google_cloud_run_v2_service"]
+    target_level: "resource"
+    access_level: "admin"
+`)
+
+	if err := LoadRules(first, second); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	defer func() { _ = LoadRules() }()
+
+	if h := GetRoleHierarchy("roles/custom.deployer"); h == nil || h.AccessLevel != "admin" {
+		t.Errorf("custom.deployer = %+v, want access_level admin from second overlay", h)
+	}
+	if got := GetRuleOrigin(SectionHierarchicalRoles, "roles/custom.deployer"); got != second {
+		t.Errorf("origin of custom.deployer = %q, want %q", got, second)
+	}
+	if h := GetRoleHierarchy("roles/viewer"); h == nil || h.DisplayName != "Anything" {
+		t.Errorf("roles/viewer = %+v, want overridden display name", h)
+	}
+	if got := GetRuleOrigin(SectionHierarchicalRoles, "roles/owner"); got != OriginBuiltin {
+		t.Errorf("origin of roles/owner = %q, want %q", got, OriginBuiltin)
+	}
+	if h := GetRoleHierarchy("roles/browser"); h != nil {
+		t.Errorf("roles/browser should have been deleted, got %+v", h)
+	}
+	if !IsImpersonationRole("roles/custom.impersonator") || !IsImpersonationRole("roles/iam.serviceAccountUser") {
+		t.Errorf("impersonation roles should include built-in and overlay entries")
+	}
+
+	canImpersonate := GetCanImpersonateFunc()
+	if canImpersonate("principalSet", "serviceAccount") {
+		t.Errorf("deleted impersonation rule principalSet->serviceAccount still allowed")
+	}
+	if !canImpersonate("user", "serviceAccount") {
+		t.Errorf("built-in impersonation rule user->serviceAccount missing")
+	}
+}
+
+func TestLoadResourceDefinitions_Overlays(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "definitions-overlay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	builtin, err := LoadResourceDefinitions()
+	if err != nil {
+		t.Fatalf("LoadResourceDefinitions() error = %v", err)
+	}
+
+	overlay := writeOverlay(t, tmpDir, "defs.yaml", `
+definitions:
+  - type: google_project_iam_member
+    resource_level: project
+    field_mappings:
+      resource_id: project_id
+      role: role
+      member: member
+  - type: acme_internal_iam_member
+    field_mappings:
+      resource_id: name
+      role: role
+      member: member
+workloads:
+  - type: google_compute_instance
+    resource_id: name
+    service_account: service_account.0.email
+  - type: google_workbench_instance
+    resource_id: name
+    service_account: gce_setup.service_accounts.email
+inventory:
+  - type: google_storage_bucket
+    resource_id: id
+    project: project
+  - type: google_filestore_instance
+    resource_id: name
+    project: project
+delete:
+  definitions: ["google_project_iam_policy"]
+  workloads: ["google_composer_environment"]
+  inventory: ["google_dns_managed_zone"]
+`)
+
+	defs, err := LoadResourceDefinitions(overlay)
+	if err != nil {
+		t.Fatalf("LoadResourceDefinitions() error = %v", err)
+	}
+
+	if len(defs) != len(builtin) {
+		t.Errorf("got %d definitions, want %d (one added, one deleted)", len(defs), len(builtin))
+	}
+
+	byType := make(map[string]int)
+	for i, d := range defs {
+		byType[d.Type] = i
+	}
+	if _, exists := byType["google_project_iam_policy"]; exists {
+		t.Errorf("google_project_iam_policy should have been deleted")
+	}
+	if i, exists := byType["google_project_iam_member"]; !exists || defs[i].FieldMappings.ResourceID != "project_id" {
+		t.Errorf("google_project_iam_member should be overridden")
+	}
+	if _, exists := byType["acme_internal_iam_member"]; !exists {
+		t.Errorf("acme_internal_iam_member should have been added")
+	}
+	if got := GetResourceDefinitionOrigin("acme_internal_iam_member"); got != overlay {
+		t.Errorf("origin = %q, want %q", got, overlay)
+	}
+	if got := GetResourceDefinitionOrigin("google_storage_bucket_iam_member"); got != OriginBuiltin {
+		t.Errorf("origin = %q, want %q", got, OriginBuiltin)
+	}
+	workloads := make(map[string]WorkloadDefinition)
+	for _, w := range GetWorkloadDefinitions() {
+		workloads[w.Type] = w
+	}
+	if _, exists := workloads["google_composer_environment"]; exists {
+		t.Errorf("google_composer_environment workload should have been deleted")
+	}
+	if got := workloads["google_compute_instance"].ServiceAccount; got != "service_account.0.email" {
+		t.Errorf("google_compute_instance workload service_account = %q, want the overlay path", got)
+	}
+	if got := GetWorkloadDefinitionOrigin("google_workbench_instance"); got != overlay {
+		t.Errorf("workload origin = %q, want %q", got, overlay)
+	}
+	if got := GetWorkloadDefinitionOrigin("google_cloud_run_v2_service"); got != OriginBuiltin {
+		t.Errorf("workload origin = %q, want %q", got, OriginBuiltin)
+	}
+	inventory := make(map[string]InventoryDefinition)
+	for _, inv := range GetInventoryDefinitions() {
+		inventory[inv.Type] = inv
+	}
+	if _, exists := inventory["google_dns_managed_zone"]; exists {
+		t.Errorf("google_dns_managed_zone inventory should have been deleted")
+	}
+	if got := inventory["google_storage_bucket"].ResourceID; got != "id" {
+		t.Errorf("google_storage_bucket inventory resource_id = %q, want the overlay attribute", got)
+	}
+	if got := GetInventoryDefinitionOrigin("google_filestore_instance"); got != overlay {
+		t.Errorf("inventory origin = %q, want %q", got, overlay)
+	}
+	if got := GetInventoryDefinitionOrigin("google_pubsub_topic"); got != OriginBuiltin {
+		t.Errorf("inventory origin = %q, want %q", got, OriginBuiltin)
+	}
+}
+
+func TestLintBuiltin(t *testing.T) {
+	if issues := LintBuiltin(); len(issues) > 0 {
+		t.Errorf("embedded definitions should lint cleanly, got %v", issues)
+	}
+}
@@ -0,0 +1,92 @@
+package definitions
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/schema"
+)
+
+// RegexKeyPrefix marks a hierarchical_roles key as a regular expression
+const RegexKeyPrefix = "regex:"
+
+// rolePattern is a compiled pattern key from hierarchical_roles
+type rolePattern struct {
+	key       string
+	re        *regexp.Regexp
+	hierarchy RoleHierarchy
+}
+
+// IsRolePattern reports whether a hierarchical_roles key is a glob or regex pattern rather than an exact role
+func IsRolePattern(key string) bool {
+	return strings.HasPrefix(key, RegexKeyPrefix) || strings.ContainsAny(key, "*?")
+}
+
+// compileRolePattern turns a pattern key into an anchored regex.
+// Globs use "*" for any run of characters except "/" and "?" for a single one.
+func compileRolePattern(key string) (*regexp.Regexp, error) {
+	if expr, ok := strings.CutPrefix(key, RegexKeyPrefix); ok {
+		return regexp.Compile("^(?:" + expr + ")$")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range key {
+		switch r {
+		case '*':
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// buildRolePatterns compiles the pattern keys of roles, ordered by descending
+// priority and then by key so that matching is deterministic
+func buildRolePatterns(roles map[string]RoleHierarchy) ([]rolePattern, error) {
+	var patterns []rolePattern
+	for key, hierarchy := range roles {
+		if !IsRolePattern(key) {
+			continue
+		}
+		re, err := compileRolePattern(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid role pattern %q: %w", key, err)
+		}
+		patterns = append(patterns, rolePattern{key: key, re: re, hierarchy: hierarchy})
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].hierarchy.Priority != patterns[j].hierarchy.Priority {
+			return patterns[i].hierarchy.Priority > patterns[j].hierarchy.Priority
+		}
+		return patterns[i].key < patterns[j].key
+	})
+	return patterns, nil
+}
+
+// checkRolePatternKeys reports hierarchical_roles pattern keys that do not compile
+func checkRolePatternKeys(file string, data []byte) []schema.Issue {
+	roles := schema.Lookup(schema.Root(data), "hierarchical_roles")
+	if roles == nil {
+		return nil
+	}
+
+	var issues []schema.Issue
+	for i := 0; i+1 < len(roles.Content); i += 2 {
+		key := roles.Content[i]
+		if !IsRolePattern(key.Value) {
+			continue
+		}
+		if _, err := compileRolePattern(key.Value); err != nil {
+			issues = append(issues, schema.NodeIssue(file, key, "hierarchical_roles: invalid role pattern %q: %v", key.Value, err))
+		}
+	}
+	return issues
+}
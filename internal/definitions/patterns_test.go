@@ -0,0 +1,102 @@
+package definitions
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGetRoleHierarchy_Patterns(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rules-patterns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	overlay := writeOverlay(t, tmpDir, "patterns.yaml", `
+hierarchical_roles:
+  "roles/*.viewer":
+    display_name: "Resource"
+    resource_types: ["*"]
+    target_level: "resource"
+    access_level: "read"
+  "regex:roles/storage\\..*":
+    display_name: "Cloud Storage Bucket"
+    resource_types: ["google_storage_bucket"]
+    target_level: "resource"
+    access_level: "write"
+    priority: 10
+  "roles/storage.*":
+    display_name: "Cloud Storage Bucket"
+    resource_types: ["google_storage_bucket"]
+    target_level: "resource"
+    access_level: "admin"
+`)
+
+	if err := LoadRules(overlay); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	defer func() { _ = LoadRules() }()
+
+	tests := []struct {
+		role       string
+		wantKey    string
+		wantAccess string
+	}{
+		// Exact built-in entries win over patterns
+		{"roles/storage.objectViewer", "roles/storage.objectViewer", "read"},
+		{"roles/widgets.viewer", "roles/*.viewer", "read"},
+		// Higher priority wins among matching patterns
+		{"roles/storage.hmacKeyAdmin", `regex:roles/storage\..*`, "write"},
+		// "*" does not cross "/"
+		{"projects/p/roles/custom.viewer", "", ""},
+		{"roles/widgets.user", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			key, h := MatchRoleHierarchy(tt.role)
+			if key != tt.wantKey {
+				t.Errorf("MatchRoleHierarchy(%q) key = %q, want %q", tt.role, key, tt.wantKey)
+			}
+			if tt.wantAccess == "" {
+				if h != nil {
+					t.Errorf("expected no match, got %+v", h)
+				}
+				return
+			}
+			if h == nil || h.AccessLevel != tt.wantAccess {
+				t.Errorf("MatchRoleHierarchy(%q) = %+v, want access_level %s", tt.role, h, tt.wantAccess)
+			}
+		})
+	}
+}
+
+func TestLoadRules_InvalidPattern(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rules-patterns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	overlay := writeOverlay(t, tmpDir, "bad.yaml", `
+hierarchical_roles:
+  "regex:roles/(storage":
+    display_name: "Cloud Storage Bucket"
+    resource_types: ["google_storage_bucket"]
+    target_level: "resource"
+    access_level: "write"
+`)
+	defer func() { _ = LoadRules() }()
+
+	err = LoadRules(overlay)
+	if err == nil {
+		t.Fatal("expected error for invalid regex key")
+	}
+	if !strings.Contains(err.Error(), "bad.yaml:3:3") {
+		t.Errorf("error = %v, want position of the invalid key", err)
+	}
+	if issues := LintRulesFile(overlay); len(issues) != 1 {
+		t.Errorf("LintRulesFile() = %v, want 1 issue", issues)
+	}
+}
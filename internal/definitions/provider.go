@@ -3,9 +3,9 @@ package definitions
 import (
 	_ "embed"
 	"fmt"
-	"os"
 
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/schema"
 
 	"gopkg.in/yaml.v3"
 )
@@ -15,27 +15,134 @@ var embeddedResources []byte
 
 // ResourceConfig matches the structure of resources.yaml
 type ResourceConfig struct {
-	Resources []parser.ResourceDefinition `yaml:"definitions"`
+	Version       string                      `yaml:"version"`
+	CloudProvider string                      `yaml:"cloud_provider" schema:"enum=gcp"`
+	LastUpdated   string                      `yaml:"last_updated"`
+	Description   string                      `yaml:"description"`
+	Resources     []parser.ResourceDefinition `yaml:"definitions" schema:"unique=type"`
+	Workloads     []WorkloadDefinition        `yaml:"workloads,omitempty" schema:"unique=type"` // resources that run as a service account
+	Inventory     []InventoryDefinition       `yaml:"inventory,omitempty" schema:"unique=type"` // resources project, folder and organization grants reach
+	Delete        ResourceDeletions           `yaml:"delete,omitempty"`                         // only meaningful in overlay files
 }
 
-// LoadResourceDefinitions loads definitions from embedded YAML or a custom file
-func LoadResourceDefinitions(customPath string) ([]parser.ResourceDefinition, error) {
-	var data []byte
-	var err error
+// WorkloadDefinition describes a resource type that runs as an attached service account.
+// Anyone who can modify such a resource can run code with that service account's access.
+type WorkloadDefinition struct {
+	Type           string `yaml:"type" json:"type" schema:"required"`                       // e.g. "google_compute_instance"
+	DisplayName    string `yaml:"display_name" json:"display_name,omitempty"`               // e.g. "Compute Instance"
+	ResourceID     string `yaml:"resource_id" json:"resource_id" schema:"required"`         // attribute IAM bindings on the resource refer to, e.g. "name"
+	ServiceAccount string `yaml:"service_account" json:"service_account" schema:"required"` // dotted path of the attached service account, e.g. "service_account.email"
+}
+
+// InventoryDefinition describes a non-IAM resource type that roles granted on its project, folder or
+// organization reach, so those grants can be expanded into the concrete resources declared in Terraform
+type InventoryDefinition struct {
+	Type        string `yaml:"type" json:"type" schema:"required"`               // e.g. "google_storage_bucket"
+	DisplayName string `yaml:"display_name" json:"display_name,omitempty"`       // e.g. "Cloud Storage Bucket"
+	ResourceID  string `yaml:"resource_id" json:"resource_id" schema:"required"` // attribute IAM bindings on the resource refer to, e.g. "name"
+	Project     string `yaml:"project" json:"project" schema:"required"`         // attribute holding the project ID or a resource name under it, e.g. "project"
+}
+
+var (
+	resourceOriginsCache  map[string]string     // resource type -> origin of its effective definition
+	workloadsCache        []WorkloadDefinition  // effective workload definitions
+	workloadOriginsCache  map[string]string     // workload type -> origin of its effective definition
+	inventoryCache        []InventoryDefinition // effective inventory definitions
+	inventoryOriginsCache map[string]string     // inventory type -> origin of its effective definition
+)
+
+// LoadResourceDefinitions loads the embedded definitions and layers each custom overlay file on top, in order.
+// Overlays add or override definitions by type and may delete built-in definitions.
+func LoadResourceDefinitions(customPaths ...string) ([]parser.ResourceDefinition, error) {
+	var config ResourceConfig
+	if err := yaml.Unmarshal(embeddedResources, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse resource definitions: %w", err)
+	}
+
+	defs := config.Resources
+	origins := make(map[string]string)
+	for _, def := range defs {
+		origins[def.Type] = OriginBuiltin
+	}
+	workloads := config.Workloads
+	workloadOrigins := make(map[string]string)
+	for _, w := range workloads {
+		workloadOrigins[w.Type] = OriginBuiltin
+	}
+	inventory := config.Inventory
+	inventoryOrigins := make(map[string]string)
+	for _, inv := range inventory {
+		inventoryOrigins[inv.Type] = OriginBuiltin
+	}
 
-	if customPath != "" {
-		data, err = os.ReadFile(customPath)
+	for _, path := range customPaths {
+		if path == "" {
+			continue
+		}
+		data, err := readOverlay(path)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read custom definitions file: %w", err)
+			return nil, err
+		}
+
+		var overlay ResourceConfig
+		if err := schema.Decode(path, data, &overlay); err != nil {
+			return nil, fmt.Errorf("failed to parse resource definitions overlay:\n%w", err)
 		}
-	} else {
-		data = embeddedResources
+		defs = applyKeyedOverlay(defs, overlay.Resources, overlay.Delete.Definitions,
+			func(d parser.ResourceDefinition) string { return d.Type }, path, origins)
+		workloads = applyKeyedOverlay(workloads, overlay.Workloads, overlay.Delete.Workloads,
+			func(w WorkloadDefinition) string { return w.Type }, path, workloadOrigins)
+		inventory = applyKeyedOverlay(inventory, overlay.Inventory, overlay.Delete.Inventory,
+			func(inv InventoryDefinition) string { return inv.Type }, path, inventoryOrigins)
 	}
 
-	var config ResourceConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse resource definitions: %w", err)
+	resourceOriginsCache = origins
+	workloadsCache = workloads
+	workloadOriginsCache = workloadOrigins
+	inventoryCache = inventory
+	inventoryOriginsCache = inventoryOrigins
+	return defs, nil
+}
+
+// GetWorkloadDefinitions returns the workload definitions loaded by LoadResourceDefinitions
+func GetWorkloadDefinitions() []WorkloadDefinition {
+	return workloadsCache
+}
+
+// GetWorkloadTypes returns the resource types of the loaded workload definitions
+func GetWorkloadTypes() []string {
+	types := make([]string, 0, len(workloadsCache))
+	for _, w := range workloadsCache {
+		types = append(types, w.Type)
+	}
+	return types
+}
+
+// GetWorkloadDefinitionOrigin returns where a workload definition came from: "builtin" or the overlay file path
+func GetWorkloadDefinitionOrigin(workloadType string) string {
+	if workloadOriginsCache == nil {
+		return ""
+	}
+	return workloadOriginsCache[workloadType]
+}
+
+// GetInventoryDefinitions returns the inventory definitions loaded by LoadResourceDefinitions
+func GetInventoryDefinitions() []InventoryDefinition {
+	return inventoryCache
+}
+
+// GetInventoryDefinitionOrigin returns where an inventory definition came from: "builtin" or the overlay file path
+func GetInventoryDefinitionOrigin(inventoryType string) string {
+	if inventoryOriginsCache == nil {
+		return ""
 	}
+	return inventoryOriginsCache[inventoryType]
+}
 
-	return config.Resources, nil
+// GetResourceDefinitionOrigin returns where a resource definition came from: "builtin" or the overlay file path
+func GetResourceDefinitionOrigin(resourceType string) string {
+	if resourceOriginsCache == nil {
+		return ""
+	}
+	return resourceOriginsCache[resourceType]
 }
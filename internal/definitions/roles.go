@@ -0,0 +1,241 @@
+package definitions
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Global caches derived from role_includes and role_permissions
+var (
+	roleIncludesCache    map[string]map[string]bool // role -> every role it transitively includes
+	rolePermissionsCache map[string]map[string]bool // role -> own and included permissions
+)
+
+// buildRoleLattice computes the transitive closure of role_includes and the
+// effective permission set of every role with declared includes or permissions
+func buildRoleLattice(includes, permissions map[string][]string) (map[string]map[string]bool, map[string]map[string]bool) {
+	closure := make(map[string]map[string]bool)
+
+	var visit func(role string, seen map[string]bool)
+	visit = func(role string, seen map[string]bool) {
+		for _, included := range includes[role] {
+			if seen[included] {
+				continue
+			}
+			seen[included] = true
+			visit(included, seen)
+		}
+	}
+	for role := range includes {
+		seen := make(map[string]bool)
+		visit(role, seen)
+		delete(seen, role) // a cycle back to the role itself adds nothing
+		closure[role] = seen
+	}
+
+	perms := make(map[string]map[string]bool)
+	addPerms := func(role, from string) {
+		for _, p := range permissions[from] {
+			if perms[role] == nil {
+				perms[role] = make(map[string]bool)
+			}
+			perms[role][p] = true
+		}
+	}
+	for role := range permissions {
+		addPerms(role, role)
+	}
+	for role, included := range closure {
+		for inc := range included {
+			addPerms(role, inc)
+		}
+	}
+
+	return closure, perms
+}
+
+// RoleIncludes reports whether holding broader grants everything narrower grants.
+// This is true when the roles are equal, when role_includes links them (transitively),
+// or when narrower declares its own permissions and they are a subset of broader's.
+// A role whose permissions only come from role_includes is not fully described, so it is never
+// compared by permissions (roles/editor would otherwise look smaller than roles/iam.serviceAccountUser).
+func RoleIncludes(broader, narrower string) bool {
+	if broader == narrower {
+		return true
+	}
+	if roleIncludesCache[broader][narrower] {
+		return true
+	}
+
+	narrowerPerms := rolePermissionsCache[narrower]
+	broaderPerms := rolePermissionsCache[broader]
+	if len(rolePermissionsConfig[narrower]) == 0 || len(broaderPerms) == 0 {
+		return false
+	}
+	for p := range narrowerPerms {
+		if !broaderPerms[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// RolePermissions returns the sorted permissions of a role, including those of the roles it includes
+func RolePermissions(role string) []string {
+	perms := make([]string, 0, len(rolePermissionsCache[role]))
+	for p := range rolePermissionsCache[role] {
+		perms = append(perms, p)
+	}
+	sort.Strings(perms)
+	return perms
+}
+
+// RolePermissionsComplete reports whether a role declares its own permissions in role_permissions.
+// Only then does RolePermissions list everything the role grants rather than what its included roles grant.
+func RolePermissionsComplete(role string) bool {
+	return len(rolePermissionsConfig[role]) > 0
+}
+
+// RoleHasPermission reports whether a role grants a permission.
+// The permission may use "*" wildcards per dot-separated segment (e.g. "storage.objects.*").
+func RoleHasPermission(role, permission string) bool {
+	if rolePermissionsCache[role][permission] {
+		return true
+	}
+	for p := range rolePermissionsCache[role] {
+		if matched, _ := path.Match(permission, p); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleMayGrantPermission reports whether a role may grant a permission, which may use "*" wildcards.
+// For a role declaring its own permissions this is RoleHasPermission. For any other role, whose known permissions
+// are only those of the roles it includes, it is also true when the hierarchical_roles entry of the role covers
+// the permission's service through its resource types (or its own name, e.g. "roles/storage.objectAdmin") and an
+// access level high enough for the permission's verb. Roles without an entry may grant any permission of the
+// service they are named after.
+// Example: RoleMayGrantPermission("roles/owner", "storage.objects.delete") → true
+func RoleMayGrantPermission(role, permission string) bool {
+	if RoleHasPermission(role, permission) {
+		return true
+	}
+	if RolePermissionsComplete(role) {
+		return false
+	}
+	service, _, _ := strings.Cut(permission, ".")
+	hierarchy := GetRoleHierarchy(role)
+	if hierarchy == nil {
+		return service == roleService(role)
+	}
+	if !containsString(hierarchy.ResourceTypes, "*") && service != roleService(role) && !typesCoverService(hierarchy.ResourceTypes, service) {
+		return false
+	}
+	return accessLevelCovers(hierarchy.AccessLevel, permissionAccessLevel(permission))
+}
+
+// roleService returns the service a predefined role is named after
+// Example: "roles/storage.objectAdmin" → "storage"
+func roleService(role string) string {
+	name := strings.TrimPrefix(role, "roles/")
+	if service, _, ok := strings.Cut(name, "."); ok {
+		return service
+	}
+	return ""
+}
+
+// typesCoverService reports whether one of the resource types belongs to a service
+// Example: "google_secret_manager_secret" belongs to "secretmanager"
+func typesCoverService(types []string, service string) bool {
+	for _, rt := range types {
+		name := strings.ReplaceAll(strings.TrimPrefix(rt, "google_"), "_", "")
+		if strings.HasPrefix(name, service) {
+			return true
+		}
+	}
+	return false
+}
+
+// permissionAccessLevel returns the access level a permission needs, judged from its verb.
+// A wildcard verb needs read access, which every role of the service has.
+func permissionAccessLevel(permission string) string {
+	verb := permission[strings.LastIndex(permission, ".")+1:]
+	switch {
+	case verb == "setIamPolicy":
+		return "admin"
+	case verb == "actAs" || verb == "getAccessToken" || verb == "getOpenIdToken" || verb == "signBlob" || verb == "signJwt" || verb == "implicitDelegation":
+		return "impersonate"
+	case verb == "*" || strings.HasPrefix(verb, "get") || strings.HasPrefix(verb, "list"):
+		return "read"
+	}
+	return "write"
+}
+
+// accessLevelCovers reports whether a role of access level has can hold a permission needing access level need
+func accessLevelCovers(has, need string) bool {
+	switch has {
+	case "admin":
+		return true
+	case "write":
+		return need == "read" || need == "write"
+	case "impersonate":
+		return need == "read" || need == "impersonate"
+	}
+	return need == "read"
+}
+
+// GrantsImpersonation reports whether a role is an impersonation role or includes one
+func GrantsImpersonation(role string) bool {
+	if IsImpersonationRole(role) {
+		return true
+	}
+	for _, r := range impersonationRolesCache {
+		if RoleIncludes(role, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Impersonation capabilities, describing what holding a role lets a principal do with a service account
+const (
+	CapabilityTokenCreation    = "token_creation"    // mint access or ID tokens for the service account
+	CapabilityActAs            = "act_as"            // attach the service account to deployed compute
+	CapabilityKeyCreation      = "key_creation"      // create long-lived keys for the service account
+	CapabilityWorkloadIdentity = "workload_identity" // exchange a federated or Kubernetes identity for the service account
+	CapabilityWorkloadControl  = "workload_control"  // modify a resource that runs as the service account; not derived from the role alone
+)
+
+// ImpersonationCapability returns the strongest capability a role grants over a service account, or "" if none.
+// Key creation covers roles that can create keys and roles that can rewrite the service account's IAM policy
+// (and so grant themselves key creation). Impersonation roles without known permissions are treated as token creation.
+func ImpersonationCapability(role string) string {
+	switch {
+	case RoleHasPermission(role, "iam.serviceAccountKeys.create"), RoleHasPermission(role, "iam.serviceAccounts.setIamPolicy"):
+		return CapabilityKeyCreation
+	case !GrantsImpersonation(role):
+		return ""
+	case RoleIncludes(role, "roles/iam.serviceAccountTokenCreator"):
+		return CapabilityTokenCreation
+	case RoleIncludes(role, "roles/iam.workloadIdentityUser"):
+		return CapabilityWorkloadIdentity
+	case RoleHasPermission(role, "iam.serviceAccounts.getAccessToken"):
+		return CapabilityTokenCreation
+	case RoleHasPermission(role, "iam.serviceAccounts.actAs"):
+		return CapabilityActAs
+	}
+	return CapabilityTokenCreation
+}
+
+// IsDeployRole reports whether a role can deploy compute that runs as a service account:
+// it is listed in deploy_roles or includes a listed role
+func IsDeployRole(role string) bool {
+	for _, r := range deployRolesCache {
+		if RoleIncludes(role, r) {
+			return true
+		}
+	}
+	return false
+}
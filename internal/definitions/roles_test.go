@@ -0,0 +1,150 @@
+package definitions
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestRoleIncludes(t *testing.T) {
+	if err := LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		broader  string
+		narrower string
+		want     bool
+	}{
+		{"Same Role", "roles/run.invoker", "roles/run.invoker", true},
+		{"Declared", "roles/owner", "roles/editor", true},
+		{"Transitive", "roles/owner", "roles/browser", true},
+		{"Reverse", "roles/viewer", "roles/owner", false},
+		{"Permission Subset", "roles/iam.serviceAccountTokenCreator", "roles/iam.workloadIdentityUser", true},
+		{"Inherited Permissions", "roles/storage.admin", "roles/storage.objectViewer", true},
+		{"Unrelated", "roles/storage.admin", "roles/bigquery.dataViewer", false},
+		{"Unknown Roles", "roles/custom.a", "roles/custom.b", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoleIncludes(tt.broader, tt.narrower); got != tt.want {
+				t.Errorf("RoleIncludes(%q, %q) = %v, want %v", tt.broader, tt.narrower, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoleMayGrantPermission(t *testing.T) {
+	if err := LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		role       string
+		permission string
+		want       bool
+	}{
+		{"Declared", "roles/iam.serviceAccountUser", "iam.serviceAccounts.actAs", true},
+		{"Declared Without Permission", "roles/iam.serviceAccountUser", "iam.serviceAccounts.getAccessToken", false},
+		{"Basic Role", "roles/owner", "storage.objects.delete", true},
+		{"Basic Role Wildcard", "roles/editor", "storage.objects.*", true},
+		{"Access Level Too Low", "roles/viewer", "storage.objects.delete", false},
+		{"Access Level Read", "roles/viewer", "storage.objects.get", true},
+		{"Editor Cannot Set Policy", "roles/editor", "resourcemanager.projects.setIamPolicy", false},
+		{"Service Of Resource Types", "roles/bigquery.dataOwner", "bigquery.tables.delete", true},
+		{"Other Service", "roles/bigquery.dataOwner", "storage.objects.delete", false},
+		{"Unknown Role", "roles/custom.thing", "custom.things.delete", true},
+		{"Unknown Role Other Service", "roles/custom.thing", "storage.objects.delete", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoleMayGrantPermission(tt.role, tt.permission); got != tt.want {
+				t.Errorf("RoleMayGrantPermission(%q, %q) = %v, want %v", tt.role, tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRolePermissions_Overlay(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rules-roles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	overlay := writeOverlay(t, tmpDir, "roles.yaml", `
+role_includes:
+  "roles/custom.admin": ["roles/custom.reader"]
+  "roles/custom.loop": ["roles/custom.admin", "roles/custom.loop"]
+role_permissions:
+  "roles/custom.reader": ["custom.things.get", "custom.things.list"]
+  "roles/custom.admin": ["custom.things.delete"]
+delete:
+  role_includes: ["roles/owner"]
+`)
+
+	if err := LoadRules(overlay); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	defer func() { _ = LoadRules() }()
+
+	want := []string{"custom.things.delete", "custom.things.get", "custom.things.list"}
+	if got := RolePermissions("roles/custom.loop"); !reflect.DeepEqual(got, want) {
+		t.Errorf("RolePermissions(custom.loop) = %v, want %v", got, want)
+	}
+	if !RoleHasPermission("roles/custom.admin", "custom.things.*") {
+		t.Errorf("custom.admin should match wildcard permission custom.things.*")
+	}
+	if RoleHasPermission("roles/custom.reader", "custom.things.delete") {
+		t.Errorf("custom.reader should not have custom.things.delete")
+	}
+	if RoleIncludes("roles/owner", "roles/editor") {
+		t.Errorf("deleted role_includes entry for roles/owner still applies")
+	}
+	if got := GetRuleOrigin(SectionRolePermissions, "roles/custom.admin"); got != overlay {
+		t.Errorf("origin = %q, want %q", got, overlay)
+	}
+}
+
+func TestImpersonationCapability(t *testing.T) {
+	if err := LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	tests := map[string]string{
+		"roles/iam.serviceAccountTokenCreator": CapabilityTokenCreation,
+		"roles/iam.serviceAccountUser":         CapabilityActAs,
+		"roles/iam.workloadIdentityUser":       CapabilityWorkloadIdentity,
+		"roles/editor":                         CapabilityActAs,
+		"roles/iam.serviceAccountKeyAdmin":     CapabilityKeyCreation,
+		"roles/iam.serviceAccountAdmin":        CapabilityKeyCreation,
+		"roles/storage.admin":                  "",
+	}
+	for role, want := range tests {
+		if got := ImpersonationCapability(role); got != want {
+			t.Errorf("ImpersonationCapability(%q) = %q, want %q", role, got, want)
+		}
+	}
+}
+
+func TestIsDeployRole(t *testing.T) {
+	if err := LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	tests := map[string]bool{
+		"roles/run.developer":          true,
+		"roles/owner":                  true, // through roles/editor
+		"roles/viewer":                 false,
+		"roles/iam.serviceAccountUser": false,
+	}
+	for role, want := range tests {
+		if got := IsDeployRole(role); got != want {
+			t.Errorf("IsDeployRole(%q) = %v, want %v", role, got, want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package output
+
+import (
+	"time"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+)
+
+// CoverageOutput represents the JSON output for the coverage command
+type CoverageOutput struct {
+	Command        string                           `json:"command"`
+	Timestamp      time.Time                        `json:"timestamp"`
+	Source         SourceInfo                       `json:"source"`
+	UncoveredTypes []analyzer.UncoveredResourceType `json:"uncovered_types"`
+	UnknownRoles   []analyzer.UnknownRole           `json:"unknown_roles"`
+	Summary        CoverageSummary                  `json:"summary"`
+}
+
+// CoverageSummary provides coverage statistics
+type CoverageSummary struct {
+	ResourcesScanned       int `json:"resources_scanned"`
+	CoveredResources       int `json:"covered_resources"`
+	UncoveredTypesCount    int `json:"uncovered_types_count"`
+	UncoveredResourceCount int `json:"uncovered_resource_count"`
+	UnknownRolesCount      int `json:"unknown_roles_count"`
+}
+
+// ConvertToCoverageOutput converts a coverage result to CoverageOutput
+func ConvertToCoverageOutput(result *analyzer.CoverageResult, source SourceInfo) CoverageOutput {
+	summary := CoverageSummary{
+		ResourcesScanned:    result.ResourcesScanned,
+		CoveredResources:    result.CoveredResources,
+		UncoveredTypesCount: len(result.UncoveredTypes),
+		UnknownRolesCount:   len(result.UnknownRoles),
+	}
+	for _, t := range result.UncoveredTypes {
+		summary.UncoveredResourceCount += t.Count
+	}
+
+	return CoverageOutput{
+		Command:        "coverage",
+		Timestamp:      time.Now().UTC(),
+		Source:         source,
+		UncoveredTypes: result.UncoveredTypes,
+		UnknownRoles:   result.UnknownRoles,
+		Summary:        summary,
+	}
+}
@@ -0,0 +1,203 @@
+package output
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// DefinitionsOutput represents the JSON output for the definitions show command
+type DefinitionsOutput struct {
+	Command             string                      `json:"command"`
+	Timestamp           time.Time                   `json:"timestamp"`
+	Effective           bool                        `json:"effective"`
+	ResourceDefinitions []ResourceDefinitionOutput  `json:"resource_definitions"`
+	Workloads           []WorkloadOutput            `json:"workloads"`
+	Inventory           []InventoryOutput           `json:"inventory"`
+	HierarchicalRoles   []HierarchicalRoleOutput    `json:"hierarchical_roles"`
+	ImpersonationRoles  []ImpersonationRoleOutput   `json:"impersonation_roles"`
+	ImpersonationRules  []ImpersonationRuleOutput   `json:"impersonation_rules"`
+	RoleIncludes        []RoleListOutput            `json:"role_includes"`
+	RolePermissions     []RoleListOutput            `json:"role_permissions"`
+	DeployRoles         []DeployRoleOutput          `json:"deploy_roles"`
+	Escalation          []EscalationPrimitiveOutput `json:"escalation_primitives"`
+}
+
+type ResourceDefinitionOutput struct {
+	Type          string              `json:"type"`
+	ResourceLevel string              `json:"resource_level"`
+	FieldMappings parser.FieldMapping `json:"field_mappings"`
+	Origin        string              `json:"origin"`
+}
+
+// WorkloadOutput is a workloads entry of the resource definitions
+type WorkloadOutput struct {
+	definitions.WorkloadDefinition
+	Origin string `json:"origin"`
+}
+
+// InventoryOutput is an inventory entry of the resource definitions
+type InventoryOutput struct {
+	definitions.InventoryDefinition
+	Origin string `json:"origin"`
+}
+
+type HierarchicalRoleOutput struct {
+	Role          string   `json:"role"`
+	DisplayName   string   `json:"display_name"`
+	ResourceTypes []string `json:"resource_types"`
+	TargetLevel   string   `json:"target_level"`
+	AccessLevel   string   `json:"access_level"`
+	Pattern       bool     `json:"pattern,omitempty"`
+	Priority      int      `json:"priority,omitempty"`
+	Origin        string   `json:"origin"`
+}
+
+type ImpersonationRoleOutput struct {
+	Role   string `json:"role"`
+	Origin string `json:"origin"`
+}
+
+// DeployRoleOutput is a deploy_roles entry
+type DeployRoleOutput struct {
+	Role   string `json:"role"`
+	Origin string `json:"origin"`
+}
+
+// EscalationPrimitiveOutput is an escalation_primitives entry
+type EscalationPrimitiveOutput struct {
+	definitions.EscalationPrimitive
+	Origin string `json:"origin"`
+}
+
+// RoleListOutput is a role_includes or role_permissions entry
+type RoleListOutput struct {
+	Role   string   `json:"role"`
+	Values []string `json:"values"`
+	Origin string   `json:"origin"`
+}
+
+type ImpersonationRuleOutput struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Origin string `json:"origin"`
+}
+
+// ConvertToDefinitionsOutput converts loaded definitions and rules to DefinitionsOutput
+func ConvertToDefinitionsOutput(defs []parser.ResourceDefinition, rules definitions.RulesConfig, effective bool) DefinitionsOutput {
+	out := DefinitionsOutput{
+		Command:             "definitions show",
+		Timestamp:           time.Now().UTC(),
+		Effective:           effective,
+		ResourceDefinitions: []ResourceDefinitionOutput{},
+		Workloads:           []WorkloadOutput{},
+		Inventory:           []InventoryOutput{},
+		HierarchicalRoles:   []HierarchicalRoleOutput{},
+		ImpersonationRoles:  []ImpersonationRoleOutput{},
+		ImpersonationRules:  []ImpersonationRuleOutput{},
+		RoleIncludes:        convertRoleLists(rules.RoleIncludes, definitions.SectionRoleIncludes),
+		RolePermissions:     convertRoleLists(rules.RolePermissions, definitions.SectionRolePermissions),
+		DeployRoles:         []DeployRoleOutput{},
+		Escalation:          []EscalationPrimitiveOutput{},
+	}
+
+	for _, def := range defs {
+		level := def.ResourceLevel
+		if level == "" {
+			level = "resource"
+		}
+		out.ResourceDefinitions = append(out.ResourceDefinitions, ResourceDefinitionOutput{
+			Type:          def.Type,
+			ResourceLevel: level,
+			FieldMappings: def.FieldMappings,
+			Origin:        definitions.GetResourceDefinitionOrigin(def.Type),
+		})
+	}
+
+	for _, w := range definitions.GetWorkloadDefinitions() {
+		out.Workloads = append(out.Workloads, WorkloadOutput{
+			WorkloadDefinition: w,
+			Origin:             definitions.GetWorkloadDefinitionOrigin(w.Type),
+		})
+	}
+
+	for _, inv := range definitions.GetInventoryDefinitions() {
+		out.Inventory = append(out.Inventory, InventoryOutput{
+			InventoryDefinition: inv,
+			Origin:              definitions.GetInventoryDefinitionOrigin(inv.Type),
+		})
+	}
+
+	// Sort roles for deterministic output
+	roles := make([]string, 0, len(rules.HierarchicalRoles))
+	for role := range rules.HierarchicalRoles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	for _, role := range roles {
+		h := rules.HierarchicalRoles[role]
+		out.HierarchicalRoles = append(out.HierarchicalRoles, HierarchicalRoleOutput{
+			Role:          role,
+			DisplayName:   h.DisplayName,
+			ResourceTypes: h.ResourceTypes,
+			TargetLevel:   h.TargetLevel,
+			AccessLevel:   h.AccessLevel,
+			Pattern:       definitions.IsRolePattern(role),
+			Priority:      h.Priority,
+			Origin:        definitions.GetRuleOrigin(definitions.SectionHierarchicalRoles, role),
+		})
+	}
+
+	for _, role := range rules.ImpersonationRoles {
+		out.ImpersonationRoles = append(out.ImpersonationRoles, ImpersonationRoleOutput{
+			Role:   role,
+			Origin: definitions.GetRuleOrigin(definitions.SectionImpersonationRoles, role),
+		})
+	}
+
+	for _, role := range rules.DeployRoles {
+		out.DeployRoles = append(out.DeployRoles, DeployRoleOutput{
+			Role:   role,
+			Origin: definitions.GetRuleOrigin(definitions.SectionDeployRoles, role),
+		})
+	}
+
+	for _, p := range rules.EscalationPrimitives {
+		out.Escalation = append(out.Escalation, EscalationPrimitiveOutput{
+			EscalationPrimitive: p,
+			Origin:              definitions.GetRuleOrigin(definitions.SectionEscalationPrimitives, p.Name),
+		})
+	}
+
+	for _, rule := range rules.ImpersonationRules {
+		out.ImpersonationRules = append(out.ImpersonationRules, ImpersonationRuleOutput{
+			Source: rule.SourceType,
+			Target: rule.TargetType,
+			Origin: definitions.GetRuleOrigin(definitions.SectionImpersonationRules, rule.Key()),
+		})
+	}
+
+	return out
+}
+
+// convertRoleLists flattens a role -> list map into entries sorted by role
+func convertRoleLists(lists map[string][]string, section string) []RoleListOutput {
+	roles := make([]string, 0, len(lists))
+	for role := range lists {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	result := make([]RoleListOutput, 0, len(roles))
+	for _, role := range roles {
+		result = append(result, RoleListOutput{
+			Role:   role,
+			Values: lists[role],
+			Origin: definitions.GetRuleOrigin(section, role),
+		})
+	}
+	return result
+}
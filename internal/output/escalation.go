@@ -0,0 +1,64 @@
+package output
+
+import (
+	"time"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+)
+
+// EscalationOutput represents the JSON output for the escalation command
+type EscalationOutput struct {
+	Command   string                       `json:"command"`
+	Timestamp time.Time                    `json:"timestamp"`
+	Source    SourceInfo                   `json:"source"`
+	Findings  []analyzer.EscalationFinding `json:"findings"`
+	Summary   EscalationSummary            `json:"summary"`
+	Scores    Scores                       `json:"scores"` // of the principals that can escalate
+}
+
+// EscalationSummary counts the findings reported by escalation
+type EscalationSummary struct {
+	Findings    int            `json:"findings"`
+	Principals  int            `json:"principals"`
+	Scopes      int            `json:"scopes"`
+	ByPrimitive map[string]int `json:"by_primitive"` // number of findings each primitive takes part in
+}
+
+// ConvertToEscalationOutput converts escalation findings to EscalationOutput
+func ConvertToEscalationOutput(findings []analyzer.EscalationFinding, source SourceInfo, scorer *analyzer.Scorer) EscalationOutput {
+	principals := make(map[string]bool)
+	scopes := make(map[string]bool)
+	byPrimitive := make(map[string]int)
+	var scored []string
+	for _, f := range findings {
+		if !principals[f.Principal] {
+			scored = append(scored, f.Principal)
+		}
+		principals[f.Principal] = true
+		scopes[f.Scope] = true
+		used := make(map[string]bool)
+		for _, s := range f.Steps {
+			if !used[s.Primitive] {
+				used[s.Primitive] = true
+				byPrimitive[s.Primitive]++
+			}
+		}
+	}
+	if findings == nil {
+		findings = []analyzer.EscalationFinding{}
+	}
+
+	return EscalationOutput{
+		Command:   "escalation",
+		Timestamp: time.Now().UTC(),
+		Source:    source,
+		Findings:  findings,
+		Summary: EscalationSummary{
+			Findings:    len(findings),
+			Principals:  len(principals),
+			Scopes:      len(scopes),
+			ByPrimitive: byPrimitive,
+		},
+		Scores: newScores(scorer, scored, nil),
+	}
+}
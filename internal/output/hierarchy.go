@@ -17,6 +17,7 @@ type NewHierarchyOutput struct {
 	HierarchicalAccess []analyzer.HierarchicalAccessEntry `json:"hierarchical_access"`
 	Warnings           []analyzer.Warning                 `json:"warnings"`
 	Summary            HierarchySummary                   `json:"summary"`
+	Scores             Scores                             `json:"scores"` // of the principals with hierarchical access and the resources they reach
 }
 
 // SourceInfo describes where the analysis input came from
@@ -36,6 +37,7 @@ type HierarchyInfo struct {
 type HierarchySummary struct {
 	TotalBindingsAnalyzed            int            `json:"total_bindings_analyzed"`
 	HierarchicalBindings             int            `json:"hierarchical_bindings"`
+	InheritedEntries                 int            `json:"inherited_entries,omitempty"` // entries on descendants of the bound scopes
 	PrincipalsWithHierarchicalAccess int            `json:"principals_with_hierarchical_access"`
 	UnknownHierarchyCount            int            `json:"unknown_hierarchy_count"`
 	UnknownRolesCount                int            `json:"unknown_roles_count"`
@@ -44,7 +46,7 @@ type HierarchySummary struct {
 }
 
 // ConvertToNewHierarchyOutput converts analyzer results to the new comprehensive format
-func ConvertToNewHierarchyOutput(result *analyzer.HierarchyAnalysisResult, source SourceInfo, totalBindings int) NewHierarchyOutput {
+func ConvertToNewHierarchyOutput(result *analyzer.HierarchyAnalysisResult, source SourceInfo, totalBindings int, scorer *analyzer.Scorer) NewHierarchyOutput {
 	// Build summary
 	summary := HierarchySummary{
 		TotalBindingsAnalyzed: totalBindings,
@@ -53,14 +55,29 @@ func ConvertToNewHierarchyOutput(result *analyzer.HierarchyAnalysisResult, sourc
 	}
 
 	principals := make(map[string]bool)
+	var scored, resources []string
+	seenResources := make(map[string]bool)
 	for _, entry := range result.HierarchicalAccess {
+		if !principals[entry.Principal] {
+			scored = append(scored, entry.Principal)
+		}
 		principals[entry.Principal] = true
+		for _, r := range entry.Resources {
+			if !seenResources[r.ID] {
+				seenResources[r.ID] = true
+				resources = append(resources, r.ID)
+			}
+		}
+		if entry.InheritedFrom != nil {
+			summary.InheritedEntries++
+			continue
+		}
+		summary.HierarchicalBindings++
 		summary.ByScope[entry.Scope.Type]++
 		summary.ByAccessType[entry.Grants.AccessType]++
 	}
 
 	summary.PrincipalsWithHierarchicalAccess = len(principals)
-	summary.HierarchicalBindings = len(result.HierarchicalAccess)
 	summary.UnknownHierarchyCount = len(result.Unknown)
 
 	// Count unknown role warnings
@@ -73,14 +90,17 @@ func ConvertToNewHierarchyOutput(result *analyzer.HierarchyAnalysisResult, sourc
 	// Sort hierarchical access by principal for consistent output
 	sortedAccess := make([]analyzer.HierarchicalAccessEntry, len(result.HierarchicalAccess))
 	copy(sortedAccess, result.HierarchicalAccess)
-	sort.Slice(sortedAccess, func(i, j int) bool {
+	sort.SliceStable(sortedAccess, func(i, j int) bool {
 		if sortedAccess[i].Principal != sortedAccess[j].Principal {
 			return sortedAccess[i].Principal < sortedAccess[j].Principal
 		}
 		if sortedAccess[i].Scope.Type != sortedAccess[j].Scope.Type {
 			return sortedAccess[i].Scope.Type < sortedAccess[j].Scope.Type
 		}
-		return sortedAccess[i].Scope.ID < sortedAccess[j].Scope.ID
+		if sortedAccess[i].Scope.ID != sortedAccess[j].Scope.ID {
+			return sortedAccess[i].Scope.ID < sortedAccess[j].Scope.ID
+		}
+		return sortedAccess[i].InheritedFrom == nil && sortedAccess[j].InheritedFrom != nil
 	})
 
 	return NewHierarchyOutput{
@@ -95,5 +115,6 @@ func ConvertToNewHierarchyOutput(result *analyzer.HierarchyAnalysisResult, sourc
 		HierarchicalAccess: sortedAccess,
 		Warnings:           result.Warnings,
 		Summary:            summary,
+		Scores:             newScores(scorer, scored, resources),
 	}
 }
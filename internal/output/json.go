@@ -21,14 +21,18 @@ type ImpactOutput struct {
 
 type PrincipalOutput struct {
 	Principal string           `json:"principal"`
+	Score     float64          `json:"score"` // blast-radius score, see the rank command
 	Resources []ResourceOutput `json:"resources"`
 }
 
 type ResourceOutput struct {
-	ResourceID     string            `json:"resource_id"`
-	ResourceType   string            `json:"resource_type"`
-	Roles          []string          `json:"roles"`
-	TerraformAddrs map[string]string `json:"terraform_addresses,omitempty"`
+	ResourceID     string                    `json:"resource_id"`
+	ResourceType   string                    `json:"resource_type"`
+	Score          float64                   `json:"score"` // exposure score, see the rank command
+	Roles          []string                  `json:"roles"`
+	TerraformAddrs map[string]string         `json:"terraform_addresses,omitempty"`
+	GroupPaths     map[string][]string       `json:"group_paths,omitempty"`    // role -> groups it is held through, innermost first
+	InheritedFrom  map[string]analyzer.Scope `json:"inherited_from,omitempty"` // role -> project, folder or organization it is granted on, for inherited access
 }
 
 // HierarchyOutput represents the JSON output for the hierarchy command
@@ -46,37 +50,62 @@ type HierarchicalAccessOutput struct {
 
 // AnalyzeOutput represents the JSON output for the analyze command
 type AnalyzeOutput struct {
-	Command            string                     `json:"command"`
-	Timestamp          time.Time                  `json:"timestamp"`
-	Account            string                     `json:"account"`
-	DirectAccess       []ResourceOutput           `json:"direct_access"`
-	HierarchicalAccess []HierarchicalAccessOutput `json:"hierarchical_access"`
-	TransitiveAccess   []TransitiveAccessOutput   `json:"transitive_access"`
+	Command                      string                         `json:"command"`
+	Timestamp                    time.Time                      `json:"timestamp"`
+	Account                      string                         `json:"account"`
+	Score                        float64                        `json:"score"` // blast-radius score of the account
+	DirectAccess                 []ResourceOutput               `json:"direct_access"`
+	HierarchicalAccess           []HierarchicalAccessOutput     `json:"hierarchical_access"`
+	TransitiveAccess             []TransitiveAccessOutput       `json:"transitive_access"`
+	TransitiveHierarchicalAccess []TransitiveHierarchicalOutput `json:"transitive_hierarchical_access,omitempty"` // project, folder and organization access gained through impersonation
+	ServiceAccountKeys           []analyzer.ServiceAccountKey   `json:"service_account_keys,omitempty"`           // keys of the account or of service accounts it reaches
+	ExternalIdentity             *analyzer.ExternalIdentity     `json:"external_identity,omitempty"`              // the member analyzed for an external identity selector
+	DeniedAccess                 []analyzer.DeniedAccess        `json:"denied_access,omitempty"`                  // granted roles and impersonation hops deny policies revoke or narrow
+	PathMode                     string                         `json:"path_mode,omitempty"`                      // --paths mode of impersonation_paths
+	ImpersonationPaths           []analyzer.ResourcePaths       `json:"impersonation_paths,omitempty"`            // impersonation paths to each resource reached, for --paths
 }
 
 type TransitiveAccessOutput struct {
-	ResourceID     string            `json:"resource_id"`
-	ResourceType   string            `json:"resource_type"`
-	Roles          []string          `json:"roles"`
-	ViaChain       []string          `json:"via_chain"`
-	TerraformAddrs map[string]string `json:"terraform_addresses,omitempty"`
+	ResourceID     string                       `json:"resource_id"`
+	ResourceType   string                       `json:"resource_type"`
+	Score          float64                      `json:"score"`
+	Roles          []string                     `json:"roles"`
+	ViaChain       []string                     `json:"via_chain"`
+	ViaEdges       []analyzer.ImpersonationEdge `json:"via_edges"`             // the grant behind each hop of via_chain
+	ChainType      string                       `json:"chain_type"`            // direct_impersonation, act_as_via_compute, act_as_unconfirmed or via_workload
+	Unconfirmed    bool                         `json:"unconfirmed,omitempty"` // true for act_as_unconfirmed chains, whose roles are only gained with a deploy role
+	TerraformAddrs map[string]string            `json:"terraform_addresses,omitempty"`
+}
+
+// TransitiveHierarchicalOutput is project, folder or organization access gained through impersonation
+type TransitiveHierarchicalOutput struct {
+	Scope         analyzer.Scope                     `json:"scope"`
+	ResourceTypes []string                           `json:"resource_types"`
+	Roles         []string                           `json:"roles,omitempty"`
+	Entries       []analyzer.HierarchicalAccessEntry `json:"entries,omitempty"` // the impersonated account's hierarchy entries on the scope
+	ViaChain      []string                           `json:"via_chain"`
+	ViaEdges      []analyzer.ImpersonationEdge       `json:"via_edges"`
+	ChainType     string                             `json:"chain_type"`
+	Unconfirmed   bool                               `json:"unconfirmed,omitempty"` // as in TransitiveAccessOutput
 }
 
 // ValidateOutput represents the JSON output for the validate command
 type ValidateOutput struct {
-	Command    string            `json:"command"`
-	Timestamp  time.Time         `json:"timestamp"`
-	Status     string            `json:"status"` // "passed" or "failed"
-	Violations []ViolationOutput `json:"violations"`
+	Command         string                    `json:"command"`
+	Timestamp       time.Time                 `json:"timestamp"`
+	Status          string                    `json:"status"` // "passed" or "failed"
+	Violations      []ViolationOutput         `json:"violations"`
+	RedundantGrants []analyzer.RedundantGrant `json:"redundant_grants,omitempty"`
 }
 
 type ViolationOutput struct {
-	Policy    string `json:"policy"`
-	Severity  string `json:"severity"`
-	Principal string `json:"principal"`
-	Resource  string `json:"resource"`
-	Role      string `json:"role"`
-	Message   string `json:"message"`
+	Policy    string  `json:"policy"`
+	Severity  string  `json:"severity"`
+	Principal string  `json:"principal"`
+	Score     float64 `json:"score"` // blast-radius score of the principal
+	Resource  string  `json:"resource"`
+	Role      string  `json:"role"`
+	Message   string  `json:"message"`
 }
 
 // PrintJSON encodes and prints the given interface as JSON to stdout
@@ -95,7 +124,7 @@ func PrintJSONTo(w io.Writer, v interface{}) {
 }
 
 // ConvertToImpactOutput converts analyzer results to ImpactOutput
-func ConvertToImpactOutput(results map[string]*analyzer.PrincipalData, isExcluded func(string, string, string) bool) ImpactOutput {
+func ConvertToImpactOutput(results map[string]*analyzer.PrincipalData, isExcluded func(string, string, string) bool, scorer *analyzer.Scorer) ImpactOutput {
 	out := ImpactOutput{
 		Command:   "impact",
 		Timestamp: time.Now().UTC(),
@@ -112,6 +141,7 @@ func ConvertToImpactOutput(results map[string]*analyzer.PrincipalData, isExclude
 		data := results[p]
 		pOut := PrincipalOutput{
 			Principal: p,
+			Score:     scorer.Principal(p).Score,
 			Resources: []ResourceOutput{},
 		}
 
@@ -144,11 +174,20 @@ func ConvertToImpactOutput(results map[string]*analyzer.PrincipalData, isExclude
 				resOut := ResourceOutput{
 					ResourceID:   resID,
 					ResourceType: meta.Type,
+					Score:        scorer.Resource(resID).Score,
 					Roles:        roles,
 				}
 				if len(tfAddrs) > 0 {
 					resOut.TerraformAddrs = tfAddrs
 				}
+				for _, r := range roles {
+					if scope, ok := meta.InheritedFrom[r]; ok {
+						if resOut.InheritedFrom == nil {
+							resOut.InheritedFrom = make(map[string]analyzer.Scope)
+						}
+						resOut.InheritedFrom[r] = scope
+					}
+				}
 				pOut.Resources = append(pOut.Resources, resOut)
 			}
 		}
@@ -208,7 +247,7 @@ func ConvertToHierarchyOutput(results map[string]*analyzer.PrincipalData) Hierar
 }
 
 // ConvertToAnalyzeOutput converts transitive access result to AnalyzeOutput
-func ConvertToAnalyzeOutput(account string, access *analyzer.TransitiveAccess) AnalyzeOutput {
+func ConvertToAnalyzeOutput(account string, access *analyzer.TransitiveAccess, scorer *analyzer.Scorer) AnalyzeOutput {
 	out := AnalyzeOutput{
 		Command:   "analyze",
 		Timestamp: time.Now().UTC(),
@@ -218,6 +257,12 @@ func ConvertToAnalyzeOutput(account string, access *analyzer.TransitiveAccess) A
 	if access == nil {
 		return out
 	}
+	out.Score = scorer.Principal(access.Principal).Score
+	out.ServiceAccountKeys = access.Keys
+	if access.DirectAccess != nil {
+		out.DeniedAccess = append(out.DeniedAccess, access.DirectAccess.Denied...)
+	}
+	out.DeniedAccess = append(out.DeniedAccess, access.Denied...)
 
 	// Direct Access
 	if access.DirectAccess != nil {
@@ -246,7 +291,9 @@ func ConvertToAnalyzeOutput(account string, access *analyzer.TransitiveAccess) A
 			resOut := ResourceOutput{
 				ResourceID:   resID,
 				ResourceType: meta.Type,
+				Score:        scorer.Resource(resID).Score,
 				Roles:        roles,
+				GroupPaths:   meta.GroupPaths,
 			}
 			if len(tfAddrs) > 0 {
 				resOut.TerraformAddrs = tfAddrs
@@ -304,8 +351,12 @@ func ConvertToAnalyzeOutput(account string, access *analyzer.TransitiveAccess) A
 			transOut := TransitiveAccessOutput{
 				ResourceID:   resID,
 				ResourceType: details.Resource.Type,
+				Score:        scorer.Resource(resID).Score,
 				Roles:        roles,
 				ViaChain:     details.ViaChain,
+				ViaEdges:     details.ViaEdges,
+				ChainType:    details.ChainType,
+				Unconfirmed:  details.ChainType == analyzer.ChainActAsUnconfirmed,
 			}
 			if len(tfAddrs) > 0 {
 				transOut.TerraformAddrs = tfAddrs
@@ -314,11 +365,31 @@ func ConvertToAnalyzeOutput(account string, access *analyzer.TransitiveAccess) A
 		}
 	}
 
+	// Hierarchical access gained through impersonation
+	for _, via := range access.SortedHierarchicalAccess() {
+		resTypes := make([]string, 0, len(via.ResourceTypes))
+		for rt := range via.ResourceTypes {
+			resTypes = append(resTypes, rt)
+		}
+		sort.Strings(resTypes)
+
+		out.TransitiveHierarchicalAccess = append(out.TransitiveHierarchicalAccess, TransitiveHierarchicalOutput{
+			Scope:         via.Scope,
+			ResourceTypes: resTypes,
+			Roles:         via.Roles(),
+			Entries:       via.Entries,
+			ViaChain:      via.ViaChain,
+			ViaEdges:      via.ViaEdges,
+			ChainType:     via.ChainType,
+			Unconfirmed:   via.ChainType == analyzer.ChainActAsUnconfirmed,
+		})
+	}
+
 	return out
 }
 
 // ConvertToValidateOutput converts validation report to ValidateOutput
-func ConvertToValidateOutput(report *policy.ValidationReport) ValidateOutput {
+func ConvertToValidateOutput(report *policy.ValidationReport, scorer *analyzer.Scorer) ValidateOutput {
 	out := ValidateOutput{
 		Command:    "validate",
 		Timestamp:  time.Now().UTC(),
@@ -336,11 +407,14 @@ func ConvertToValidateOutput(report *policy.ValidationReport) ValidateOutput {
 			Policy:    v.PolicyName,
 			Severity:  string(v.Severity),
 			Principal: v.Principal,
+			Score:     scorer.Principal(v.Principal).Score,
 			Resource:  v.Resource,
 			Role:      v.Role,
 			Message:   v.Message,
 		})
 	}
 
+	out.RedundantGrants = report.RedundantGrants
+
 	return out
 }
@@ -0,0 +1,32 @@
+package output
+
+import (
+	"time"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/schema"
+)
+
+// LintOutput represents the JSON output for the lint command
+type LintOutput struct {
+	Command      string         `json:"command"`
+	Timestamp    time.Time      `json:"timestamp"`
+	Status       string         `json:"status"` // "passed" or "failed"
+	FilesChecked []string       `json:"files_checked"`
+	Issues       []schema.Issue `json:"issues"`
+}
+
+// ConvertToLintOutput converts lint results to LintOutput
+func ConvertToLintOutput(files []string, issues []schema.Issue) LintOutput {
+	out := LintOutput{
+		Command:      "lint",
+		Timestamp:    time.Now().UTC(),
+		Status:       "passed",
+		FilesChecked: files,
+		Issues:       []schema.Issue{},
+	}
+	if len(issues) > 0 {
+		out.Status = "failed"
+		out.Issues = issues
+	}
+	return out
+}
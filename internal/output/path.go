@@ -0,0 +1,75 @@
+package output
+
+import (
+	"time"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+// PathOutput represents the JSON output for the path command
+type PathOutput struct {
+	Command    string                       `json:"command"`
+	Timestamp  time.Time                    `json:"timestamp"`
+	Source     SourceInfo                   `json:"source"`
+	From       string                       `json:"from"`
+	To         string                       `json:"to"`
+	Routes     []analyzer.Route             `json:"routes"`
+	Truncated  bool                         `json:"truncated,omitempty"` // Impersonation paths were cut off at analyzer.MaxImpersonationPaths
+	Reachable  []string                     `json:"reachable,omitempty"`
+	NearMisses []analyzer.NearMiss          `json:"near_misses,omitempty"`
+	Bindings   map[string]PathBindingSource `json:"bindings"` // Source of each binding the routes and near misses refer to, by Terraform address
+	Scores     Scores                       `json:"scores"`   // of the two ends
+}
+
+// PathBindingSource is where a binding is declared and the condition it carries
+type PathBindingSource struct {
+	Location  string            `json:"location,omitempty"` // "file:line", empty for plans
+	Condition *parser.Condition `json:"condition,omitempty"`
+}
+
+// ConvertToPathOutput converts path results to PathOutput
+func ConvertToPathOutput(result *analyzer.PathResult, bindings []parser.IAMBinding, source SourceInfo, scorer *analyzer.Scorer) PathOutput {
+	referenced := make(map[string]bool)
+	for _, route := range result.Routes {
+		if route.Impersonation != nil {
+			for _, e := range route.Impersonation.Edges {
+				referenced[e.SourceAddress] = true
+			}
+		}
+		if route.Grant != nil {
+			referenced[route.Grant.Address] = true
+		}
+	}
+	for _, m := range result.NearMisses {
+		referenced[m.Binding] = true
+	}
+
+	sources := make(map[string]PathBindingSource)
+	for _, b := range bindings {
+		if b.TerraformAddr != "" && referenced[b.TerraformAddr] {
+			sources[b.TerraformAddr] = PathBindingSource{Location: b.Location, Condition: b.Condition}
+		}
+	}
+
+	principals, resources := []string{result.From}, []string{}
+	if analyzer.IsPrincipal(result.To) {
+		principals = append(principals, result.To)
+	} else {
+		resources = append(resources, result.To)
+	}
+
+	return PathOutput{
+		Command:    "path",
+		Timestamp:  time.Now().UTC(),
+		Source:     source,
+		From:       result.From,
+		To:         result.To,
+		Routes:     result.Routes,
+		Truncated:  result.Truncated,
+		Reachable:  result.Reachable,
+		NearMisses: result.NearMisses,
+		Bindings:   sources,
+		Scores:     newScores(scorer, principals, resources),
+	}
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"time"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+)
+
+// RankOutput represents the JSON output for the rank command
+type RankOutput struct {
+	Command    string                    `json:"command"`
+	Timestamp  time.Time                 `json:"timestamp"`
+	Source     SourceInfo                `json:"source"`
+	Weights    analyzer.ScoreWeights     `json:"weights"`
+	Principals []analyzer.PrincipalScore `json:"principals"`
+	Resources  []analyzer.ResourceScore  `json:"resources"`
+	Summary    RankSummary               `json:"summary"`
+}
+
+// RankSummary counts what was scored, before the --top limit
+type RankSummary struct {
+	Principals int `json:"principals"`
+	Resources  int `json:"resources"`
+}
+
+// Scores holds the blast-radius scores of the principals and resources an output refers to, see the rank command
+type Scores struct {
+	Principals map[string]float64 `json:"principals,omitempty"`
+	Resources  map[string]float64 `json:"resources,omitempty"`
+}
+
+// newScores looks up the scores of the given principals and resources, or none without a scorer
+func newScores(scorer *analyzer.Scorer, principals, resources []string) Scores {
+	scores := Scores{}
+	if scorer == nil {
+		return scores
+	}
+	for _, p := range principals {
+		if scores.Principals == nil {
+			scores.Principals = make(map[string]float64)
+		}
+		scores.Principals[p] = scorer.Principal(p).Score
+	}
+	for _, r := range resources {
+		if scores.Resources == nil {
+			scores.Resources = make(map[string]float64)
+		}
+		scores.Resources[r] = scorer.Resource(r).Score
+	}
+	return scores
+}
+
+// ConvertToRankOutput converts the scores of an analysis to RankOutput, keeping the top principals and
+// resources (all of them when top is not positive)
+func ConvertToRankOutput(scorer *analyzer.Scorer, source SourceInfo, top int) RankOutput {
+	principals := scorer.RankPrincipals()
+	resources := scorer.RankResources()
+	out := RankOutput{
+		Command:    "rank",
+		Timestamp:  time.Now().UTC(),
+		Source:     source,
+		Weights:    scorer.Weights(),
+		Principals: []analyzer.PrincipalScore{},
+		Resources:  []analyzer.ResourceScore{},
+		Summary:    RankSummary{Principals: len(principals), Resources: len(resources)},
+	}
+	if top > 0 && len(principals) > top {
+		principals = principals[:top]
+	}
+	if top > 0 && len(resources) > top {
+		resources = resources[:top]
+	}
+	out.Principals = append(out.Principals, principals...)
+	out.Resources = append(out.Resources, resources...)
+	return out
+}
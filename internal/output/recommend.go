@@ -0,0 +1,55 @@
+package output
+
+import (
+	"time"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+)
+
+// RecommendOutput represents the JSON output for the recommend command
+type RecommendOutput struct {
+	Command         string                    `json:"command"`
+	Timestamp       time.Time                 `json:"timestamp"`
+	Source          SourceInfo                `json:"source"`
+	Recommendations []analyzer.Recommendation `json:"recommendations"`
+	Summary         RecommendSummary          `json:"summary"`
+	Scores          Scores                    `json:"scores"` // of the principals holding broad roles
+}
+
+// RecommendSummary counts the recommendations reported by recommend
+type RecommendSummary struct {
+	Recommendations int `json:"recommendations"`
+	Principals      int `json:"principals"`
+	Suggestions     int `json:"suggestions"`
+	Unused          int `json:"unused"` // broad grants reaching nothing declared their principal needs
+}
+
+// ConvertToRecommendOutput converts least-privilege recommendations to RecommendOutput
+func ConvertToRecommendOutput(recs []analyzer.Recommendation, source SourceInfo, scorer *analyzer.Scorer) RecommendOutput {
+	summary := RecommendSummary{Recommendations: len(recs)}
+	seen := make(map[string]bool)
+	var principals []string
+	for _, r := range recs {
+		if !seen[r.Principal] {
+			seen[r.Principal] = true
+			principals = append(principals, r.Principal)
+		}
+		summary.Suggestions += len(r.Suggestions)
+		if !r.Required && len(r.Suggestions) == 0 && len(r.Uncovered) == 0 {
+			summary.Unused++
+		}
+	}
+	summary.Principals = len(principals)
+	if recs == nil {
+		recs = []analyzer.Recommendation{}
+	}
+
+	return RecommendOutput{
+		Command:         "recommend",
+		Timestamp:       time.Now().UTC(),
+		Source:          source,
+		Recommendations: recs,
+		Summary:         summary,
+		Scores:          newScores(scorer, principals, nil),
+	}
+}
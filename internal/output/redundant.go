@@ -0,0 +1,55 @@
+package output
+
+import (
+	"time"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+)
+
+// RedundantOutput represents the JSON output for the redundant command
+type RedundantOutput struct {
+	Command   string                    `json:"command"`
+	Timestamp time.Time                 `json:"timestamp"`
+	Source    SourceInfo                `json:"source"`
+	Redundant []analyzer.RedundantGrant `json:"redundant"`
+	Summary   RedundantSummary          `json:"summary"`
+	Scores    Scores                    `json:"scores"` // of the principals with redundant grants
+}
+
+// RedundantSummary counts the redundant grants reported by redundant
+type RedundantSummary struct {
+	Total      int            `json:"total"`
+	Principals int            `json:"principals"`
+	Bindings   int            `json:"bindings"` // distinct Terraform addresses with a redundant grant
+	ByKind     map[string]int `json:"by_kind"`
+}
+
+// ConvertToRedundantOutput converts redundant grants to RedundantOutput
+func ConvertToRedundantOutput(redundant []analyzer.RedundantGrant, source SourceInfo, scorer *analyzer.Scorer) RedundantOutput {
+	summary := RedundantSummary{Total: len(redundant), ByKind: make(map[string]int)}
+	seen := make(map[string]bool)
+	addresses := make(map[string]bool)
+	var principals []string
+	for _, r := range redundant {
+		if !seen[r.Principal] {
+			seen[r.Principal] = true
+			principals = append(principals, r.Principal)
+		}
+		addresses[r.Grant.ResourceAddress] = true
+		summary.ByKind[r.Kind]++
+	}
+	summary.Principals = len(principals)
+	summary.Bindings = len(addresses)
+	if redundant == nil {
+		redundant = []analyzer.RedundantGrant{}
+	}
+
+	return RedundantOutput{
+		Command:   "redundant",
+		Timestamp: time.Now().UTC(),
+		Source:    source,
+		Redundant: redundant,
+		Summary:   summary,
+		Scores:    newScores(scorer, principals, nil),
+	}
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"time"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+)
+
+// WhoCanOutput represents the JSON output for the who-can command
+type WhoCanOutput struct {
+	Command         string                      `json:"command"`
+	Timestamp       time.Time                   `json:"timestamp"`
+	Source          SourceInfo                  `json:"source"`
+	ResourcePattern string                      `json:"resource_pattern"`
+	Role            string                      `json:"role,omitempty"`       // --role filter
+	Permission      string                      `json:"permission,omitempty"` // --permission filter
+	Accessors       []analyzer.ResourceAccessor `json:"accessors"`
+	Summary         WhoCanSummary               `json:"summary"`
+	Scores          Scores                      `json:"scores"` // of the accessors and the resources they reach
+}
+
+// WhoCanSummary counts the resources and principals reported by who-can
+type WhoCanSummary struct {
+	Resources  int            `json:"resources"`
+	Principals int            `json:"principals"`
+	ByVia      map[string]int `json:"by_via"`     // number of accessors per way of access
+	Unverified int            `json:"unverified"` // accessors whose role only may grant the --permission filter
+}
+
+// ConvertToWhoCanOutput converts who-can results to WhoCanOutput
+func ConvertToWhoCanOutput(accessors []analyzer.ResourceAccessor, source SourceInfo, resourcePattern, role, permission string, scorer *analyzer.Scorer) WhoCanOutput {
+	resources := make(map[string]bool)
+	principals := make(map[string]bool)
+	byVia := make(map[string]int)
+	unverified := 0
+	var scoredPrincipals, scoredResources []string
+	for _, a := range accessors {
+		if !resources[a.ResourceID] {
+			scoredResources = append(scoredResources, a.ResourceID)
+		}
+		if !principals[a.Principal] {
+			scoredPrincipals = append(scoredPrincipals, a.Principal)
+		}
+		resources[a.ResourceID] = true
+		principals[a.Principal] = true
+		byVia[a.Via]++
+		if a.Unverified {
+			unverified++
+		}
+	}
+	if accessors == nil {
+		accessors = []analyzer.ResourceAccessor{}
+	}
+
+	return WhoCanOutput{
+		Command:         "who-can",
+		Timestamp:       time.Now().UTC(),
+		Source:          source,
+		ResourcePattern: resourcePattern,
+		Role:            role,
+		Permission:      permission,
+		Accessors:       accessors,
+		Summary: WhoCanSummary{
+			Resources:  len(resources),
+			Principals: len(principals),
+			ByVia:      byVia,
+			Unverified: unverified,
+		},
+		Scores: newScores(scorer, scoredPrincipals, scoredResources),
+	}
+}
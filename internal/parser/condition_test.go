@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var conditionDefs = []ResourceDefinition{
+	{
+		Type:          "google_service_account_iam_member",
+		ResourceLevel: "resource",
+		FieldMappings: FieldMapping{
+			ResourceID: "service_account_id",
+			Role:       "role",
+			Member:     "member",
+		},
+	},
+}
+
+func TestParseDir_Condition(t *testing.T) {
+	tmpDir := t.TempDir()
+	mainTF := `
+variable "expiry" { default = "2027-01-01T00:00:00Z" }
+
+resource "google_service_account_iam_member" "temporary" {
+  service_account_id = "projects/p/serviceAccounts/deployer@p.iam.gserviceaccount.com"
+  role               = "roles/iam.serviceAccountTokenCreator"
+  member             = "user:alice@example.com"
+
+  condition {
+    title      = "expires"
+    expression = "request.time < timestamp(\"${var.expiry}\")"
+  }
+}
+
+resource "google_service_account_iam_member" "permanent" {
+  service_account_id = "projects/p/serviceAccounts/deployer@p.iam.gserviceaccount.com"
+  role               = "roles/iam.serviceAccountUser"
+  member             = "user:bob@example.com"
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(mainTF), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bindings, err := ParseDir(tmpDir, "", conditionDefs, nil)
+	if err != nil {
+		t.Fatalf("ParseDir failed: %v", err)
+	}
+
+	got := make(map[string]*Condition)
+	for _, b := range bindings {
+		got[b.TerraformAddr] = b.Condition
+	}
+	want := map[string]*Condition{
+		"google_service_account_iam_member.temporary": {Title: "expires", Expression: `request.time < timestamp("2027-01-01T00:00:00Z")`},
+		"google_service_account_iam_member.permanent": nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("conditions = %+v, want %+v", got, want)
+	}
+}
+
+func TestParsePlanFile_Condition(t *testing.T) {
+	plan := TerraformPlan{
+		PlannedValues: PlannedValues{
+			RootModule: Module{
+				Resources: []Resource{
+					{
+						Address: "google_service_account_iam_member.temporary",
+						Mode:    "managed",
+						Type:    "google_service_account_iam_member",
+						Values: map[string]interface{}{
+							"service_account_id": "projects/p/serviceAccounts/deployer@p.iam.gserviceaccount.com",
+							"role":               "roles/iam.serviceAccountTokenCreator",
+							"member":             "user:alice@example.com",
+							"condition": []interface{}{
+								map[string]interface{}{"title": "expires", "expression": "request.time < timestamp(\"2027-01-01T00:00:00Z\")"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	planFile := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(planFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bindings, err := ParsePlanFile(planFile, conditionDefs)
+	if err != nil {
+		t.Fatalf("ParsePlanFile failed: %v", err)
+	}
+	if len(bindings) != 1 {
+		t.Fatalf("expected 1 binding, got %d", len(bindings))
+	}
+	want := &Condition{Title: "expires", Expression: `request.time < timestamp("2027-01-01T00:00:00Z")`}
+	if !reflect.DeepEqual(bindings[0].Condition, want) {
+		t.Errorf("Condition = %+v, want %+v", bindings[0].Condition, want)
+	}
+}
@@ -2,18 +2,19 @@ package parser
 
 // ResourceDefinition defines how to extract IAM information from a Terraform resource
 type ResourceDefinition struct {
-	Type          string       `yaml:"type"`           // e.g. "google_project_iam_member"
-	DisplayName   string       `yaml:"display_name"`   // e.g. "BigQuery Datasets" - human readable name
-	ResourceLevel string       `yaml:"resource_level"` // e.g. "project", "folder", "organization", "resource"
-	FieldMappings FieldMapping `yaml:"field_mappings"` // Structured mapping of fields
+	Type          string       `yaml:"type" json:"type" schema:"required"`                                                                // e.g. "google_project_iam_member"
+	Category      string       `yaml:"category" json:"category,omitempty"`                                                                // e.g. "storage" - grouping used in resources.yaml
+	DisplayName   string       `yaml:"display_name" json:"display_name,omitempty"`                                                        // e.g. "BigQuery Datasets" - human readable name
+	ResourceLevel string       `yaml:"resource_level" json:"resource_level,omitempty" schema:"enum=organization|folder|project|resource"` // e.g. "project", "folder", "organization", "resource"
+	FieldMappings FieldMapping `yaml:"field_mappings" json:"field_mappings"`                                                              // Structured mapping of fields
 }
 
 // FieldMapping defines the HCL attribute names for specific IAM concepts
 type FieldMapping struct {
-	ResourceID string `yaml:"resource_id"` // e.g. "project", "dataset_id", "bucket"
-	Role       string `yaml:"role"`        // e.g. "role"
-	Member     string `yaml:"member"`      // e.g. "member"
-	Members    string `yaml:"members"`     // e.g. "members"
-	Parent     string `yaml:"parent"`      // e.g. "folder_id", "org_id" - parent resource reference
-	PolicyData string `yaml:"policy_data"` // e.g. "policy_data" - for iam_policy resources
+	ResourceID string `yaml:"resource_id" json:"resource_id,omitempty"` // e.g. "project", "dataset_id", "bucket"
+	Role       string `yaml:"role" json:"role,omitempty"`               // e.g. "role"
+	Member     string `yaml:"member" json:"member,omitempty"`           // e.g. "member"
+	Members    string `yaml:"members" json:"members,omitempty"`         // e.g. "members"
+	Parent     string `yaml:"parent" json:"parent,omitempty"`           // e.g. "folder_id", "org_id" - parent resource reference
+	PolicyData string `yaml:"policy_data" json:"policy_data,omitempty"` // e.g. "policy_data" - for iam_policy resources
 }
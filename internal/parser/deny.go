@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// DenyPolicyType is the Terraform resource type of IAM deny policies
+const DenyPolicyType = "google_iam_deny_policy"
+
+// DenyRule is a single rule of an IAM deny policy.
+// Principals and permissions keep the IAM v2 format of the policy
+// (e.g. "principalSet://goog/group/devs@example.com", "storage.googleapis.com/buckets.delete").
+type DenyRule struct {
+	AttachmentPoint      string     // Decoded parent of the policy (e.g. "cloudresourcemanager.googleapis.com/projects/my-project")
+	DeniedPrincipals     []string   // Principals the rule applies to
+	ExceptionPrincipals  []string   // Principals exempt from the rule
+	DeniedPermissions    []string   // Permissions the rule denies
+	ExceptionPermissions []string   // Permissions exempt from the rule
+	Condition            *Condition // Denial condition, nil when the rule always applies
+	Description          string     // Description of the rule
+	TerraformAddr        string     // Terraform address of the deny policy
+}
+
+// ParseDenyPolicies returns the rules of every google_iam_deny_policy declared in the .tf files under dir.
+// Rules whose attachment point cannot be resolved are kept with an empty AttachmentPoint.
+func ParseDenyPolicies(dir string, tfvarsPath string, ignoredDirs []string) ([]DenyRule, error) {
+	source, err := LoadDir(dir, tfvarsPath, ignoredDirs)
+	if err != nil {
+		return nil, err
+	}
+	return source.DenyPolicies(), nil
+}
+
+// hclDenyRules returns the rules of the deny policies declared in the loaded .tf files
+func (s *Source) hclDenyRules() []DenyRule {
+	traverser := s.traverser
+	var rules []DenyRule
+	for _, file := range s.files {
+		content, _, _ := file.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{
+				{Type: "resource", LabelNames: []string{"type", "name"}},
+			},
+		})
+
+		for _, block := range content.Blocks {
+			if block.Labels[0] != DenyPolicyType {
+				continue
+			}
+			body, ok := block.Body.(*hclsyntax.Body)
+			if !ok {
+				continue
+			}
+
+			address := fmt.Sprintf("%s.%s", block.Labels[0], block.Labels[1])
+			parent := ""
+			if attr, ok := body.Attributes["parent"]; ok {
+				parent = resolveString(attr.Expr, traverser)
+			}
+
+			for _, rulesBlock := range body.Blocks {
+				if rulesBlock.Type != "rules" {
+					continue
+				}
+				rule := DenyRule{AttachmentPoint: decodeAttachmentPoint(parent), TerraformAddr: address}
+				if attr, ok := rulesBlock.Body.Attributes["description"]; ok {
+					rule.Description = resolveString(attr.Expr, traverser)
+				}
+				for _, denyBlock := range rulesBlock.Body.Blocks {
+					if denyBlock.Type != "deny_rule" {
+						continue
+					}
+					attrs := denyBlock.Body.Attributes
+					rule.DeniedPrincipals = resolveStringList(attrs["denied_principals"], traverser)
+					rule.ExceptionPrincipals = resolveStringList(attrs["exception_principals"], traverser)
+					rule.DeniedPermissions = resolveStringList(attrs["denied_permissions"], traverser)
+					rule.ExceptionPermissions = resolveStringList(attrs["exception_permissions"], traverser)
+					for _, conditionBlock := range denyBlock.Body.Blocks {
+						if conditionBlock.Type != "denial_condition" {
+							continue
+						}
+						rule.Condition = &Condition{}
+						if attr, ok := conditionBlock.Body.Attributes["title"]; ok {
+							rule.Condition.Title = resolveString(attr.Expr, traverser)
+						}
+						if attr, ok := conditionBlock.Body.Attributes["expression"]; ok {
+							rule.Condition.Expression = resolveString(attr.Expr, traverser)
+						}
+					}
+				}
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	return rules
+}
+
+// ParsePlanDenyPolicies returns the rules of every google_iam_deny_policy in a Terraform plan JSON file
+func ParsePlanDenyPolicies(planPath string) ([]DenyRule, error) {
+	source, err := LoadPlan(planPath)
+	if err != nil {
+		return nil, err
+	}
+	return source.DenyPolicies(), nil
+}
+
+// planModuleDenyRules recursively collects deny rules from a module and its children
+func planModuleDenyRules(module Module) []DenyRule {
+	var rules []DenyRule
+
+	for _, resource := range module.Resources {
+		if resource.Mode != "managed" || resource.Type != DenyPolicyType {
+			continue
+		}
+		parent := decodeAttachmentPoint(GetStringFromMap(resource.Values, "parent"))
+
+		ruleList, _ := resource.Values["rules"].([]interface{})
+		for _, r := range ruleList {
+			ruleValues, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			rule := DenyRule{
+				AttachmentPoint: parent,
+				Description:     GetStringFromMap(ruleValues, "description"),
+				TerraformAddr:   resource.Address,
+			}
+			if denyValues := firstNestedBlock(ruleValues, "deny_rule"); denyValues != nil {
+				rule.DeniedPrincipals = GetListFromMap(denyValues, "denied_principals")
+				rule.ExceptionPrincipals = GetListFromMap(denyValues, "exception_principals")
+				rule.DeniedPermissions = GetListFromMap(denyValues, "denied_permissions")
+				rule.ExceptionPermissions = GetListFromMap(denyValues, "exception_permissions")
+				if conditionValues := firstNestedBlock(denyValues, "denial_condition"); conditionValues != nil {
+					rule.Condition = &Condition{
+						Title:      GetStringFromMap(conditionValues, "title"),
+						Expression: GetStringFromMap(conditionValues, "expression"),
+					}
+				}
+			}
+			rules = append(rules, rule)
+		}
+	}
+
+	for _, child := range module.ChildModules {
+		rules = append(rules, planModuleDenyRules(child)...)
+	}
+
+	return rules
+}
+
+// firstNestedBlock returns the first object of a nested block, which the plan renders as a list of objects
+func firstNestedBlock(values map[string]interface{}, key string) map[string]interface{} {
+	list, ok := values[key].([]interface{})
+	if !ok || len(list) == 0 {
+		return nil
+	}
+	block, _ := list[0].(map[string]interface{})
+	return block
+}
+
+// decodeAttachmentPoint undoes the URL encoding deny policy parents are written with
+// (e.g. "cloudresourcemanager.googleapis.com%2Fprojects%2Fmy-project")
+func decodeAttachmentPoint(parent string) string {
+	if decoded, err := url.QueryUnescape(parent); err == nil {
+		return decoded
+	}
+	return parent
+}
+
+// resolveString resolves an expression to a string, or "" if it cannot be resolved
+func resolveString(expr hcl.Expression, traverser *ConfigTraverser) string {
+	val, err := traverser.ResolveExpression(expr)
+	if err != nil || val.Type() != cty.String || !val.IsKnown() || val.IsNull() {
+		return ""
+	}
+	return val.AsString()
+}
+
+// resolveStringList resolves a list or tuple attribute to its string elements, skipping any that cannot be resolved
+func resolveStringList(attr *hclsyntax.Attribute, traverser *ConfigTraverser) []string {
+	if attr == nil {
+		return nil
+	}
+	val, err := traverser.ResolveExpression(attr.Expr)
+	if err != nil || !val.IsKnown() || val.IsNull() || !(val.Type().IsTupleType() || val.Type().IsListType() || val.Type().IsSetType()) {
+		return nil
+	}
+	var list []string
+	it := val.ElementIterator()
+	for it.Next() {
+		_, v := it.Element()
+		if v.Type() == cty.String && v.IsKnown() && !v.IsNull() {
+			list = append(list, strings.TrimSpace(v.AsString()))
+		}
+	}
+	return list
+}
@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDenyPolicies(t *testing.T) {
+	tmpDir := t.TempDir()
+	mainTF := `
+variable "project" {
+  default = "my-project"
+}
+
+resource "google_iam_deny_policy" "protect" {
+  parent = urlencode("cloudresourcemanager.googleapis.com/projects/${var.project}")
+  name   = "protect"
+
+  rules {
+    description = "no token minting"
+    deny_rule {
+      denied_principals    = ["principalSet://goog/public:all"]
+      exception_principals = ["principal://goog/subject/admin@example.com"]
+      denied_permissions   = ["iam.googleapis.com/serviceAccounts.getAccessToken"]
+    }
+  }
+
+  rules {
+    deny_rule {
+      denied_principals  = ["principalSet://goog/group/devs@example.com"]
+      denied_permissions = ["storage.googleapis.com/objects.delete"]
+      denial_condition {
+        title      = "prod"
+        expression = "resource.matchTag('123/env', 'prod')"
+      }
+    }
+  }
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(mainTF), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := ParseDenyPolicies(tmpDir, "", nil)
+	if err != nil {
+		t.Fatalf("ParseDenyPolicies() error = %v", err)
+	}
+
+	want := []DenyRule{
+		{
+			AttachmentPoint:     "cloudresourcemanager.googleapis.com/projects/my-project",
+			DeniedPrincipals:    []string{"principalSet://goog/public:all"},
+			ExceptionPrincipals: []string{"principal://goog/subject/admin@example.com"},
+			DeniedPermissions:   []string{"iam.googleapis.com/serviceAccounts.getAccessToken"},
+			Description:         "no token minting",
+			TerraformAddr:       "google_iam_deny_policy.protect",
+		},
+		{
+			AttachmentPoint:   "cloudresourcemanager.googleapis.com/projects/my-project",
+			DeniedPrincipals:  []string{"principalSet://goog/group/devs@example.com"},
+			DeniedPermissions: []string{"storage.googleapis.com/objects.delete"},
+			Condition:         &Condition{Title: "prod", Expression: "resource.matchTag('123/env', 'prod')"},
+			TerraformAddr:     "google_iam_deny_policy.protect",
+		},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("ParseDenyPolicies() = %+v, want %+v", rules, want)
+	}
+}
+
+func TestParsePlanDenyPolicies(t *testing.T) {
+	planJSON := `{
+  "planned_values": {
+    "root_module": {
+      "child_modules": [{
+        "resources": [{
+          "address": "module.org.google_iam_deny_policy.protect",
+          "mode": "managed",
+          "type": "google_iam_deny_policy",
+          "values": {
+            "parent": "cloudresourcemanager.googleapis.com%2Forganizations%2F123",
+            "rules": [{
+              "description": "no key creation",
+              "deny_rule": [{
+                "denied_principals": ["principalSet://goog/public:all"],
+                "denied_permissions": ["iam.googleapis.com/serviceAccountKeys.create"],
+                "denial_condition": []
+              }]
+            }]
+          }
+        }]
+      }]
+    }
+  }
+}`
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(planPath, []byte(planJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := ParsePlanDenyPolicies(planPath)
+	if err != nil {
+		t.Fatalf("ParsePlanDenyPolicies() error = %v", err)
+	}
+
+	want := []DenyRule{{
+		AttachmentPoint:   "cloudresourcemanager.googleapis.com/organizations/123",
+		DeniedPrincipals:  []string{"principalSet://goog/public:all"},
+		DeniedPermissions: []string{"iam.googleapis.com/serviceAccountKeys.create"},
+		Description:       "no key creation",
+		TerraformAddr:     "module.org.google_iam_deny_policy.protect",
+	}}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("ParsePlanDenyPolicies() = %+v, want %+v", rules, want)
+	}
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// ResourceRef is a managed resource declared in Terraform, independent of any definition
+type ResourceRef struct {
+	Type       string   // Terraform resource type (e.g. "google_cloud_run_v2_service_iam_member")
+	Address    string   // Terraform address (e.g. "google_cloud_run_v2_service_iam_member.invoker")
+	Attributes []string // Attribute names set on the resource, sorted
+}
+
+// ListResources returns every managed resource block declared in the .tf files under dir
+func ListResources(dir string, ignoredDirs []string) ([]ResourceRef, error) {
+	files, err := loadHCLFiles(dir, ignoredDirs)
+	if err != nil {
+		return nil, err
+	}
+	return (&Source{dir: dir, files: files}).ListResources(), nil
+}
+
+// hclResourceRefs returns every managed resource block declared in the loaded .tf files
+func (s *Source) hclResourceRefs() []ResourceRef {
+	var refs []ResourceRef
+	for _, file := range s.files {
+		content, _, _ := file.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{
+				{Type: "resource", LabelNames: []string{"type", "name"}},
+			},
+		})
+
+		for _, block := range content.Blocks {
+			attrs, _ := block.Body.JustAttributes()
+			names := make([]string, 0, len(attrs))
+			for name := range attrs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			refs = append(refs, ResourceRef{
+				Type:       block.Labels[0],
+				Address:    fmt.Sprintf("%s.%s", block.Labels[0], block.Labels[1]),
+				Attributes: names,
+			})
+		}
+	}
+
+	return refs
+}
+
+// ListPlanResources returns every managed resource in a Terraform plan JSON file
+func ListPlanResources(planPath string) ([]ResourceRef, error) {
+	source, err := LoadPlan(planPath)
+	if err != nil {
+		return nil, err
+	}
+	return source.ListResources(), nil
+}
+
+// listModuleResources recursively collects managed resources from a module and its children
+func listModuleResources(module Module) []ResourceRef {
+	var refs []ResourceRef
+
+	for _, resource := range module.Resources {
+		if resource.Mode != "managed" {
+			continue
+		}
+
+		names := make([]string, 0, len(resource.Values))
+		for name, val := range resource.Values {
+			if val != nil {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		refs = append(refs, ResourceRef{
+			Type:       resource.Type,
+			Address:    resource.Address,
+			Attributes: names,
+		})
+	}
+
+	for _, child := range module.ChildModules {
+		refs = append(refs, listModuleResources(child)...)
+	}
+
+	return refs
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestListResources(t *testing.T) {
+	tmpDir := t.TempDir()
+	mainTF := `
+resource "google_storage_bucket_acl" "acl" {
+  bucket      = "my-bucket"
+  role_entity = ["OWNER:user-a@example.com"]
+}
+
+resource "google_project_iam_member" "binding" {
+  project = "my-project"
+  role    = "roles/viewer"
+  member  = "user:a@example.com"
+}
+
+data "google_iam_policy" "ignored" {}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(mainTF), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := ListResources(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("ListResources() error = %v", err)
+	}
+
+	want := []ResourceRef{
+		{Type: "google_storage_bucket_acl", Address: "google_storage_bucket_acl.acl", Attributes: []string{"bucket", "role_entity"}},
+		{Type: "google_project_iam_member", Address: "google_project_iam_member.binding", Attributes: []string{"member", "project", "role"}},
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("ListResources() = %+v, want %+v", refs, want)
+	}
+}
+
+func TestListPlanResources(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.json")
+
+	plan := TerraformPlan{
+		PlannedValues: PlannedValues{
+			RootModule: Module{
+				Resources: []Resource{
+					{Address: "data.google_iam_policy.p", Mode: "data", Type: "google_iam_policy"},
+				},
+				ChildModules: []Module{{
+					Resources: []Resource{{
+						Address: "module.app.google_storage_bucket_acl.acl",
+						Mode:    "managed",
+						Type:    "google_storage_bucket_acl",
+						Values: map[string]interface{}{
+							"bucket":         "my-bucket",
+							"predefined_acl": nil,
+						},
+					}},
+				}},
+			},
+		},
+	}
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(planFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := ListPlanResources(planFile)
+	if err != nil {
+		t.Fatalf("ListPlanResources() error = %v", err)
+	}
+
+	want := []ResourceRef{
+		{Type: "google_storage_bucket_acl", Address: "module.app.google_storage_bucket_acl.acl", Attributes: []string{"bucket"}},
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("ListPlanResources() = %+v, want %+v", refs, want)
+	}
+}
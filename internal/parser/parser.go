@@ -9,109 +9,40 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 )
 
 // IAMBinding represents a single IAM binding found in Terraform
 type IAMBinding struct {
-	ResourceID    string   // The identifier of the resource (e.g. project ID, bucket name)
-	ResourceType  string   // The type of the resource (e.g. "google_project", "google_storage_bucket") - inferred from TF resource type
-	ResourceLevel string   // The hierarchy level: "organization", "folder", "project", "resource"
-	Role          string   // The IAM role (e.g. "roles/storage.admin")
-	Members       []string // The principals granted this role
-	ParentID      string   // Parent resource ID (e.g. folder ID, org ID)
-	ParentType    string   // Parent resource type: "organization", "folder", "project"
-	TerraformAddr string   // Full terraform address (e.g. "google_project_iam_member.alice")
+	ResourceID    string     // The identifier of the resource (e.g. project ID, bucket name)
+	ResourceType  string     // The type of the resource (e.g. "google_project", "google_storage_bucket") - inferred from TF resource type
+	ResourceLevel string     // The hierarchy level: "organization", "folder", "project", "resource"
+	Role          string     // The IAM role (e.g. "roles/storage.admin")
+	Members       []string   // The principals granted this role
+	ParentID      string     // Parent resource ID (e.g. folder ID, org ID)
+	ParentType    string     // Parent resource type: "organization", "folder", "project"
+	TerraformAddr string     // Full terraform address (e.g. "google_project_iam_member.alice")
+	Condition     *Condition // IAM condition attached to the binding, nil when unconditional
+	Location      string     // "file:line" of the resource block, relative to the scanned directory; empty for plans
 }
 
 // ParseDir scans a directory recursively for Terraform files and parses them for IAM bindings based on definitions
 // It now also loads variables and resolves references.
 func ParseDir(dir string, tfvarsPath string, definitions []ResourceDefinition, ignoredDirs []string) ([]IAMBinding, error) {
-	// 1. Load Variables (Root level only for now)
-	vars, err := LoadVariables(dir, tfvarsPath)
+	source, err := LoadDir(dir, tfvarsPath, ignoredDirs)
 	if err != nil {
-		fmt.Printf("Warning: failed to load variables: %v\n", err)
-	}
-
-	// 2. Load all files recursively
-	parser := hclparse.NewParser()
-	var parsedFiles []*hcl.File
-	// var fileNames []string // Unused for now
-
-	// Prepare ignored map for faster lookup
-	ignoredMap := make(map[string]bool)
-	for _, d := range ignoredDirs {
-		ignoredMap[d] = true
-	}
-	// Always ignore .git and .terraform
-	ignoredMap[".git"] = true
-	ignoredMap[".terraform"] = true
-
-	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if d.IsDir() {
-			if ignoredMap[d.Name()] {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		if strings.HasSuffix(d.Name(), ".tf") {
-			file, diags := parser.ParseHCLFile(path)
-			if diags.HasErrors() {
-				fmt.Printf("Warning: failed to parse file %s: %s\n", path, diags.Error())
-				return nil // Continue walking
-			}
-			parsedFiles = append(parsedFiles, file)
-			// fileNames = append(fileNames, d.Name())
-		}
-		return nil
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to scan directory: %w", err)
-	}
-
-	// 3. Initialize Traverser
-	traverser := NewConfigTraverser(parsedFiles, vars)
-
-	// 4. Extract Default Project from Provider
-	defaultProject := ""
-	for _, file := range parsedFiles {
-		content, _, _ := file.Body.PartialContent(&hcl.BodySchema{
-			Blocks: []hcl.BlockHeaderSchema{
-				{Type: "provider", LabelNames: []string{"name"}},
-			},
-		})
-		for _, block := range content.Blocks {
-			if block.Type == "provider" && len(block.Labels) == 1 && block.Labels[0] == "google" {
-				// Check for project attribute
-				blockContent, _, _ := block.Body.PartialContent(&hcl.BodySchema{
-					Attributes: []hcl.AttributeSchema{
-						{Name: "project", Required: false},
-					},
-				})
-				if attr, exists := blockContent.Attributes["project"]; exists {
-					val, err := traverser.ResolveExpression(attr.Expr)
-					if err == nil && val.Type() == cty.String {
-						defaultProject = val.AsString()
-						break
-					}
-				}
-			}
-		}
-		if defaultProject != "" {
-			break
-		}
+		return nil, err
 	}
+	return source.Bindings(definitions), nil
+}
 
-	// 5. Extract Bindings
+// hclBindings extracts the bindings of the loaded .tf files
+func (s *Source) hclBindings(definitions []ResourceDefinition) []IAMBinding {
+	dir, traverser, defaultProject := s.dir, s.traverser, s.defaultProject
 	var bindings []IAMBinding
 
-	for i, file := range parsedFiles {
+	for i, file := range s.files {
 		// Use file.Body.Content to find IAM resources
 		content, _, _ := file.Body.PartialContent(&hcl.BodySchema{
 			Blocks: []hcl.BlockHeaderSchema{
@@ -122,17 +53,18 @@ func ParseDir(dir string, tfvarsPath string, definitions []ResourceDefinition, i
 		for _, block := range content.Blocks {
 			if block.Type == "resource" {
 				resourceType := block.Labels[0]
+				start := len(bindings)
 				// Check against definitions
 				for _, def := range definitions {
 					if resourceType == def.Type {
 						// Check for for_each or count meta-arguments
-						attrs, _ := block.Body.JustAttributes()
+						attrs, _ := resourceAttributes(block)
 
 						if forEachAttr, hasForEach := attrs["for_each"]; hasForEach {
 							// Expand for_each
 							expanded, err := expandForEach(block, def, traverser, defaultProject, forEachAttr)
 							if err != nil {
-								fmt.Printf("Warning: failed to expand for_each in %s: %v\n", resourceType, err)
+								fmt.Fprintf(os.Stderr, "Warning: failed to expand for_each in %s: %v\n", resourceType, err)
 								continue
 							}
 							bindings = append(bindings, expanded...)
@@ -140,7 +72,7 @@ func ParseDir(dir string, tfvarsPath string, definitions []ResourceDefinition, i
 							// Expand count
 							expanded, err := expandCount(block, def, traverser, defaultProject, countAttr)
 							if err != nil {
-								fmt.Printf("Warning: failed to expand count in %s: %v\n", resourceType, err)
+								fmt.Fprintf(os.Stderr, "Warning: failed to expand count in %s: %v\n", resourceType, err)
 								continue
 							}
 							bindings = append(bindings, expanded...)
@@ -156,12 +88,69 @@ func ParseDir(dir string, tfvarsPath string, definitions []ResourceDefinition, i
 						break // Matched definition
 					}
 				}
+				location := blockLocation(dir, block)
+				for j := start; j < len(bindings); j++ {
+					bindings[j].Location = location
+				}
 			}
 		}
 		_ = i
 	}
 
-	return bindings, nil
+	return bindings
+}
+
+// blockLocation returns "file:line" of a block's definition, with the file relative to dir when possible
+func blockLocation(dir string, block *hcl.Block) string {
+	filename := block.DefRange.Filename
+	if rel, err := filepath.Rel(dir, filename); err == nil {
+		filename = rel
+	}
+	return fmt.Sprintf("%s:%d", filepath.ToSlash(filename), block.DefRange.Start.Line)
+}
+
+// loadHCLFiles parses every .tf file under dir, skipping ignored directory names
+func loadHCLFiles(dir string, ignoredDirs []string) ([]*hcl.File, error) {
+	parser := hclparse.NewParser()
+	var parsedFiles []*hcl.File
+
+	// Prepare ignored map for faster lookup
+	ignoredMap := make(map[string]bool)
+	for _, d := range ignoredDirs {
+		ignoredMap[d] = true
+	}
+	// Always ignore .git and .terraform
+	ignoredMap[".git"] = true
+	ignoredMap[".terraform"] = true
+
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if ignoredMap[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if strings.HasSuffix(d.Name(), ".tf") {
+			file, diags := parser.ParseHCLFile(path)
+			if diags.HasErrors() {
+				fmt.Fprintf(os.Stderr, "Warning: failed to parse file %s: %s\n", path, diags.Error())
+				return nil // Continue walking
+			}
+			parsedFiles = append(parsedFiles, file)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan directory: %w", err)
+	}
+
+	return parsedFiles, nil
 }
 
 // expandForEach expands a resource with for_each into multiple IAMBindings
@@ -256,10 +245,11 @@ func expandCount(block *hcl.Block, def ResourceDefinition, traverser *ConfigTrav
 }
 
 func extractIAMResource(block *hcl.Block, def ResourceDefinition, traverser *ConfigTraverser, defaultProject string) ([]IAMBinding, error) {
-	attrs, diags := block.Body.JustAttributes()
+	attrs, diags := resourceAttributes(block)
 	if diags.HasErrors() {
 		return nil, diags
 	}
+	condition := extractCondition(block, traverser)
 
 	// Common fields extraction
 	resourceID := defaultProject
@@ -285,6 +275,11 @@ func extractIAMResource(block *hcl.Block, def ResourceDefinition, traverser *Con
 	if hclName := def.FieldMappings.ResourceID; hclName != "" {
 		if val := getString(hclName); val != "" {
 			resourceID = val
+		} else if attr, ok := attrs[hclName]; ok && def.ResourceLevel == "folder" {
+			// A folder created in the same configuration has no ID before apply; use its address
+			if ref := referencedResource(attr.Expr); ref != "" {
+				resourceID = ref
+			}
 		}
 	}
 
@@ -300,6 +295,11 @@ func extractIAMResource(block *hcl.Block, def ResourceDefinition, traverser *Con
 		resourceLevel = "resource"
 	}
 
+	// Resources belong to the project they are created in
+	if resourceLevel == "resource" && parentID == "" {
+		parentID = hclOwningProject(def, attrs, traverser, resourceID, defaultProject)
+	}
+
 	// Determine parent type
 	parentType = DetermineParentType(resourceLevel, parentID)
 
@@ -324,6 +324,7 @@ func extractIAMResource(block *hcl.Block, def ResourceDefinition, traverser *Con
 					ParentID:      parentID,
 					ParentType:    parentType,
 					TerraformAddr: terraformAddr,
+					Condition:     pb.Condition,
 				}
 				bindings = append(bindings, b)
 			}
@@ -339,6 +340,7 @@ func extractIAMResource(block *hcl.Block, def ResourceDefinition, traverser *Con
 		ParentID:      parentID,
 		ParentType:    parentType,
 		TerraformAddr: terraformAddr,
+		Condition:     condition,
 	}
 
 	// Role
@@ -377,3 +379,79 @@ func extractIAMResource(block *hcl.Block, def ResourceDefinition, traverser *Con
 
 	return []IAMBinding{binding}, nil
 }
+
+// resourceAttributes returns the attributes of a resource block.
+// Nested blocks such as condition {} are skipped rather than reported as errors.
+func resourceAttributes(block *hcl.Block) (hcl.Attributes, hcl.Diagnostics) {
+	body, ok := block.Body.(*hclsyntax.Body)
+	if !ok {
+		return block.Body.JustAttributes()
+	}
+	attrs := make(hcl.Attributes, len(body.Attributes))
+	for name, attr := range body.Attributes {
+		attrs[name] = attr.AsHCLAttribute()
+	}
+	return attrs, nil
+}
+
+// extractCondition resolves the condition {} block of an IAM resource, if any
+func extractCondition(block *hcl.Block, traverser *ConfigTraverser) *Condition {
+	body, ok := block.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	for _, nested := range body.Blocks {
+		if nested.Type != "condition" {
+			continue
+		}
+		condition := &Condition{}
+		for name, attr := range nested.Body.Attributes {
+			val, err := traverser.ResolveExpression(attr.Expr)
+			if err != nil || val.Type() != cty.String || !val.IsKnown() || val.IsNull() {
+				continue
+			}
+			switch name {
+			case "title":
+				condition.Title = val.AsString()
+			case "expression":
+				condition.Expression = val.AsString()
+			}
+		}
+		return condition
+	}
+	return nil
+}
+
+// findDefaultProject returns the project of the google provider block, or "" if none is set
+func findDefaultProject(parsedFiles []*hcl.File, traverser *ConfigTraverser) string {
+	defaultProject := ""
+	for _, file := range parsedFiles {
+		content, _, _ := file.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{
+				{Type: "provider", LabelNames: []string{"name"}},
+			},
+		})
+		for _, block := range content.Blocks {
+			if block.Type == "provider" && len(block.Labels) == 1 && block.Labels[0] == "google" {
+				// Check for project attribute
+				blockContent, _, _ := block.Body.PartialContent(&hcl.BodySchema{
+					Attributes: []hcl.AttributeSchema{
+						{Name: "project", Required: false},
+					},
+				})
+				if attr, exists := blockContent.Attributes["project"]; exists {
+					val, err := traverser.ResolveExpression(attr.Expr)
+					if err == nil && val.Type() == cty.String {
+						defaultProject = val.AsString()
+						break
+					}
+				}
+			}
+		}
+		if defaultProject != "" {
+			break
+		}
+	}
+
+	return defaultProject
+}
@@ -120,5 +120,52 @@ owner = "custom-owner"
 		if len(b2.Members) != 1 || b2.Members[0] != "serviceAccount:sa@test.com" {
 			t.Errorf("Binding 2 Member mismatch: got %v", b2.Members)
 		}
+		if b2.Location != "main.tf:16" {
+			t.Errorf("Binding 2 Location = %q, want %q", b2.Location, "main.tf:16")
+		}
+	}
+}
+
+func TestParseDir_FolderReference(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// The folder's ID is only known after apply, so the binding is keyed by the folder's address
+	mainTF := `
+resource "google_folder" "team" {
+  display_name = "team"
+  parent       = "organizations/111"
+}
+
+resource "google_folder_iam_member" "viewer" {
+  folder = google_folder.team.name
+  role   = "roles/viewer"
+  member = "user:alice@example.com"
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(mainTF), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defs := []ResourceDefinition{
+		{
+			Type:          "google_folder_iam_member",
+			ResourceLevel: "folder",
+			FieldMappings: FieldMapping{
+				ResourceID: "folder",
+				Role:       "role",
+				Member:     "member",
+			},
+		},
+	}
+
+	bindings, err := ParseDir(tmpDir, "", defs, nil)
+	if err != nil {
+		t.Fatalf("ParseDir failed: %v", err)
+	}
+	if len(bindings) != 1 {
+		t.Fatalf("Expected 1 binding, got %d", len(bindings))
+	}
+	if bindings[0].ResourceID != "google_folder.team" {
+		t.Errorf("ResourceID = %q, want %q", bindings[0].ResourceID, "google_folder.team")
 	}
 }
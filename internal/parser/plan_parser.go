@@ -8,9 +8,53 @@ import (
 
 // TerraformPlan represents the structure of terraform show -json output
 type TerraformPlan struct {
-	FormatVersion    string        `json:"format_version"`
-	TerraformVersion string        `json:"terraform_version"`
-	PlannedValues    PlannedValues `json:"planned_values"`
+	FormatVersion    string                  `json:"format_version"`
+	TerraformVersion string                  `json:"terraform_version"`
+	PlannedValues    PlannedValues           `json:"planned_values"`
+	Configuration    PlanConfiguration       `json:"configuration"`
+	Variables        map[string]PlanVariable `json:"variables"`
+}
+
+// PlanConfiguration is the configuration section of a plan: provider configurations and the expressions of resources
+type PlanConfiguration struct {
+	ProviderConfig map[string]PlanProviderConfig `json:"provider_config"`
+	RootModule     PlanConfigModule              `json:"root_module"`
+}
+
+// PlanConfigModule is a module of the plan configuration, with the modules it calls
+type PlanConfigModule struct {
+	Resources   []PlanConfigResource      `json:"resources"`
+	ModuleCalls map[string]PlanModuleCall `json:"module_calls"`
+}
+
+// PlanModuleCall is a module block of the plan configuration
+type PlanModuleCall struct {
+	Module PlanConfigModule `json:"module"`
+}
+
+// PlanConfigResource is a resource block of the plan configuration. Expressions maps attribute names to a
+// PlanExpression, and nested block names to a list of such maps.
+type PlanConfigResource struct {
+	Address     string                 `json:"address"`
+	Mode        string                 `json:"mode"`
+	Expressions map[string]interface{} `json:"expressions"`
+}
+
+// PlanProviderConfig is a provider block of the plan configuration
+type PlanProviderConfig struct {
+	Name        string                    `json:"name"`
+	Expressions map[string]PlanExpression `json:"expressions"`
+}
+
+// PlanExpression is a configuration expression: a constant, or the references it is computed from
+type PlanExpression struct {
+	ConstantValue interface{} `json:"constant_value"`
+	References    []string    `json:"references"`
+}
+
+// PlanVariable is the value of a root module variable in a plan
+type PlanVariable struct {
+	Value interface{} `json:"value"`
 }
 
 type PlannedValues struct {
@@ -33,33 +77,31 @@ type Resource struct {
 
 // ParsePlanFile parses a Terraform plan JSON file and extracts IAM bindings
 func ParsePlanFile(planPath string, definitions []ResourceDefinition) ([]IAMBinding, error) {
-	// Read the plan file
-	data, err := os.ReadFile(planPath)
+	source, err := LoadPlan(planPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read plan file: %w", err)
-	}
-
-	// Parse JSON
-	var plan TerraformPlan
-	if err := json.Unmarshal(data, &plan); err != nil {
-		return nil, fmt.Errorf("failed to parse plan JSON: %w", err)
+		return nil, err
 	}
+	return source.Bindings(definitions), nil
+}
 
+// planBindings extracts the bindings of a plan's resources
+func planBindings(plan TerraformPlan, definitions []ResourceDefinition, defaultProject string) []IAMBinding {
 	// Create definition lookup map
 	defMap := make(map[string]ResourceDefinition)
 	for _, def := range definitions {
 		defMap[def.Type] = def
 	}
 
-	// Extract bindings
-	var bindings []IAMBinding
-	bindings = append(bindings, extractBindingsFromModule(plan.PlannedValues.RootModule, defMap)...)
+	// Index the projects of planned resources, for resource-level bindings
+	projects := make(planProjects)
+	projects.addModule(plan.PlannedValues.RootModule, defMap)
 
-	return bindings, nil
+	// Extract bindings
+	return extractBindingsFromModule(plan.PlannedValues.RootModule, defMap, projects, defaultProject)
 }
 
 // extractBindingsFromModule recursively extracts IAM bindings from a module and its children
-func extractBindingsFromModule(module Module, defMap map[string]ResourceDefinition) []IAMBinding {
+func extractBindingsFromModule(module Module, defMap map[string]ResourceDefinition, projects planProjects, defaultProject string) []IAMBinding {
 	var bindings []IAMBinding
 
 	// Process resources in this module
@@ -76,9 +118,9 @@ func extractBindingsFromModule(module Module, defMap map[string]ResourceDefiniti
 		}
 
 		// Extract binding from this resource
-		bindingsFromResource, err := extractBindingFromResource(resource, def)
+		bindingsFromResource, err := extractBindingFromResource(resource, def, projects, defaultProject)
 		if err != nil {
-			fmt.Printf("Warning: failed to extract binding from %s: %v\n", resource.Address, err)
+			fmt.Fprintf(os.Stderr, "Warning: failed to extract binding from %s: %v\n", resource.Address, err)
 			continue
 		}
 
@@ -87,14 +129,14 @@ func extractBindingsFromModule(module Module, defMap map[string]ResourceDefiniti
 
 	// Process child modules recursively
 	for _, child := range module.ChildModules {
-		bindings = append(bindings, extractBindingsFromModule(child, defMap)...)
+		bindings = append(bindings, extractBindingsFromModule(child, defMap, projects, defaultProject)...)
 	}
 
 	return bindings
 }
 
 // extractBindingFromResource extracts an IAMBinding from a Terraform resource
-func extractBindingFromResource(resource Resource, def ResourceDefinition) ([]IAMBinding, error) {
+func extractBindingFromResource(resource Resource, def ResourceDefinition, projects planProjects, defaultProject string) ([]IAMBinding, error) {
 	// Common fields extraction
 	resourceID := ""
 	// Extract ResourceID
@@ -117,6 +159,11 @@ func extractBindingFromResource(resource Resource, def ResourceDefinition) ([]IA
 		resourceLevel = "resource"
 	}
 
+	// Resources belong to the project they are created in
+	if resourceLevel == "resource" && parentID == "" {
+		parentID = planOwningProject(def, resource.Values, resourceID, projects, defaultProject)
+	}
+
 	// Determine parent type based on resource level
 	parentType := DetermineParentType(resourceLevel, parentID)
 
@@ -141,6 +188,7 @@ func extractBindingFromResource(resource Resource, def ResourceDefinition) ([]IA
 						ParentID:      parentID,
 						ParentType:    parentType,
 						TerraformAddr: resource.Address,
+						Condition:     pb.Condition,
 					}
 					bindings = append(bindings, b)
 				}
@@ -158,6 +206,7 @@ func extractBindingFromResource(resource Resource, def ResourceDefinition) ([]IA
 		ResourceLevel: resourceLevel,
 		ParentID:      parentID,
 		ParentType:    parentType,
+		Condition:     planCondition(resource.Values),
 	}
 
 	// Extract Role
@@ -190,3 +239,20 @@ func extractBindingFromResource(resource Resource, def ResourceDefinition) ([]IA
 
 	return []IAMBinding{binding}, nil
 }
+
+// planCondition reads the condition block of a planned IAM resource.
+// Terraform renders nested blocks as lists, so only the first element is used.
+func planCondition(values map[string]interface{}) *Condition {
+	blocks, ok := values["condition"].([]interface{})
+	if !ok || len(blocks) == 0 {
+		return nil
+	}
+	block, ok := blocks[0].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return &Condition{
+		Title:      GetStringFromMap(block, "title"),
+		Expression: GetStringFromMap(block, "expression"),
+	}
+}
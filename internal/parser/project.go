@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// nonProjectTypePrefixes are IAM resource types whose resources live outside any project,
+// so their bindings are never placed in the provider's default project
+var nonProjectTypePrefixes = []string{
+	"google_access_context_manager_",
+	"google_billing_account_",
+	"google_iam_workforce_pool_",
+	"google_tags_",
+}
+
+// inProject reports whether resources of an IAM resource type belong to a project
+func inProject(resourceType string) bool {
+	for _, prefix := range nonProjectTypePrefixes {
+		if strings.HasPrefix(resourceType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// ProjectFromResourceID returns the project a resource ID names, or "".
+// Full resource names ("projects/my-project/topics/t", "//pubsub.googleapis.com/projects/my-project/topics/t")
+// and service account emails ("sa@my-project.iam.gserviceaccount.com") name their project;
+// the "projects/-/" wildcard does not.
+func ProjectFromResourceID(id string) string {
+	parts := strings.Split(id, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "projects" && parts[i+1] != "" && parts[i+1] != "-" {
+			return parts[i+1]
+		}
+	}
+	email := parts[len(parts)-1]
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		if project, ok := strings.CutSuffix(email[at+1:], ".iam.gserviceaccount.com"); ok {
+			return project
+		}
+	}
+	return ""
+}
+
+// hclOwningProject returns the project a resource-level binding's resource belongs to: the binding's own
+// project argument, the project named in its resource ID, the project of the resource block it references
+// or, failing those, the provider's default project
+func hclOwningProject(def ResourceDefinition, attrs hcl.Attributes, traverser *ConfigTraverser, resourceID, defaultProject string) string {
+	if !inProject(def.Type) {
+		return ""
+	}
+	if attr, ok := attrs["project"]; ok {
+		if project := resolveString(attr.Expr, traverser); project != "" {
+			return strings.TrimPrefix(project, "projects/")
+		}
+	}
+	if project := ProjectFromResourceID(resourceID); project != "" {
+		return project
+	}
+	if attr, ok := attrs[def.FieldMappings.ResourceID]; ok {
+		if ref := referencedResource(attr.Expr); ref != "" {
+			resType, resName, _ := strings.Cut(ref, ".")
+			val, err := traverser.LookupResourceAttribute(resType, resName, "project")
+			if err == nil && val.Type() == cty.String && val.IsKnown() && !val.IsNull() {
+				return val.AsString()
+			}
+		}
+	}
+	return defaultProject
+}
+
+// planProjects indexes the planned resources that record a project by the values IAM bindings refer to them by
+// (name, id and *_id attributes), for placing resource-level bindings in their project.
+// Values shared by resources in different projects are left out.
+type planProjects map[string]string
+
+// addModule indexes the resources of a module and its children, skipping IAM resources
+func (p planProjects) addModule(module Module, defMap map[string]ResourceDefinition) {
+	for _, resource := range module.Resources {
+		if _, isIAM := defMap[resource.Type]; isIAM || resource.Mode != "managed" {
+			continue
+		}
+		project := GetStringFromMap(resource.Values, "project")
+		if project == "" {
+			continue
+		}
+		for key, value := range resource.Values {
+			id, ok := value.(string)
+			if !ok || id == "" || !(key == "name" || key == "id" || strings.HasSuffix(key, "_id")) {
+				continue
+			}
+			if existing, seen := p[id]; seen && existing != project {
+				p[id] = "" // ambiguous
+				continue
+			}
+			p[id] = project
+		}
+	}
+	for _, child := range module.ChildModules {
+		p.addModule(child, defMap)
+	}
+}
+
+// planOwningProject returns the project a resource-level binding's resource belongs to in a plan: the binding's
+// own project value, the project named in its resource ID, the project of the planned resource it names or,
+// failing those, the provider's default project
+func planOwningProject(def ResourceDefinition, values map[string]interface{}, resourceID string, projects planProjects, defaultProject string) string {
+	if !inProject(def.Type) {
+		return ""
+	}
+	if project := GetStringFromMap(values, "project"); project != "" {
+		return strings.TrimPrefix(project, "projects/")
+	}
+	if project := ProjectFromResourceID(resourceID); project != "" {
+		return project
+	}
+	if project := projects[resourceID]; project != "" {
+		return project
+	}
+	return defaultProject
+}
+
+// planDefaultProject returns the project of the plan's google provider configuration, or "".
+// The project may be a constant or a reference to a root module variable.
+func planDefaultProject(plan TerraformPlan) string {
+	provider, ok := plan.Configuration.ProviderConfig["google"]
+	if !ok {
+		return ""
+	}
+	expr := provider.Expressions["project"]
+	if project, ok := expr.ConstantValue.(string); ok {
+		return project
+	}
+	for _, ref := range expr.References {
+		if name, ok := strings.CutPrefix(ref, "var."); ok {
+			if project, ok := plan.Variables[name].Value.(string); ok {
+				return project
+			}
+		}
+	}
+	return ""
+}
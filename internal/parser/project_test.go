@@ -0,0 +1,182 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDir_OwningProject(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	mainTF := `
+provider "google" {
+  project = "default-project"
+}
+
+resource "google_storage_bucket" "data" {
+  name    = "data-bucket"
+  project = "data-project"
+}
+
+resource "google_storage_bucket_iam_member" "explicit" {
+  bucket  = "explicit-bucket"
+  project = "explicit-project"
+  role    = "roles/storage.objectViewer"
+  member  = "user:alice@example.com"
+}
+
+resource "google_storage_bucket_iam_member" "referenced" {
+  bucket = google_storage_bucket.data.name
+  role   = "roles/storage.objectViewer"
+  member = "user:alice@example.com"
+}
+
+resource "google_storage_bucket_iam_member" "defaulted" {
+  bucket = "other-bucket"
+  role   = "roles/storage.objectViewer"
+  member = "user:alice@example.com"
+}
+
+resource "google_service_account_iam_member" "sa" {
+  service_account_id = "deployer@sa-project.iam.gserviceaccount.com"
+  role               = "roles/iam.serviceAccountUser"
+  member             = "user:alice@example.com"
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(mainTF), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defs := []ResourceDefinition{
+		{
+			Type:          "google_storage_bucket_iam_member",
+			ResourceLevel: "resource",
+			FieldMappings: FieldMapping{ResourceID: "bucket", Role: "role", Member: "member"},
+		},
+		{
+			Type:          "google_service_account_iam_member",
+			ResourceLevel: "resource",
+			FieldMappings: FieldMapping{ResourceID: "service_account_id", Role: "role", Member: "member"},
+		},
+	}
+
+	bindings, err := ParseDir(tmpDir, "", defs, nil)
+	if err != nil {
+		t.Fatalf("ParseDir failed: %v", err)
+	}
+
+	want := map[string]string{
+		"google_storage_bucket_iam_member.explicit":   "explicit-project",
+		"google_storage_bucket_iam_member.referenced": "data-project",
+		"google_storage_bucket_iam_member.defaulted":  "default-project",
+		"google_service_account_iam_member.sa":        "sa-project",
+	}
+	if len(bindings) != len(want) {
+		t.Fatalf("Expected %d bindings, got %d", len(want), len(bindings))
+	}
+	for _, b := range bindings {
+		if b.ParentID != want[b.TerraformAddr] || b.ParentType != "project" {
+			t.Errorf("%s: parent = %s %q, want project %q", b.TerraformAddr, b.ParentType, b.ParentID, want[b.TerraformAddr])
+		}
+	}
+}
+
+func TestParsePlanFile_OwningProject(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.json")
+
+	plan := TerraformPlan{
+		PlannedValues: PlannedValues{
+			RootModule: Module{
+				Resources: []Resource{
+					{
+						Address: "google_storage_bucket.data",
+						Mode:    "managed",
+						Type:    "google_storage_bucket",
+						Values:  map[string]interface{}{"name": "data-bucket", "project": "data-project"},
+					},
+					{
+						Address: "google_storage_bucket_iam_member.referenced",
+						Mode:    "managed",
+						Type:    "google_storage_bucket_iam_member",
+						Values: map[string]interface{}{
+							"bucket": "data-bucket",
+							"role":   "roles/storage.objectViewer",
+							"member": "user:alice@example.com",
+						},
+					},
+					{
+						Address: "google_storage_bucket_iam_member.defaulted",
+						Mode:    "managed",
+						Type:    "google_storage_bucket_iam_member",
+						Values: map[string]interface{}{
+							"bucket": "other-bucket",
+							"role":   "roles/storage.objectViewer",
+							"member": "user:alice@example.com",
+						},
+					},
+				},
+			},
+		},
+		Configuration: PlanConfiguration{
+			ProviderConfig: map[string]PlanProviderConfig{
+				"google": {
+					Name:        "google",
+					Expressions: map[string]PlanExpression{"project": {References: []string{"var.project_id"}}},
+				},
+			},
+		},
+		Variables: map[string]PlanVariable{"project_id": {Value: "default-project"}},
+	}
+
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatalf("Failed to marshal plan: %v", err)
+	}
+	if err := os.WriteFile(planFile, data, 0644); err != nil {
+		t.Fatalf("Failed to write plan file: %v", err)
+	}
+
+	defs := []ResourceDefinition{
+		{
+			Type:          "google_storage_bucket_iam_member",
+			ResourceLevel: "resource",
+			FieldMappings: FieldMapping{ResourceID: "bucket", Role: "role", Member: "member"},
+		},
+	}
+
+	bindings, err := ParsePlanFile(planFile, defs)
+	if err != nil {
+		t.Fatalf("ParsePlanFile failed: %v", err)
+	}
+
+	want := map[string]string{
+		"google_storage_bucket_iam_member.referenced": "data-project",
+		"google_storage_bucket_iam_member.defaulted":  "default-project",
+	}
+	if len(bindings) != len(want) {
+		t.Fatalf("Expected %d bindings, got %d", len(want), len(bindings))
+	}
+	for _, b := range bindings {
+		if b.ParentID != want[b.TerraformAddr] || b.ParentType != "project" {
+			t.Errorf("%s: parent = %s %q, want project %q", b.TerraformAddr, b.ParentType, b.ParentID, want[b.TerraformAddr])
+		}
+	}
+}
+
+func TestProjectFromResourceID(t *testing.T) {
+	tests := map[string]string{
+		"projects/app/topics/events":                                "app",
+		"//pubsub.googleapis.com/projects/app/topics/t":             "app",
+		"sa@app.iam.gserviceaccount.com":                            "app",
+		"projects/-/serviceAccounts/sa@app.iam.gserviceaccount.com": "app",
+		"my-bucket": "",
+	}
+	for id, want := range tests {
+		if got := ProjectFromResourceID(id); got != want {
+			t.Errorf("ProjectFromResourceID(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
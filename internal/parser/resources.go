@@ -0,0 +1,261 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ResourceInstance is a managed resource of interest with its string attributes resolved.
+// Unlike IAMBinding it is not driven by a resource definition; callers ask for the types they need.
+// Attributes of nested blocks are keyed by their dotted path (e.g. "service_account.email").
+type ResourceInstance struct {
+	Type       string            // Terraform resource type (e.g. "google_service_account_key")
+	Address    string            // Terraform address (e.g. "google_service_account_key.ci")
+	Values     map[string]string // String attributes that could be resolved, keyed by name or dotted path
+	References map[string]string // Attribute → address of the resource it references, for values that could not be resolved
+	Project    string            // Default project of the google provider, if any
+}
+
+// ParseResources returns the resources of the given types declared in the .tf files under dir.
+// String attributes are resolved against variables and other resources where possible;
+// count and for_each are not expanded, so each block yields a single instance.
+func ParseResources(dir string, tfvarsPath string, types []string, ignoredDirs []string) ([]ResourceInstance, error) {
+	source, err := LoadDir(dir, tfvarsPath, ignoredDirs)
+	if err != nil {
+		return nil, err
+	}
+	return source.Resources(types), nil
+}
+
+// hclResources returns the resources of the wanted types declared in the loaded .tf files
+func (s *Source) hclResources(wanted map[string]bool) []ResourceInstance {
+	var instances []ResourceInstance
+	for _, file := range s.files {
+		content, _, _ := file.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{
+				{Type: "resource", LabelNames: []string{"type", "name"}},
+			},
+		})
+
+		for _, block := range content.Blocks {
+			if !wanted[block.Labels[0]] {
+				continue
+			}
+
+			instance := ResourceInstance{
+				Type:       block.Labels[0],
+				Address:    fmt.Sprintf("%s.%s", block.Labels[0], block.Labels[1]),
+				Values:     make(map[string]string),
+				References: make(map[string]string),
+				Project:    s.defaultProject,
+			}
+			if body, ok := block.Body.(*hclsyntax.Body); ok {
+				collectBlockValues(&instance, body, "", s.traverser)
+			} else {
+				attrs, _ := block.Body.JustAttributes()
+				for name, attr := range attrs {
+					resolveInstanceValue(&instance, name, attr.Expr, s.traverser)
+				}
+			}
+			instances = append(instances, instance)
+		}
+	}
+
+	return instances
+}
+
+// collectBlockValues resolves the attributes of a block and, under dotted names, of its nested blocks
+// (e.g. "service_account.email"). Only the first of repeated nested blocks is kept.
+func collectBlockValues(instance *ResourceInstance, body *hclsyntax.Body, prefix string, traverser *ConfigTraverser) {
+	for name, attr := range body.Attributes {
+		resolveInstanceValue(instance, prefix+name, attr.Expr, traverser)
+	}
+	seen := make(map[string]bool)
+	for _, nested := range body.Blocks {
+		if seen[nested.Type] {
+			continue
+		}
+		seen[nested.Type] = true
+		collectBlockValues(instance, nested.Body, prefix+nested.Type+".", traverser)
+	}
+}
+
+// resolveInstanceValue records a string attribute, or the resource it references if it cannot be resolved
+func resolveInstanceValue(instance *ResourceInstance, name string, expr hcl.Expression, traverser *ConfigTraverser) {
+	val, err := traverser.ResolveExpression(expr)
+	if err == nil && val.Type() == cty.String && val.IsKnown() && !val.IsNull() {
+		instance.Values[name] = val.AsString()
+		return
+	}
+	if ref := referencedResource(expr); ref != "" {
+		instance.References[name] = ref
+	}
+}
+
+// referencedResource returns the "type.name" address an expression refers to, or "" if it is not a resource reference
+func referencedResource(expr hcl.Expression) string {
+	traversal, diags := hcl.AbsTraversalForExpr(expr)
+	if diags.HasErrors() || len(traversal) < 2 {
+		return ""
+	}
+	root, ok := traversal[0].(hcl.TraverseRoot)
+	if !ok || root.Name == "var" || root.Name == "local" || root.Name == "data" || root.Name == "module" {
+		return ""
+	}
+	name, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return ""
+	}
+	return root.Name + "." + name.Name
+}
+
+// ParsePlanResources returns the managed resources of the given types in a Terraform plan JSON file
+func ParsePlanResources(planPath string, types []string) ([]ResourceInstance, error) {
+	source, err := LoadPlan(planPath)
+	if err != nil {
+		return nil, err
+	}
+	return source.Resources(types), nil
+}
+
+// planModuleResources recursively collects managed resources of the wanted types from a module and its children.
+// Attributes the plan leaves unknown, such as those computed from resources not created yet, are recorded as
+// references when the configuration refers to a resource for them.
+func planModuleResources(module Module, wanted map[string]bool, defaultProject string, references map[string]map[string]string) []ResourceInstance {
+	var instances []ResourceInstance
+
+	for _, resource := range module.Resources {
+		if resource.Mode != "managed" || !wanted[resource.Type] {
+			continue
+		}
+
+		instance := ResourceInstance{
+			Type:    resource.Type,
+			Address: resource.Address,
+			Values:  make(map[string]string),
+			Project: defaultProject,
+		}
+		collectPlanValues(instance.Values, resource.Values, "")
+		for name, ref := range references[configAddress(resource.Address)] {
+			if _, known := instance.Values[name]; !known {
+				if instance.References == nil {
+					instance.References = make(map[string]string)
+				}
+				instance.References[name] = ref
+			}
+		}
+		instances = append(instances, instance)
+	}
+
+	for _, child := range module.ChildModules {
+		instances = append(instances, planModuleResources(child, wanted, defaultProject, references)...)
+	}
+
+	return instances
+}
+
+// planReferences returns, for the managed resources of a configuration module and the modules it calls, the
+// resource each attribute refers to: configuration address -> attribute -> address, in the form of the HCL
+// parser's References. prefix is the "module.<name>." path of the module.
+func planReferences(module PlanConfigModule, prefix string) map[string]map[string]string {
+	references := make(map[string]map[string]string)
+	for _, resource := range module.Resources {
+		if resource.Mode != "managed" {
+			continue
+		}
+		refs := make(map[string]string)
+		collectPlanReferences(refs, resource.Expressions, "", prefix)
+		if len(refs) > 0 {
+			references[prefix+resource.Address] = refs
+		}
+	}
+	for name, call := range module.ModuleCalls {
+		for address, refs := range planReferences(call.Module, prefix+"module."+name+".") {
+			references[address] = refs
+		}
+	}
+	return references
+}
+
+// collectPlanReferences records the resource each expression refers to, flattening nested blocks under
+// dotted names like collectPlanValues
+func collectPlanReferences(out map[string]string, expressions map[string]interface{}, prefix, modulePrefix string) {
+	for name, val := range expressions {
+		switch v := val.(type) {
+		case map[string]interface{}:
+			refs, _ := v["references"].([]interface{})
+			for _, ref := range refs {
+				if s, ok := ref.(string); ok {
+					if address := planReferencedResource(s); address != "" {
+						out[prefix+name] = modulePrefix + address
+						break
+					}
+				}
+			}
+		case []interface{}:
+			if len(v) > 0 {
+				if nested, ok := v[0].(map[string]interface{}); ok {
+					collectPlanReferences(out, nested, prefix+name+".", modulePrefix)
+				}
+			}
+		}
+	}
+}
+
+// planReferencedResource returns the "type.name" address a plan reference (e.g. "google_service_account.ci.email")
+// refers to, or "" if it is not a resource reference, like referencedResource
+func planReferencedResource(ref string) string {
+	parts := strings.SplitN(ref, ".", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	switch parts[0] {
+	case "var", "local", "data", "module", "each", "count", "path", "self", "terraform":
+		return ""
+	}
+	name, _, _ := strings.Cut(parts[1], "[")
+	return parts[0] + "." + name
+}
+
+// configAddress strips the instance keys of a planned resource address (e.g. "module.app[0].google_x.y[\"a\"]"),
+// giving the address of its configuration block
+func configAddress(address string) string {
+	var b strings.Builder
+	depth := 0
+	for _, c := range address {
+		switch {
+		case c == '[':
+			depth++
+		case c == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// collectPlanValues copies the string values of a planned resource. Nested blocks, which the
+// plan renders as lists of objects, are flattened under dotted names like the HCL parser does.
+func collectPlanValues(out map[string]string, values map[string]interface{}, prefix string) {
+	for name, val := range values {
+		switch v := val.(type) {
+		case string:
+			if v != "" {
+				out[prefix+name] = v
+			}
+		case map[string]interface{}:
+			collectPlanValues(out, v, prefix+name+".")
+		case []interface{}:
+			if len(v) > 0 {
+				if nested, ok := v[0].(map[string]interface{}); ok {
+					collectPlanValues(out, nested, prefix+name+".")
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseResources(t *testing.T) {
+	tmpDir := t.TempDir()
+	mainTF := `
+provider "google" {
+  project = "app"
+}
+
+variable "sa" { default = "legacy" }
+
+resource "google_service_account" "ci" {
+  account_id = "ci"
+}
+
+resource "google_service_account_key" "ci" {
+  service_account_id = google_service_account.ci.name
+}
+
+resource "google_service_account_key" "legacy" {
+  service_account_id = "projects/app/serviceAccounts/${var.sa}@app.iam.gserviceaccount.com"
+}
+
+resource "google_storage_bucket" "ignored" {
+  name = "bucket"
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(mainTF), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParseResources(tmpDir, "", []string{"google_service_account", "google_service_account_key"}, nil)
+	if err != nil {
+		t.Fatalf("ParseResources failed: %v", err)
+	}
+
+	want := []ResourceInstance{
+		{
+			Type: "google_service_account", Address: "google_service_account.ci", Project: "app",
+			Values: map[string]string{"account_id": "ci"}, References: map[string]string{},
+		},
+		{
+			Type: "google_service_account_key", Address: "google_service_account_key.ci", Project: "app",
+			Values: map[string]string{}, References: map[string]string{"service_account_id": "google_service_account.ci"},
+		},
+		{
+			Type: "google_service_account_key", Address: "google_service_account_key.legacy", Project: "app",
+			Values:     map[string]string{"service_account_id": "projects/app/serviceAccounts/legacy@app.iam.gserviceaccount.com"},
+			References: map[string]string{},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseResources() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseResources_NestedBlocks(t *testing.T) {
+	tmpDir := t.TempDir()
+	mainTF := `
+resource "google_compute_instance" "vm" {
+  name = "runner"
+  service_account {
+    email  = google_service_account.vm.email
+    scopes = ["cloud-platform"]
+  }
+}
+
+resource "google_composer_environment" "etl" {
+  name = "etl"
+  config {
+    node_config {
+      service_account = "etl@app.iam.gserviceaccount.com"
+    }
+  }
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(mainTF), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParseResources(tmpDir, "", []string{"google_compute_instance", "google_composer_environment"}, nil)
+	if err != nil {
+		t.Fatalf("ParseResources failed: %v", err)
+	}
+
+	want := []ResourceInstance{
+		{
+			Type: "google_compute_instance", Address: "google_compute_instance.vm",
+			Values:     map[string]string{"name": "runner"},
+			References: map[string]string{"service_account.email": "google_service_account.vm"},
+		},
+		{
+			Type: "google_composer_environment", Address: "google_composer_environment.etl",
+			Values:     map[string]string{"name": "etl", "config.node_config.service_account": "etl@app.iam.gserviceaccount.com"},
+			References: map[string]string{},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseResources() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCollectPlanValues(t *testing.T) {
+	values := map[string]interface{}{
+		"name":         "api",
+		"min_replicas": 1.0,
+		"template": []interface{}{
+			map[string]interface{}{"service_account": "api@app.iam.gserviceaccount.com"},
+		},
+		"labels": map[string]interface{}{"env": "prod"},
+	}
+
+	got := make(map[string]string)
+	collectPlanValues(got, values, "")
+
+	want := map[string]string{
+		"name":                     "api",
+		"template.service_account": "api@app.iam.gserviceaccount.com",
+		"labels.env":               "prod",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collectPlanValues() = %v, want %v", got, want)
+	}
+}
+
+func TestParsePlanResources_DefaultProject(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.json")
+
+	plan := TerraformPlan{
+		PlannedValues: PlannedValues{
+			RootModule: Module{
+				Resources: []Resource{
+					{Address: "google_service_account.ci", Mode: "managed", Type: "google_service_account", Values: map[string]interface{}{"account_id": "ci"}},
+					{Address: "google_storage_bucket.data", Mode: "managed", Type: "google_storage_bucket", Values: map[string]interface{}{"name": "data"}},
+				},
+				ChildModules: []Module{{
+					Resources: []Resource{
+						{Address: "module.app.google_service_account.api", Mode: "managed", Type: "google_service_account", Values: map[string]interface{}{"account_id": "api"}},
+					},
+				}},
+			},
+		},
+		Configuration: PlanConfiguration{
+			ProviderConfig: map[string]PlanProviderConfig{
+				"google": {Name: "google", Expressions: map[string]PlanExpression{"project": {References: []string{"var.project_id"}}}},
+			},
+		},
+		Variables: map[string]PlanVariable{"project_id": {Value: "app-prod"}},
+	}
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(planFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParsePlanResources(planFile, []string{"google_service_account"})
+	if err != nil {
+		t.Fatalf("ParsePlanResources() error = %v", err)
+	}
+
+	want := []ResourceInstance{
+		{Type: "google_service_account", Address: "google_service_account.ci", Values: map[string]string{"account_id": "ci"}, Project: "app-prod"},
+		{Type: "google_service_account", Address: "module.app.google_service_account.api", Values: map[string]string{"account_id": "api"}, Project: "app-prod"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePlanResources() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParsePlanResources_References(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.json")
+
+	keyExpr := map[string]interface{}{"service_account_id": map[string]interface{}{"references": []interface{}{"google_service_account.ci.name", "google_service_account.ci"}}}
+	plan := TerraformPlan{
+		PlannedValues: PlannedValues{
+			RootModule: Module{
+				Resources: []Resource{
+					{Address: "google_service_account_key.ci", Mode: "managed", Type: "google_service_account_key", Values: map[string]interface{}{}},
+					{Address: "google_service_account_key.known", Mode: "managed", Type: "google_service_account_key", Values: map[string]interface{}{"service_account_id": "projects/app/serviceAccounts/ci@app.iam.gserviceaccount.com"}},
+				},
+				ChildModules: []Module{{
+					Resources: []Resource{
+						{Address: `module.app["a"].google_service_account_key.ci[0]`, Mode: "managed", Type: "google_service_account_key", Values: map[string]interface{}{}},
+					},
+				}},
+			},
+		},
+		Configuration: PlanConfiguration{
+			RootModule: PlanConfigModule{
+				Resources: []PlanConfigResource{
+					{Address: "google_service_account_key.ci", Mode: "managed", Expressions: keyExpr},
+					{Address: "google_service_account_key.known", Mode: "managed", Expressions: keyExpr},
+				},
+				ModuleCalls: map[string]PlanModuleCall{
+					"app": {Module: PlanConfigModule{Resources: []PlanConfigResource{
+						{
+							Address: "google_service_account_key.ci", Mode: "managed",
+							Expressions: map[string]interface{}{"service_account_id": map[string]interface{}{"references": []interface{}{"var.account", "google_service_account.ci[0].name", "google_service_account.ci"}}},
+						},
+					}}},
+				},
+			},
+		},
+	}
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(planFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParsePlanResources(planFile, []string{"google_service_account_key"})
+	if err != nil {
+		t.Fatalf("ParsePlanResources() error = %v", err)
+	}
+
+	// Known values win over references; references in modules carry the module path
+	want := []ResourceInstance{
+		{
+			Type: "google_service_account_key", Address: "google_service_account_key.ci", Values: map[string]string{},
+			References: map[string]string{"service_account_id": "google_service_account.ci"},
+		},
+		{
+			Type: "google_service_account_key", Address: "google_service_account_key.known",
+			Values: map[string]string{"service_account_id": "projects/app/serviceAccounts/ci@app.iam.gserviceaccount.com"},
+		},
+		{
+			Type: "google_service_account_key", Address: `module.app["a"].google_service_account_key.ci[0]`, Values: map[string]string{},
+			References: map[string]string{"service_account_id": "module.app.google_service_account.ci"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePlanResources() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Source is Terraform input loaded once, either the .tf files of a directory or a plan JSON file, from which
+// bindings, resources and deny policies are parsed. Commands load it once and share it between analyses.
+type Source struct {
+	dir            string           // scanned directory, for .tf input
+	files          []*hcl.File      // parsed .tf files
+	traverser      *ConfigTraverser // resolves expressions of the .tf files
+	defaultProject string           // default project of the google provider
+	plan           *TerraformPlan   // plan, for plan JSON input
+}
+
+// LoadDir parses the .tf files under dir and the variables they can be resolved against.
+// Files that fail to parse are skipped with a warning on stderr.
+func LoadDir(dir string, tfvarsPath string, ignoredDirs []string) (*Source, error) {
+	vars, err := LoadVariables(dir, tfvarsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load variables: %v\n", err)
+	}
+
+	files, err := loadHCLFiles(dir, ignoredDirs)
+	if err != nil {
+		return nil, err
+	}
+	traverser := NewConfigTraverser(files, vars)
+	return &Source{
+		dir:            dir,
+		files:          files,
+		traverser:      traverser,
+		defaultProject: findDefaultProject(files, traverser),
+	}, nil
+}
+
+// LoadPlan reads a Terraform plan JSON file, as written by terraform show -json
+func LoadPlan(planPath string) (*Source, error) {
+	data, err := os.ReadFile(planPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	var plan TerraformPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan JSON: %w", err)
+	}
+	return &Source{plan: &plan, defaultProject: planDefaultProject(plan)}, nil
+}
+
+// Bindings returns the IAM bindings of the resources matching definitions
+func (s *Source) Bindings(definitions []ResourceDefinition) []IAMBinding {
+	if s.plan != nil {
+		return planBindings(*s.plan, definitions, s.defaultProject)
+	}
+	return s.hclBindings(definitions)
+}
+
+// Resources returns the managed resources of the given types
+func (s *Source) Resources(types []string) []ResourceInstance {
+	wanted := make(map[string]bool, len(types))
+	for _, t := range types {
+		wanted[t] = true
+	}
+	if s.plan != nil {
+		references := planReferences(s.plan.Configuration.RootModule, "")
+		return planModuleResources(s.plan.PlannedValues.RootModule, wanted, s.defaultProject, references)
+	}
+	return s.hclResources(wanted)
+}
+
+// DenyPolicies returns the rules of every google_iam_deny_policy
+func (s *Source) DenyPolicies() []DenyRule {
+	if s.plan != nil {
+		return planModuleDenyRules(s.plan.PlannedValues.RootModule)
+	}
+	return s.hclDenyRules()
+}
+
+// ListResources returns every managed resource, whatever its type
+func (s *Source) ListResources() []ResourceRef {
+	if s.plan != nil {
+		return listModuleResources(s.plan.PlannedValues.RootModule)
+	}
+	return s.hclResourceRefs()
+}
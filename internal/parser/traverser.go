@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"net/url"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -37,7 +38,8 @@ func (t *ConfigTraverser) ResolveExpression(expr hcl.Expression) (cty.Value, err
 			"var": cty.ObjectVal(t.Variables),
 		},
 		Functions: map[string]function.Function{
-			"length": stdlib.LengthFunc,
+			"length":    stdlib.LengthFunc,
+			"urlencode": urlEncodeFunc,
 		},
 	}
 
@@ -152,3 +154,12 @@ func (t *ConfigTraverser) WithScope(scopedCtx *hcl.EvalContext) *ConfigTraverser
 		ScopedContext: scopedCtx,
 	}
 }
+
+// urlEncodeFunc implements Terraform's urlencode(), used to build IAM deny policy parents
+var urlEncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "str", Type: cty.String}},
+	Type:   function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.StringVal(url.QueryEscape(args[0].AsString())), nil
+	},
+})
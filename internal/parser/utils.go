@@ -28,8 +28,15 @@ func DetermineParentType(resourceLevel, parentID string) string {
 
 // PolicyBinding matches the structure of a binding in policy_data JSON
 type PolicyBinding struct {
-	Role    string   `json:"role"`
-	Members []string `json:"members"`
+	Role      string     `json:"role"`
+	Members   []string   `json:"members"`
+	Condition *Condition `json:"condition,omitempty"`
+}
+
+// Condition is an IAM condition attached to a binding
+type Condition struct {
+	Title      string `json:"title,omitempty"`
+	Expression string `json:"expression"`
 }
 
 // Policy matches the structure of policy_data JSON
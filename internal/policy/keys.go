@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+)
+
+// validateServiceAccountKey flags Terraform-declared keys and key creation grants for the selected service accounts
+func (v *PolicyValidator) validateServiceAccountKey(policy *Policy) []Violation {
+	violations := []Violation{}
+	keyPolicy := policy.ServiceAccountKey
+
+	if keyPolicy == nil || v.impGraph == nil {
+		return violations
+	}
+	selected := func(serviceAccount string) bool {
+		return MatchesPrincipalPattern(serviceAccount, keyPolicy.Selector.PrincipalPattern)
+	}
+
+	// Existing keys: the private key is stored in the Terraform state
+	if !keyPolicy.AllowKeyResources {
+		for _, key := range v.impGraph.Keys {
+			if key.ServiceAccount != "" && !selected(key.ServiceAccount) {
+				continue
+			}
+			account := key.ServiceAccount
+			if account == "" {
+				account = "an unresolved service account"
+			}
+			violations = append(violations, Violation{
+				PolicyName:    policy.Name,
+				ViolationType: ViolationTypeServiceAccountKey,
+				Severity:      policy.Severity,
+				Principal:     key.ServiceAccount,
+				Resource:      key.Address,
+				Message:       fmt.Sprintf("Long-lived key for %s is declared in Terraform; anyone with access to the state or outputs can act as it", account),
+				Location:      key.Address,
+				Remediation:   "Remove the key resource and use impersonation or workload identity federation instead",
+			})
+		}
+	}
+
+	// Key creation grants, one violation per grant however many service accounts it covers
+	type grant struct {
+		source, role, scope, address string
+	}
+	targets := make(map[grant][]string)
+	var grants []grant
+	for _, edges := range v.impGraph.Graph {
+		for _, edge := range edges {
+			if edge.Capability != definitions.CapabilityKeyCreation || !selected(edge.Target) {
+				continue
+			}
+			if IsPrincipalIn(edge.Source, keyPolicy.AllowedKeyCreators) {
+				continue
+			}
+			g := grant{source: edge.Source, role: edge.Role, scope: edge.Scope, address: edge.SourceAddress}
+			if _, seen := targets[g]; !seen {
+				grants = append(grants, g)
+			}
+			targets[g] = append(targets[g], edge.Target)
+		}
+	}
+	sort.Slice(grants, func(i, j int) bool {
+		if grants[i].source != grants[j].source {
+			return grants[i].source < grants[j].source
+		}
+		return grants[i].scope+grants[i].role < grants[j].scope+grants[j].role
+	})
+
+	for _, g := range grants {
+		accounts := targets[g]
+		sort.Strings(accounts)
+		violations = append(violations, Violation{
+			PolicyName:    policy.Name,
+			ViolationType: ViolationTypeKeyCreation,
+			Severity:      policy.Severity,
+			Principal:     g.source,
+			Resource:      g.scope,
+			Role:          g.role,
+			Message:       fmt.Sprintf("%s can create keys for %s via %s", g.source, describeAccounts(accounts), g.role),
+			Location:      g.address,
+			Remediation:   "Remove the key creation role or add the principal to allowed_key_creators",
+		})
+	}
+
+	return violations
+}
+
+// describeAccounts names a single service account or counts several
+func describeAccounts(accounts []string) string {
+	if len(accounts) == 1 {
+		return strings.TrimPrefix(accounts[0], "serviceAccount:")
+	}
+	return fmt.Sprintf("%d service accounts", len(accounts))
+}
@@ -0,0 +1,112 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
+)
+
+func TestValidateServiceAccountKey(t *testing.T) {
+	if err := definitions.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	const (
+		ci     = "serviceAccount:ci@app.iam.gserviceaccount.com"
+		runner = "serviceAccount:runner@app.iam.gserviceaccount.com"
+	)
+	bindings := []parser.IAMBinding{
+		{
+			ResourceID: "app", ResourceType: "google_project_iam_member", ResourceLevel: "project",
+			Role: "roles/iam.serviceAccountKeyAdmin", Members: []string{"user:alice@example.com", "group:platform@example.com"},
+			TerraformAddr: "google_project_iam_member.key_admins",
+		},
+		{
+			ResourceID: "projects/app/serviceAccounts/ci@app.iam.gserviceaccount.com", ResourceType: "google_service_account_iam_member", ResourceLevel: "resource",
+			Role: "roles/iam.serviceAccountUser", Members: []string{runner},
+			TerraformAddr: "google_service_account_iam_member.ci_users",
+		},
+	}
+	graph := analyzer.BuildImpersonationGraph(bindings)
+	graph.AddKeys([]analyzer.ServiceAccountKey{
+		{ServiceAccount: ci, Address: "google_service_account_key.ci"},
+		{Address: "google_service_account_key.unresolved"},
+	})
+	validator := NewValidator(&PolicyConfig{}, bindings, nil, graph, definitions.GetCanImpersonateFunc())
+
+	keyViolation := func(principal, address string) Violation {
+		account := principal
+		if account == "" {
+			account = "an unresolved service account"
+		}
+		return Violation{
+			PolicyName: "no-keys", ViolationType: ViolationTypeServiceAccountKey, Severity: SeverityError,
+			Principal: principal, Resource: address, Location: address,
+			Message:     "Long-lived key for " + account + " is declared in Terraform; anyone with access to the state or outputs can act as it",
+			Remediation: "Remove the key resource and use impersonation or workload identity federation instead",
+		}
+	}
+	creationViolation := func(source, accounts string) Violation {
+		return Violation{
+			PolicyName: "no-keys", ViolationType: ViolationTypeKeyCreation, Severity: SeverityError,
+			Principal: source, Resource: "project:app", Role: "roles/iam.serviceAccountKeyAdmin",
+			Location:    "google_project_iam_member.key_admins",
+			Message:     source + " can create keys for " + accounts + " via roles/iam.serviceAccountKeyAdmin",
+			Remediation: "Remove the key creation role or add the principal to allowed_key_creators",
+		}
+	}
+
+	tests := []struct {
+		name   string
+		policy ServiceAccountKeyPolicy
+		want   []Violation
+	}{
+		{
+			name:   "key resources and key creation grants",
+			policy: ServiceAccountKeyPolicy{},
+			want: []Violation{
+				keyViolation(ci, "google_service_account_key.ci"),
+				keyViolation("", "google_service_account_key.unresolved"),
+				creationViolation("group:platform@example.com", "2 service accounts"),
+				creationViolation("user:alice@example.com", "2 service accounts"),
+			},
+		},
+		{
+			name:   "key resources allowed",
+			policy: ServiceAccountKeyPolicy{AllowKeyResources: true},
+			want: []Violation{
+				creationViolation("group:platform@example.com", "2 service accounts"),
+				creationViolation("user:alice@example.com", "2 service accounts"),
+			},
+		},
+		{
+			name:   "allowed key creators",
+			policy: ServiceAccountKeyPolicy{AllowKeyResources: true, AllowedKeyCreators: []string{"^group:platform@"}},
+			want: []Violation{
+				creationViolation("user:alice@example.com", "2 service accounts"),
+			},
+		},
+		{
+			name:   "selected service accounts, unresolved keys included",
+			policy: ServiceAccountKeyPolicy{Selector: PrincipalSelector{PrincipalPattern: "^serviceAccount:runner@"}},
+			want: []Violation{
+				keyViolation("", "google_service_account_key.unresolved"),
+				creationViolation("group:platform@example.com", "runner@app.iam.gserviceaccount.com"),
+				creationViolation("user:alice@example.com", "runner@app.iam.gserviceaccount.com"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &Policy{Name: "no-keys", Type: PolicyTypeServiceAccountKey, Severity: SeverityError, ServiceAccountKey: &tt.policy}
+			got := validator.validateServiceAccountKey(policy)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateServiceAccountKey() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
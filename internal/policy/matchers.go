@@ -1,47 +1,61 @@
 package policy
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+)
+
+// Role expression prefixes usable wherever a policy lists roles
+const (
+	RoleExprAtLeast    = "at_least:"   // "at_least:roles/editor" matches any role that includes roles/editor
+	RoleExprPermission = "permission:" // "permission:storage.objects.delete" matches any role granting the permission
 )
 
 // matchPattern checks if a value matches a pattern using regex
 // Patterns:
 //   - "*" matches everything
 //   - Regex patterns like "^user:.*" or "roles/storage\..*"
-//   - Exact match as fallback if regex is invalid
-func matchPattern(value, pattern string) bool {
+//
+// An invalid regex is an error rather than a literal; policy fields holding patterns are tagged
+// schema:"pattern" so LoadPolicies rejects invalid ones before any matching.
+func matchPattern(value, pattern string) (bool, error) {
 	// Special case: "*" matches everything
 	if pattern == "*" {
-		return true
+		return true, nil
 	}
 
-	// Try regex matching
 	re, err := regexp.Compile(pattern)
 	if err != nil {
-		// Invalid regex, fall back to exact match
-		return value == pattern
+		return false, fmt.Errorf("invalid pattern %q: %w", pattern, err)
 	}
+	return re.MatchString(value), nil
+}
 
-	return re.MatchString(value)
+// matches is matchPattern for patterns already validated when the policies were loaded; an invalid one matches nothing
+func matches(value, pattern string) bool {
+	ok, err := matchPattern(value, pattern)
+	return err == nil && ok
 }
 
 // MatchesPrincipalPattern checks if a principal matches a pattern
 // Supports regex patterns like "^user:.*" or "^serviceAccount:.*@.*\.iam\.gserviceaccount\.com$"
 func MatchesPrincipalPattern(principal, pattern string) bool {
-	return matchPattern(principal, pattern)
+	return matches(principal, pattern)
 }
 
 // MatchesResourcePattern checks if a resource ID matches a pattern
 // Supports regex patterns like "^prod-.*" or ".*-bucket$"
 func MatchesResourcePattern(resourceID, pattern string) bool {
-	return matchPattern(resourceID, pattern)
+	return matches(resourceID, pattern)
 }
 
 // MatchesRolePattern checks if a role matches a pattern
 // Supports regex patterns like "^roles/storage\..*" or "roles/bigquery\.(dataViewer|dataEditor)"
 func MatchesRolePattern(role, pattern string) bool {
-	return matchPattern(role, pattern)
+	return matches(role, pattern)
 }
 
 // ExtractPrincipalEmail extracts email from principal string
@@ -64,16 +78,37 @@ func GetPrincipalType(principal string) string {
 	return "unknown"
 }
 
-// IsRoleIn checks if a role is in a list of roles
+// MatchesRoleExpression checks if a role matches a policy role entry.
+// Entries are "at_least:<role>", "permission:<permission>", or a role name/regex pattern.
+func MatchesRoleExpression(role, expr string) bool {
+	if target, ok := strings.CutPrefix(expr, RoleExprAtLeast); ok {
+		return definitions.RoleIncludes(role, target)
+	}
+	if permission, ok := strings.CutPrefix(expr, RoleExprPermission); ok {
+		return definitions.RoleHasPermission(role, permission)
+	}
+	return role == expr || MatchesRolePattern(role, expr)
+}
+
+// IsRoleIn checks if a role is in a list of roles, patterns or role expressions
 func IsRoleIn(role string, roles []string) bool {
 	for _, r := range roles {
-		if role == r || MatchesRolePattern(role, r) {
+		if MatchesRoleExpression(role, r) {
 			return true
 		}
 	}
 	return false
 }
 
+// SatisfiesRole checks if a held role satisfies a required role entry.
+// A plain role name is satisfied by any role that includes it.
+func SatisfiesRole(held, required string) bool {
+	if MatchesRoleExpression(held, required) {
+		return true
+	}
+	return !strings.HasPrefix(required, RoleExprPermission) && definitions.RoleIncludes(held, required)
+}
+
 // IsPrincipalIn checks if a principal matches any pattern in a list
 func IsPrincipalIn(principal string, patterns []string) bool {
 	for _, pattern := range patterns {
@@ -0,0 +1,170 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/definitions"
+)
+
+// loadCustomRoles loads the embedded rules with an overlay of custom roles:
+// roles/custom.admin includes roles/custom.reader and adds custom.things.delete
+func loadCustomRoles(t *testing.T) {
+	t.Helper()
+	overlay := filepath.Join(t.TempDir(), "roles.yaml")
+	content := `
+role_includes:
+  "roles/custom.admin": ["roles/custom.reader"]
+role_permissions:
+  "roles/custom.reader": ["custom.things.get", "custom.things.list"]
+  "roles/custom.admin": ["custom.things.delete"]
+`
+	if err := os.WriteFile(overlay, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := definitions.LoadRules(overlay); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	t.Cleanup(func() { _ = definitions.LoadRules() })
+}
+
+func TestMatchesRoleExpression(t *testing.T) {
+	loadCustomRoles(t)
+
+	tests := []struct {
+		name string
+		role string
+		expr string
+		want bool
+	}{
+		{"Exact Role", "roles/custom.reader", "roles/custom.reader", true},
+		{"Role Pattern", "roles/custom.admin", "^roles/custom\\..*", true},
+		{"Other Role", "roles/custom.admin", "roles/custom.reader", false},
+		{"At Least Included Role", "roles/custom.admin", "at_least:roles/custom.reader", true},
+		{"At Least Same Role", "roles/custom.reader", "at_least:roles/custom.reader", true},
+		{"At Least Narrower Role", "roles/custom.reader", "at_least:roles/custom.admin", false},
+		{"At Least Built-in", "roles/owner", "at_least:roles/editor", true},
+		{"Permission Granted", "roles/custom.admin", "permission:custom.things.delete", true},
+		{"Permission Of Included Role", "roles/custom.admin", "permission:custom.things.get", true},
+		{"Permission Missing", "roles/custom.reader", "permission:custom.things.delete", false},
+		{"Permission Wildcard", "roles/custom.reader", "permission:custom.things.*", true},
+		{"Permission Other Service", "roles/custom.admin", "permission:storage.objects.get", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchesRoleExpression(tt.role, tt.expr); got != tt.want {
+				t.Errorf("MatchesRoleExpression(%q, %q) = %v, want %v", tt.role, tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSatisfiesRole(t *testing.T) {
+	loadCustomRoles(t)
+
+	tests := []struct {
+		name     string
+		held     string
+		required string
+		want     bool
+	}{
+		{"Same Role", "roles/custom.reader", "roles/custom.reader", true},
+		{"Including Role", "roles/custom.admin", "roles/custom.reader", true},
+		{"Narrower Role", "roles/custom.reader", "roles/custom.admin", false},
+		{"Built-in Inclusion", "roles/owner", "roles/viewer", true},
+		{"At Least", "roles/custom.admin", "at_least:roles/custom.reader", true},
+		{"Permission Granted", "roles/custom.admin", "permission:custom.things.get", true},
+		{"Permission Missing", "roles/custom.reader", "permission:custom.things.delete", false},
+		// A permission entry is only satisfied by the permissions a role grants, never by role inclusion
+		{"Permission Not Through Inclusion", "permission:custom.things.get", "permission:custom.things.get", false},
+		{"Unknown Role", "roles/custom.other", "roles/custom.reader", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SatisfiesRole(tt.held, tt.required); got != tt.want {
+				t.Errorf("SatisfiesRole(%q, %q) = %v, want %v", tt.held, tt.required, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		value, pattern string
+		want, wantErr  bool
+	}{
+		{value: "roles/storage.admin", pattern: "*", want: true},
+		{value: "roles/storage.admin", pattern: `^roles/storage\.`, want: true},
+		{value: "roles/viewer", pattern: `^roles/storage\.`},
+		// An invalid regex is an error, not a literal to compare against
+		{value: "roles/(storage", pattern: "roles/(storage", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := matchPattern(tt.value, tt.pattern)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("matchPattern(%q, %q) = %v, %v; want %v, error %v", tt.value, tt.pattern, got, err, tt.want, tt.wantErr)
+		}
+	}
+	if MatchesRolePattern("roles/(storage", "roles/(storage") {
+		t.Errorf("MatchesRolePattern() matched an invalid pattern")
+	}
+}
+
+func TestLoadPolicies_InvalidRolePatterns(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+	}{
+		{
+			name: "required binding role",
+			policy: `
+  - name: p
+    type: persona
+    persona:
+      persona_name: dev
+      principals: ["user:alice@example.com"]
+      required_bindings:
+        - resource_pattern: ".*"
+          role: "roles/(storage"`,
+		},
+		{
+			name: "forbidden binding role",
+			policy: `
+  - name: p
+    type: persona
+    persona:
+      persona_name: dev
+      principals: ["user:alice@example.com"]
+      forbidden_bindings:
+        - resource_pattern: ".*"
+          role: "roles/(storage"`,
+		},
+		{
+			name: "conflicting roles",
+			policy: `
+  - name: p
+    type: separation_of_duty
+    separation_of_duty:
+      scope: per_principal
+      conflicting_roles:
+        - ["roles/owner", "roles/(storage"]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			content := "cloud_provider: gcp\npolicies:" + tt.policy + "\n"
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadPolicies(path)
+			if err == nil || !strings.Contains(err.Error(), `invalid regex "roles/(storage"`) {
+				t.Errorf("LoadPolicies() error = %v, want the invalid role pattern reported", err)
+			}
+		})
+	}
+}
@@ -1,17 +1,19 @@
 package policy
 
+import "github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
+
 // PolicyConfig represents the complete policy configuration
 type PolicyConfig struct {
-	CloudProvider string   `yaml:"cloud_provider"`
-	Policies      []Policy `yaml:"policies"`
+	CloudProvider string   `yaml:"cloud_provider" schema:"required,enum=gcp"`
+	Policies      []Policy `yaml:"policies" schema:"unique=name"`
 }
 
 // Policy represents a single policy definition
 type Policy struct {
-	Name        string     `yaml:"name"`
-	Type        PolicyType `yaml:"type"`
+	Name        string     `yaml:"name" schema:"required"`
+	Type        PolicyType `yaml:"type" schema:"required,enum=role_restriction|persona|resource_access|separation_of_duty|impersonation_escalation|effective_access|service_account_key"`
 	Description string     `yaml:"description"`
-	Severity    Severity   `yaml:"severity"`
+	Severity    Severity   `yaml:"severity" schema:"enum=error|warning|info"`
 
 	// Type-specific fields
 	RoleRestriction         *RoleRestrictionPolicy   `yaml:"role_restriction,omitempty"`
@@ -20,6 +22,7 @@ type Policy struct {
 	SeparationOfDuty        *SeparationOfDutyPolicy  `yaml:"separation_of_duty,omitempty"`
 	ImpersonationEscalation *ImpersonationEscalation `yaml:"impersonation_escalation,omitempty"`
 	EffectiveAccess         *EffectiveAccessPolicy   `yaml:"effective_access,omitempty"`
+	ServiceAccountKey       *ServiceAccountKeyPolicy `yaml:"service_account_key,omitempty"`
 }
 
 // PolicyType defines the type of policy
@@ -32,6 +35,7 @@ const (
 	PolicyTypeSeparationOfDuty        PolicyType = "separation_of_duty"
 	PolicyTypeImpersonationEscalation PolicyType = "impersonation_escalation"
 	PolicyTypeEffectiveAccess         PolicyType = "effective_access"
+	PolicyTypeServiceAccountKey       PolicyType = "service_account_key"
 )
 
 // Severity levels
@@ -46,14 +50,14 @@ const (
 // RoleRestrictionPolicy restricts which roles principals can have
 type RoleRestrictionPolicy struct {
 	Selector     PrincipalSelector `yaml:"selector"`
-	AllowedRoles *[]string         `yaml:"allowed_roles"` // Pointer to distinguish nil (not set) from empty (no roles allowed)
-	DeniedRoles  []string          `yaml:"denied_roles"`
+	AllowedRoles *[]string         `yaml:"allowed_roles" schema:"pattern"` // Pointer to distinguish nil (not set) from empty (no roles allowed)
+	DeniedRoles  []string          `yaml:"denied_roles" schema:"pattern"`
 }
 
 // PersonaPolicy defines required and forbidden bindings for a persona
 type PersonaPolicy struct {
 	PersonaName              string                 `yaml:"persona_name"`
-	Principals               []string               `yaml:"principals"`
+	Principals               []string               `yaml:"principals" schema:"pattern"`
 	RequiredBindings         []RequiredBinding      `yaml:"required_bindings"`
 	ForbiddenBindings        []ForbiddenBinding     `yaml:"forbidden_bindings"`
 	AllowAdditionalAccess    bool                   `yaml:"allow_additional_access"`
@@ -64,15 +68,15 @@ type PersonaPolicy struct {
 // ResourceAccessPolicy controls which principals can access resources
 type ResourceAccessPolicy struct {
 	Selector                 ResourceSelector    `yaml:"selector"`
-	AllowedPrincipals        []string            `yaml:"allowed_principals"`
-	AllowedRolesPerPrincipal map[string][]string `yaml:"allowed_roles_per_principal,omitempty"`
+	AllowedPrincipals        []string            `yaml:"allowed_principals" schema:"pattern"`
+	AllowedRolesPerPrincipal map[string][]string `yaml:"allowed_roles_per_principal,omitempty" schema:"pattern"`
 	ValidateEffectiveAccess  bool                `yaml:"validate_effective_access"`
 }
 
 // SeparationOfDutyPolicy prevents conflicting role combinations
 type SeparationOfDutyPolicy struct {
-	ConflictingRoles [][]string `yaml:"conflicting_roles"`
-	Scope            string     `yaml:"scope"` // "per_principal" or "per_resource"
+	ConflictingRoles [][]string `yaml:"conflicting_roles" schema:"pattern"`
+	Scope            string     `yaml:"scope" schema:"enum=per_principal|per_resource"` // "per_principal" or "per_resource"
 }
 
 // ImpersonationEscalation prevents privilege escalation through impersonation
@@ -84,57 +88,64 @@ type ImpersonationEscalation struct {
 type EffectiveAccessPolicy struct {
 	Selector                     ResourceSelector `yaml:"selector"`
 	ValidateEffectiveAccess      bool             `yaml:"validate_effective_access"`
-	AllowedEffectivePrincipals   []string         `yaml:"allowed_effective_principals"`
-	ForbiddenEffectivePrincipals []string         `yaml:"forbidden_effective_principals"`
+	AllowedEffectivePrincipals   []string         `yaml:"allowed_effective_principals" schema:"pattern"`
+	ForbiddenEffectivePrincipals []string         `yaml:"forbidden_effective_principals" schema:"pattern"`
+}
+
+// ServiceAccountKeyPolicy restricts long-lived credentials for service accounts
+type ServiceAccountKeyPolicy struct {
+	Selector           PrincipalSelector `yaml:"selector"`                              // Service accounts the policy applies to (all if empty)
+	AllowKeyResources  bool              `yaml:"allow_key_resources"`                   // Permit google_service_account_key resources
+	AllowedKeyCreators []string          `yaml:"allowed_key_creators" schema:"pattern"` // Principals that may hold key creation roles
 }
 
 // PrincipalSelector selects principals to apply policy to
 type PrincipalSelector struct {
-	PrincipalPattern string `yaml:"principal_pattern"`
+	PrincipalPattern string `yaml:"principal_pattern" schema:"pattern"`
 }
 
 // ResourceSelector selects resources to apply policy to
 type ResourceSelector struct {
-	ResourcePattern string `yaml:"resource_pattern"`
+	ResourcePattern string `yaml:"resource_pattern" schema:"pattern"`
 	ResourceType    string `yaml:"resource_type"`
 }
 
 // RequiredBinding defines a binding that must exist
 type RequiredBinding struct {
-	ResourcePattern string `yaml:"resource_pattern"`
+	ResourcePattern string `yaml:"resource_pattern" schema:"pattern"`
 	ResourceType    string `yaml:"resource_type"`
-	Role            string `yaml:"role"`
+	Role            string `yaml:"role" schema:"pattern"`
 }
 
 // ForbiddenBinding defines a binding that must not exist
 type ForbiddenBinding struct {
-	ResourcePattern string `yaml:"resource_pattern"`
+	ResourcePattern string `yaml:"resource_pattern" schema:"pattern"`
 	ResourceType    string `yaml:"resource_type,omitempty"`
-	Role            string `yaml:"role"`
+	Role            string `yaml:"role" schema:"pattern"`
 }
 
 // TransitiveConstraints defines constraints on transitive access
 type TransitiveConstraints struct {
 	MaxImpersonationDepth        int               `yaml:"max_impersonation_depth"`
-	ForbiddenTransitiveRoles     []string          `yaml:"forbidden_transitive_roles"`
+	ForbiddenTransitiveRoles     []string          `yaml:"forbidden_transitive_roles" schema:"pattern"`
 	ForbiddenTransitiveResources []ResourcePattern `yaml:"forbidden_transitive_resources"`
-	AllowedImpersonationTargets  []string          `yaml:"allowed_impersonation_targets"`
+	AllowedImpersonationTargets  []string          `yaml:"allowed_impersonation_targets" schema:"pattern"`
 }
 
 // ResourcePattern defines a resource pattern for matching
 type ResourcePattern struct {
-	ResourcePattern string `yaml:"resource_pattern"`
+	ResourcePattern string `yaml:"resource_pattern" schema:"pattern"`
 	ResourceType    string `yaml:"resource_type,omitempty"`
 }
 
 // EscalationRule defines a forbidden privilege escalation
 type EscalationRule struct {
-	FromRolePattern      string `yaml:"from_role_pattern,omitempty"`
-	ToRolePattern        string `yaml:"to_role_pattern,omitempty"`
-	FromPrincipalPattern string `yaml:"from_principal_pattern,omitempty"`
-	ToPrincipalPattern   string `yaml:"to_principal_pattern,omitempty"`
-	ToResourcePattern    string `yaml:"to_resource_pattern,omitempty"`
-	Via                  string `yaml:"via"` // "impersonation"
+	FromRolePattern      string `yaml:"from_role_pattern,omitempty" schema:"pattern"`
+	ToRolePattern        string `yaml:"to_role_pattern,omitempty" schema:"pattern"`
+	FromPrincipalPattern string `yaml:"from_principal_pattern,omitempty" schema:"pattern"`
+	ToPrincipalPattern   string `yaml:"to_principal_pattern,omitempty" schema:"pattern"`
+	ToResourcePattern    string `yaml:"to_resource_pattern,omitempty" schema:"pattern"`
+	Via                  string `yaml:"via" schema:"enum=impersonation"` // "impersonation"
 }
 
 // Violation represents a policy violation
@@ -164,6 +175,8 @@ const (
 	ViolationTypeImpersonationDepth    ViolationType = "impersonation_depth"
 	ViolationTypePrivilegeEscalation   ViolationType = "privilege_escalation"
 	ViolationTypeEffectiveAccess       ViolationType = "effective_access"
+	ViolationTypeServiceAccountKey     ViolationType = "service_account_key"
+	ViolationTypeKeyCreation           ViolationType = "key_creation"
 )
 
 // ValidationReport summarizes policy validation results
@@ -178,4 +191,5 @@ type ValidationReport struct {
 	PrincipalsAnalyzed int
 	MaxChainDepth      int
 	HighRiskFindings   []string
+	RedundantGrants    []analyzer.RedundantGrant // Grants already covered by a broader role (informational)
 }
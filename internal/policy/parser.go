@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/Tronic82/cloud-blast-radius-cli/internal/schema"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,8 +16,11 @@ func LoadPolicies(path string) (*PolicyConfig, error) {
 	}
 
 	var config PolicyConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse policy file: %w", err)
+	if err := schema.Decode(path, data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file:\n%w", err)
+	}
+	if issues := checkPolicySections(path, data, &config); len(issues) > 0 {
+		return nil, fmt.Errorf("invalid policy configuration:\n%w", schema.Errors(issues))
 	}
 
 	// Validate policies
@@ -70,25 +74,7 @@ func normalizePolicies(config *PolicyConfig) error {
 		policy := &config.Policies[i]
 
 		// Ensure exactly one type-specific field is set
-		typeFieldCount := 0
-		if policy.RoleRestriction != nil {
-			typeFieldCount++
-		}
-		if policy.Persona != nil {
-			typeFieldCount++
-		}
-		if policy.ResourceAccess != nil {
-			typeFieldCount++
-		}
-		if policy.SeparationOfDuty != nil {
-			typeFieldCount++
-		}
-		if policy.ImpersonationEscalation != nil {
-			typeFieldCount++
-		}
-		if policy.EffectiveAccess != nil {
-			typeFieldCount++
-		}
+		typeFieldCount := len(policySections(policy))
 
 		if typeFieldCount == 0 {
 			return fmt.Errorf("policy %s: no type-specific configuration found", policy.Name)
@@ -100,3 +86,71 @@ func normalizePolicies(config *PolicyConfig) error {
 
 	return nil
 }
+
+// policySections returns the policy types whose type-specific section is set
+func policySections(policy *Policy) []PolicyType {
+	var sections []PolicyType
+	if policy.RoleRestriction != nil {
+		sections = append(sections, PolicyTypeRoleRestriction)
+	}
+	if policy.Persona != nil {
+		sections = append(sections, PolicyTypePersona)
+	}
+	if policy.ResourceAccess != nil {
+		sections = append(sections, PolicyTypeResourceAccess)
+	}
+	if policy.SeparationOfDuty != nil {
+		sections = append(sections, PolicyTypeSeparationOfDuty)
+	}
+	if policy.ImpersonationEscalation != nil {
+		sections = append(sections, PolicyTypeImpersonationEscalation)
+	}
+	if policy.EffectiveAccess != nil {
+		sections = append(sections, PolicyTypeEffectiveAccess)
+	}
+	if policy.ServiceAccountKey != nil {
+		sections = append(sections, PolicyTypeServiceAccountKey)
+	}
+	return sections
+}
+
+// checkPolicySections verifies each policy has exactly one type-specific section and that it matches its type
+func checkPolicySections(path string, data []byte, config *PolicyConfig) []schema.Issue {
+	var issues []schema.Issue
+	policiesNode := schema.Lookup(schema.Root(data), "policies")
+
+	for i := range config.Policies {
+		policy := &config.Policies[i]
+		var node *yaml.Node
+		if policiesNode != nil && i < len(policiesNode.Content) {
+			node = policiesNode.Content[i]
+		}
+
+		sections := policySections(policy)
+		switch {
+		case len(sections) == 0:
+			issues = append(issues, schema.NodeIssue(path, node, "policy %q: missing %q section", policy.Name, policy.Type))
+		case len(sections) > 1:
+			issues = append(issues, schema.NodeIssue(path, node, "policy %q: multiple type-specific sections found %v", policy.Name, sections))
+		case policy.Type != "" && sections[0] != policy.Type:
+			issues = append(issues, schema.NodeIssue(path, schema.Lookup(node, string(sections[0])),
+				"policy %q: section %q does not match type %q", policy.Name, sections[0], policy.Type))
+		}
+	}
+
+	return issues
+}
+
+// LintPolicyFile checks a policy file and returns every issue found
+func LintPolicyFile(path string) []schema.Issue {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []schema.Issue{{File: path, Message: err.Error()}}
+	}
+
+	var config PolicyConfig
+	issues := schema.Check(path, data, &config)
+	issues = append(issues, checkPolicySections(path, data, &config)...)
+	schema.SortIssues(issues)
+	return issues
+}
@@ -72,6 +72,18 @@ func GenerateReport(report *ValidationReport) string {
 			output.WriteString(fmt.Sprintf("Maximum Impersonation Chain Depth: %d\n", report.MaxChainDepth))
 		}
 
+		if len(report.RedundantGrants) > 0 {
+			output.WriteString(fmt.Sprintf("\nRedundant Grants: %d\n", len(report.RedundantGrants)))
+			for _, r := range report.RedundantGrants {
+				output.WriteString(fmt.Sprintf("  - %s: %s on %s '%s' is covered by %s on %s '%s'\n",
+					r.Principal, r.Grant.Role, r.Grant.ScopeType, r.Grant.ScopeID,
+					r.CoveredBy.Role, r.CoveredBy.ScopeType, r.CoveredBy.ScopeID))
+				if r.Grant.ResourceAddress != "" {
+					output.WriteString(fmt.Sprintf("      Remove: %s\n", r.Grant.ResourceAddress))
+				}
+			}
+		}
+
 		if len(report.HighRiskFindings) > 0 {
 			output.WriteString("\nHigh-Risk Findings:\n")
 			for _, finding := range report.HighRiskFindings {
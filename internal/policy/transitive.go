@@ -102,8 +102,13 @@ func (v *PolicyValidator) checkRequiredBindings(principal string, persona *Perso
 			if required.ResourceType != "" && meta.Type != required.ResourceType {
 				continue
 			}
-			if meta.Roles[required.Role] {
-				found = true
+			for held := range meta.Roles {
+				if SatisfiesRole(held, required.Role) {
+					found = true
+					break
+				}
+			}
+			if found {
 				break
 			}
 		}
@@ -151,8 +156,8 @@ func (v *PolicyValidator) checkForbiddenBindings(principal string, persona *Pers
 						Principal:     principal,
 						Resource:      resourceID,
 						Role:          role,
-						Message:       fmt.Sprintf("Forbidden access to resource: %s", resourceID),
-						Remediation:   "Remove role binding",
+						Message:       fmt.Sprintf("Forbidden access to resource: %s%s", resourceID, viaGroups(meta.GroupPaths[role])),
+						Remediation:   bindingRemediation(meta.GroupPaths[role]),
 					})
 				}
 			} else if meta.Roles[forbidden.Role] {
@@ -161,8 +166,8 @@ func (v *PolicyValidator) checkForbiddenBindings(principal string, persona *Pers
 					Principal:     principal,
 					Resource:      resourceID,
 					Role:          forbidden.Role,
-					Message:       fmt.Sprintf("Forbidden role %s on resource %s", forbidden.Role, resourceID),
-					Remediation:   "Remove role binding",
+					Message:       fmt.Sprintf("Forbidden role %s on resource %s%s", forbidden.Role, resourceID, viaGroups(meta.GroupPaths[forbidden.Role])),
+					Remediation:   bindingRemediation(meta.GroupPaths[forbidden.Role]),
 				})
 			}
 		}
@@ -171,6 +176,39 @@ func (v *PolicyValidator) checkForbiddenBindings(principal string, persona *Pers
 	return violations
 }
 
+// viaGroups describes the group path a role is held through, or "" for the principal's own grants
+func viaGroups(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	return " (via " + strings.Join(path, " → ") + ")"
+}
+
+// bindingRemediation suggests removing the binding, or the membership when the role comes from a group
+func bindingRemediation(path []string) string {
+	if len(path) == 0 {
+		return "Remove role binding"
+	}
+	return fmt.Sprintf("Remove the principal from %s or the role binding of %s", path[0], path[len(path)-1])
+}
+
+// chainGroups describes the group path behind the first hop of a chain, or "" when the principal holds it directly
+func chainGroups(accessVia *analyzer.AccessVia) string {
+	if len(accessVia.ViaEdges) == 0 {
+		return ""
+	}
+	return viaGroups(accessVia.ViaEdges[0].ViaGroups)
+}
+
+// chainCaveat notes that a chain with an actAs hop lacking a known deploy role only grants access if the principal
+// can deploy compute some other way, or returns "" for chains that grant it outright
+func chainCaveat(accessVia *analyzer.AccessVia) string {
+	if accessVia.ChainType != analyzer.ChainActAsUnconfirmed {
+		return ""
+	}
+	return " (unconfirmed: actAs without a known deploy role)"
+}
+
 // validateTransitiveAccess validates transitive access constraints
 func (v *PolicyValidator) validateTransitiveAccess(principal string, persona *PersonaPolicy) []Violation {
 	violations := []Violation{}
@@ -205,7 +243,7 @@ func (v *PolicyValidator) validateTransitiveAccess(principal string, persona *Pe
 					Resource:           resourceID,
 					Role:               role,
 					ImpersonationChain: accessVia.ViaChain,
-					Message:            fmt.Sprintf("Forbidden transitive role '%s' via impersonation: %s", role, strings.Join(accessVia.ViaChain, " → ")),
+					Message:            fmt.Sprintf("Forbidden transitive role '%s' via impersonation: %s%s%s", role, strings.Join(accessVia.ViaChain, " → "), chainGroups(accessVia), chainCaveat(accessVia)),
 					Remediation:        "Remove impersonation permission in chain",
 				})
 			}
@@ -220,7 +258,7 @@ func (v *PolicyValidator) validateTransitiveAccess(principal string, persona *Pe
 						Principal:          principal,
 						Resource:           resourceID,
 						ImpersonationChain: accessVia.ViaChain,
-						Message:            fmt.Sprintf("Forbidden transitive resource access: %s via %s", resourceID, strings.Join(accessVia.ViaChain, " → ")),
+						Message:            fmt.Sprintf("Forbidden transitive resource access: %s via %s%s%s", resourceID, strings.Join(accessVia.ViaChain, " → "), chainGroups(accessVia), chainCaveat(accessVia)),
 						Remediation:        "Remove impersonation permission in chain",
 					})
 					break
@@ -231,18 +269,16 @@ func (v *PolicyValidator) validateTransitiveAccess(principal string, persona *Pe
 
 	// Check impersonation targets if whitelist specified
 	if len(constraints.AllowedImpersonationTargets) > 0 {
-		if targets, exists := v.impGraph.Graph[principal]; exists {
-			for _, target := range targets {
-				if !IsPrincipalIn(target, constraints.AllowedImpersonationTargets) {
-					violations = append(violations, Violation{
-						ViolationType:      ViolationTypeTransitiveResource,
-						Principal:          principal,
-						Resource:           target,
-						ImpersonationChain: []string{target},
-						Message:            fmt.Sprintf("Impersonation of unauthorized target: %s", target),
-						Remediation:        "Remove impersonation permission or add target to allowed list",
-					})
-				}
+		for _, target := range v.impGraph.Targets(principal) {
+			if !IsPrincipalIn(target, constraints.AllowedImpersonationTargets) {
+				violations = append(violations, Violation{
+					ViolationType:      ViolationTypeTransitiveResource,
+					Principal:          principal,
+					Resource:           target,
+					ImpersonationChain: []string{target},
+					Message:            fmt.Sprintf("Impersonation of unauthorized target: %s", target),
+					Remediation:        "Remove impersonation permission or add target to allowed list",
+				})
 			}
 		}
 	}
@@ -306,7 +342,7 @@ func (v *PolicyValidator) validateImpersonationEscalation(policy *Policy) []Viol
 										Principal:          principal,
 										Role:               transitiveRole,
 										ImpersonationChain: accessVia.ViaChain,
-										Message:            fmt.Sprintf("Privilege escalation detected: %s → %s via impersonation", directRole, transitiveRole),
+										Message:            fmt.Sprintf("Privilege escalation detected: %s → %s via impersonation%s", directRole, transitiveRole, chainCaveat(accessVia)),
 										Remediation:        "Remove impersonation permission in chain",
 									})
 								}
@@ -331,7 +367,7 @@ func (v *PolicyValidator) validateImpersonationEscalation(policy *Policy) []Viol
 									Resource:           resourceID,
 									Role:               transitiveRole,
 									ImpersonationChain: accessVia.ViaChain,
-									Message:            fmt.Sprintf("Principal %s can escalate to %s via impersonation", principal, transitiveRole),
+									Message:            fmt.Sprintf("Principal %s can escalate to %s via impersonation%s", principal, transitiveRole, chainCaveat(accessVia)),
 									Remediation:        "Remove impersonation permission in chain",
 								})
 							}
@@ -343,7 +379,7 @@ func (v *PolicyValidator) validateImpersonationEscalation(policy *Policy) []Viol
 			// Check principal/resource-based escalation
 			if rule.FromPrincipalPattern != "" && rule.ToResourcePattern != "" {
 				if MatchesPrincipalPattern(principal, rule.FromPrincipalPattern) {
-					for resourceID := range transitiveAccess.TransitiveAccess {
+					for resourceID, accessVia := range transitiveAccess.TransitiveAccess {
 						if MatchesResourcePattern(resourceID, rule.ToResourcePattern) {
 							violations = append(violations, Violation{
 								PolicyName:         policy.Name,
@@ -351,8 +387,8 @@ func (v *PolicyValidator) validateImpersonationEscalation(policy *Policy) []Viol
 								Severity:           policy.Severity,
 								Principal:          principal,
 								Resource:           resourceID,
-								ImpersonationChain: transitiveAccess.TransitiveAccess[resourceID].ViaChain,
-								Message:            fmt.Sprintf("Unauthorized transitive access to %s", resourceID),
+								ImpersonationChain: accessVia.ViaChain,
+								Message:            fmt.Sprintf("Unauthorized transitive access to %s%s", resourceID, chainCaveat(accessVia)),
 								Remediation:        "Remove impersonation permission in chain",
 							})
 						}
@@ -376,6 +412,7 @@ func (v *PolicyValidator) validateEffectiveAccess(policy *Policy) []Violation {
 
 	// Collect all principals with effective access (direct or transitive)
 	effectiveAccess := make(map[string]map[string]bool) // resourceID -> principal -> true
+	unconfirmed := make(map[string]map[string]bool)     // resourceID -> principal -> only reached through an unconfirmed chain
 
 	// Add direct access
 	for principal, data := range v.directAccess {
@@ -383,7 +420,7 @@ func (v *PolicyValidator) validateEffectiveAccess(policy *Policy) []Violation {
 			if !MatchesResourcePattern(resourceID, effective.Selector.ResourcePattern) {
 				continue
 			}
-			if effective.Selector.ResourceType != "" && effective.Selector.ResourceType != "*" && meta.Type != effective.Selector.ResourceType {
+			if !matchesResourceType(meta.Type, effective.Selector.ResourceType) {
 				continue
 			}
 
@@ -394,6 +431,29 @@ func (v *PolicyValidator) validateEffectiveAccess(policy *Policy) []Violation {
 		}
 	}
 
+	// Add access inherited from the resource's project, folder or organization
+	inheritedFrom := make(map[string]map[string]analyzer.Scope) // resourceID -> principal -> scope
+	for _, inherited := range analyzer.InheritedGrantsWithParents(v.bindings, v.impGraph.Parents()) {
+		if !MatchesResourcePattern(inherited.ResourceID, effective.Selector.ResourcePattern) {
+			continue
+		}
+		if !matchesResourceType(inherited.ResourceType, effective.Selector.ResourceType) {
+			continue
+		}
+		if effectiveAccess[inherited.ResourceID] == nil {
+			effectiveAccess[inherited.ResourceID] = make(map[string]bool)
+		}
+		if inheritedFrom[inherited.ResourceID] == nil {
+			inheritedFrom[inherited.ResourceID] = make(map[string]analyzer.Scope)
+		}
+		for _, member := range inherited.Binding.Members {
+			effectiveAccess[inherited.ResourceID][member] = true
+			if _, seen := inheritedFrom[inherited.ResourceID][member]; !seen {
+				inheritedFrom[inherited.ResourceID][member] = inherited.InheritedFrom
+			}
+		}
+	}
+
 	// Add transitive access if validation enabled
 	if effective.ValidateEffectiveAccess {
 		for principal := range v.directAccess {
@@ -404,7 +464,7 @@ func (v *PolicyValidator) validateEffectiveAccess(policy *Policy) []Violation {
 				continue
 			}
 
-			for resourceID := range transitiveAccess.TransitiveAccess {
+			for resourceID, accessVia := range transitiveAccess.TransitiveAccess {
 				if !MatchesResourcePattern(resourceID, effective.Selector.ResourcePattern) {
 					continue
 				}
@@ -412,6 +472,12 @@ func (v *PolicyValidator) validateEffectiveAccess(policy *Policy) []Violation {
 				if effectiveAccess[resourceID] == nil {
 					effectiveAccess[resourceID] = make(map[string]bool)
 				}
+				if !effectiveAccess[resourceID][principal] && accessVia.ChainType == analyzer.ChainActAsUnconfirmed {
+					if unconfirmed[resourceID] == nil {
+						unconfirmed[resourceID] = make(map[string]bool)
+					}
+					unconfirmed[resourceID][principal] = true
+				}
 				effectiveAccess[resourceID][principal] = true
 			}
 		}
@@ -436,6 +502,10 @@ func (v *PolicyValidator) validateEffectiveAccess(policy *Policy) []Violation {
 				accessType := "transitive"
 				if isDirect {
 					accessType = "direct"
+				} else if scope, ok := inheritedFrom[resourceID][principal]; ok {
+					accessType = fmt.Sprintf("inherited from %s '%s'", scope.Type, scope.ID)
+				} else if unconfirmed[resourceID][principal] {
+					accessType = "transitive, unconfirmed: actAs without a known deploy role"
 				}
 
 				violations = append(violations, Violation{
@@ -2,6 +2,7 @@ package policy
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/analyzer"
 	"github.com/Tronic82/cloud-blast-radius-cli/internal/parser"
@@ -71,6 +72,9 @@ func (v *PolicyValidator) Validate() (*ValidationReport, error) {
 	// Calculate max chain depth
 	report.MaxChainDepth = v.calculateMaxChainDepth()
 
+	// Flag grants already covered by a broader role
+	report.RedundantGrants = analyzer.FindRedundantGrantsWithParents(v.bindings, nil, v.impGraph.Parents())
+
 	return report, nil
 }
 
@@ -89,6 +93,8 @@ func (v *PolicyValidator) ValidatePolicy(policy *Policy) []Violation {
 		return v.validateImpersonationEscalation(policy)
 	case PolicyTypeEffectiveAccess:
 		return v.validateEffectiveAccess(policy)
+	case PolicyTypeServiceAccountKey:
+		return v.validateServiceAccountKey(policy)
 	default:
 		return []Violation{{
 			PolicyName:    policy.Name,
@@ -157,7 +163,8 @@ func (v *PolicyValidator) validateRoleRestriction(policy *Policy) []Violation {
 	return violations
 }
 
-// validateResourceAccess validates resource access policies
+// validateResourceAccess validates resource access policies.
+// Grants on a resource's project, folder or organization whose role reaches the resource count as access to it.
 func (v *PolicyValidator) validateResourceAccess(policy *Policy) []Violation {
 	violations := []Violation{}
 	access := policy.ResourceAccess
@@ -171,50 +178,84 @@ func (v *PolicyValidator) validateResourceAccess(policy *Policy) []Violation {
 		if !MatchesResourcePattern(binding.ResourceID, access.Selector.ResourcePattern) {
 			continue
 		}
-		if access.Selector.ResourceType != "" && access.Selector.ResourceType != "*" {
-			if binding.ResourceType != access.Selector.ResourceType {
-				continue
-			}
+		if !matchesResourceType(binding.ResourceType, access.Selector.ResourceType) {
+			continue
+		}
+		violations = append(violations, checkResourceAccess(policy, binding.ResourceID, binding, "")...)
+	}
+
+	// Grants inherited from the resource's ancestors
+	for _, inherited := range analyzer.InheritedGrantsWithParents(v.bindings, v.impGraph.Parents()) {
+		if !MatchesResourcePattern(inherited.ResourceID, access.Selector.ResourcePattern) {
+			continue
+		}
+		if !matchesResourceType(inherited.ResourceType, access.Selector.ResourceType) {
+			continue
 		}
+		via := fmt.Sprintf(" (inherited from %s '%s')", inherited.InheritedFrom.Type, inherited.InheritedFrom.ID)
+		violations = append(violations, checkResourceAccess(policy, inherited.ResourceID, inherited.Binding, via)...)
+	}
+
+	return violations
+}
+
+// checkResourceAccess checks the members of a binding that gives access to resourceID against a resource access policy.
+// via describes where an inherited binding was granted and is appended to the messages.
+func checkResourceAccess(policy *Policy, resourceID string, binding parser.IAMBinding, via string) []Violation {
+	violations := []Violation{}
+	access := policy.ResourceAccess
 
-		// Check each member
-		for _, member := range binding.Members {
-			// Check if member is allowed
-			if !IsPrincipalIn(member, access.AllowedPrincipals) {
+	removeFrom := "resource"
+	if via != "" {
+		removeFrom = binding.ResourceLevel + " '" + binding.ResourceID + "'"
+	}
+
+	for _, member := range binding.Members {
+		// Check if member is allowed
+		if !IsPrincipalIn(member, access.AllowedPrincipals) {
+			violations = append(violations, Violation{
+				PolicyName:    policy.Name,
+				ViolationType: ViolationTypeUnauthorizedPrincipal,
+				Severity:      policy.Severity,
+				Principal:     member,
+				Resource:      resourceID,
+				Role:          binding.Role,
+				Message:       "Unauthorized principal has access to resource" + via,
+				Remediation:   "Remove principal from " + removeFrom + " access",
+			})
+		}
+
+		// Check role restrictions if specified
+		if len(access.AllowedRolesPerPrincipal) > 0 {
+			allowedRoles, exists := access.AllowedRolesPerPrincipal[member]
+			if exists && !IsRoleIn(binding.Role, allowedRoles) {
 				violations = append(violations, Violation{
 					PolicyName:    policy.Name,
-					ViolationType: ViolationTypeUnauthorizedPrincipal,
+					ViolationType: ViolationTypeForbiddenRole,
 					Severity:      policy.Severity,
 					Principal:     member,
-					Resource:      binding.ResourceID,
+					Resource:      resourceID,
 					Role:          binding.Role,
-					Message:       "Unauthorized principal has access to resource",
-					Remediation:   "Remove principal from resource access",
+					Message:       "Principal has unauthorized role on resource" + via,
+					Remediation:   "Change role to allowed role or remove binding",
 				})
 			}
-
-			// Check role restrictions if specified
-			if len(access.AllowedRolesPerPrincipal) > 0 {
-				allowedRoles, exists := access.AllowedRolesPerPrincipal[member]
-				if exists && !IsRoleIn(binding.Role, allowedRoles) {
-					violations = append(violations, Violation{
-						PolicyName:    policy.Name,
-						ViolationType: ViolationTypeForbiddenRole,
-						Severity:      policy.Severity,
-						Principal:     member,
-						Resource:      binding.ResourceID,
-						Role:          binding.Role,
-						Message:       "Principal has unauthorized role on resource",
-						Remediation:   "Change role to allowed role or remove binding",
-					})
-				}
-			}
 		}
 	}
 
 	return violations
 }
 
+// matchesResourceType reports whether an IAM resource type matches a selector's resource_type.
+// The selector may name the IAM resource type itself or the resource type it grants on
+// (e.g. "google_storage_bucket" matches "google_storage_bucket_iam_member"); "" and "*" match every type.
+func matchesResourceType(iamType, selector string) bool {
+	if selector == "" || selector == "*" {
+		return true
+	}
+	return iamType == selector || analyzer.BaseResourceType(iamType) == selector
+}
+
 // validateSeparationOfDuty validates separation of duty policies
 func (v *PolicyValidator) validateSeparationOfDuty(policy *Policy) []Violation {
 	violations := []Violation{}
@@ -236,15 +277,19 @@ func (v *PolicyValidator) validateSeparationOfDuty(policy *Policy) []Violation {
 				}
 			}
 
-			// Check for conflicts
+			// Check for conflicts, counting duties held through broader roles
 			for _, conflictSet := range sod.ConflictingRoles {
 				hasCount := 0
 				conflictingRoles := []string{}
 
 				for _, role := range conflictSet {
-					if allRoles[role] {
+					if held, ok := findSatisfyingRole(allRoles, role); ok {
 						hasCount++
-						conflictingRoles = append(conflictingRoles, role)
+						if held == role {
+							conflictingRoles = append(conflictingRoles, role)
+						} else {
+							conflictingRoles = append(conflictingRoles, fmt.Sprintf("%s (via %s)", role, held))
+						}
 					}
 				}
 
@@ -265,6 +310,24 @@ func (v *PolicyValidator) validateSeparationOfDuty(policy *Policy) []Violation {
 	return violations
 }
 
+// findSatisfyingRole returns a held role that satisfies the required role, preferring an exact match
+func findSatisfyingRole(held map[string]bool, required string) (string, bool) {
+	if held[required] {
+		return required, true
+	}
+	roles := make([]string, 0, len(held))
+	for role := range held {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		if SatisfiesRole(role, required) {
+			return role, true
+		}
+	}
+	return "", false
+}
+
 // calculateMaxChainDepth calculates the maximum impersonation chain depth
 func (v *PolicyValidator) calculateMaxChainDepth() int {
 	maxDepth := 0
@@ -291,13 +354,11 @@ func (v *PolicyValidator) calculateMaxChainDepth() int {
 			}
 
 			// Add impersonation targets
-			if targets, exists := v.impGraph.Graph[current.principal]; exists {
-				for _, target := range targets {
-					queue = append(queue, struct {
-						principal string
-						depth     int
-					}{target, current.depth + 1})
-				}
+			for _, target := range v.impGraph.Targets(current.principal) {
+				queue = append(queue, struct {
+					principal string
+					depth     int
+				}{target, current.depth + 1})
 			}
 		}
 	}
@@ -0,0 +1,36 @@
+package policy
+
+import "testing"
+
+func TestFindSatisfyingRole(t *testing.T) {
+	loadCustomRoles(t)
+
+	tests := []struct {
+		name     string
+		held     []string
+		required string
+		want     string
+		ok       bool
+	}{
+		{"Exact Match Preferred", []string{"roles/custom.admin", "roles/custom.reader"}, "roles/custom.reader", "roles/custom.reader", true},
+		{"Including Role", []string{"roles/custom.admin", "roles/viewer"}, "roles/custom.reader", "roles/custom.admin", true},
+		{"First In Order", []string{"roles/owner", "roles/editor"}, "roles/viewer", "roles/editor", true},
+		{"Permission", []string{"roles/custom.reader", "roles/custom.admin"}, "permission:custom.things.delete", "roles/custom.admin", true},
+		{"Permission Not Held", []string{"roles/custom.reader"}, "permission:custom.things.delete", "", false},
+		{"Nothing Satisfies", []string{"roles/custom.reader"}, "roles/custom.admin", "", false},
+		{"No Roles", nil, "roles/custom.reader", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			held := make(map[string]bool)
+			for _, r := range tt.held {
+				held[r] = true
+			}
+			got, ok := findSatisfyingRole(held, tt.required)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("findSatisfyingRole(%v, %q) = %q, %v, want %q, %v", tt.held, tt.required, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
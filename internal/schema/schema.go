@@ -0,0 +1,351 @@
+// Package schema performs strict, position-aware decoding of the YAML files
+// blast-radius reads (definitions, rules, config and policies).
+//
+// Struct fields can carry a `schema` tag with comma-separated checks:
+//   - enum=a|b|c   value must be one of the listed values (empty is allowed)
+//   - regex        value (or each list element) must compile as a Go regex
+//   - pattern      like regex, but the "*" wildcard is also accepted
+//   - unique       list elements must not repeat
+//   - unique=key   list of mappings must not repeat the given key
+//   - required     value must be present and non-empty
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Issue describes a single problem found in a YAML file
+type Issue struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// String formats the issue as file:line:column: message
+func (i Issue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s", i.File, i.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", i.File, i.Line, i.Column, i.Message)
+}
+
+// Errors is a list of issues that can be returned as an error
+type Errors []Issue
+
+func (e Errors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, issue := range e {
+		lines = append(lines, issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Decode strictly decodes data into out. Unknown fields, duplicate keys,
+// failed tag checks and type mismatches are returned as Errors.
+func Decode(file string, data []byte, out interface{}) error {
+	issues := Check(file, data, out)
+	if len(issues) > 0 {
+		return Errors(issues)
+	}
+	return nil
+}
+
+// Check decodes data into out and returns every issue found instead of stopping at the first
+func Check(file string, data []byte, out interface{}) []Issue {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []Issue{yamlErrorIssue(file, err)}
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return nil // empty file
+	}
+
+	root := doc.Content[0]
+	c := &checker{file: file}
+	c.walk(root, reflect.TypeOf(out), "")
+
+	if err := root.Decode(out); err != nil {
+		if typeErr, ok := err.(*yaml.TypeError); ok {
+			for _, msg := range typeErr.Errors {
+				c.issues = append(c.issues, messageIssue(file, msg))
+			}
+		} else {
+			c.issues = append(c.issues, yamlErrorIssue(file, err))
+		}
+	}
+
+	SortIssues(c.issues)
+	return c.issues
+}
+
+// SortIssues orders issues by file position
+func SortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Column < issues[j].Column
+	})
+}
+
+// NodeIssue builds an issue positioned at the given node
+func NodeIssue(file string, node *yaml.Node, format string, args ...interface{}) Issue {
+	issue := Issue{File: file, Message: fmt.Sprintf(format, args...)}
+	if node != nil {
+		issue.Line = node.Line
+		issue.Column = node.Column
+	}
+	return issue
+}
+
+// Root parses data and returns its top-level node, or nil if it cannot be parsed
+func Root(data []byte) *yaml.Node {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// Lookup returns the value node for key in a mapping node
+func Lookup(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+type checker struct {
+	file   string
+	issues []Issue
+}
+
+func (c *checker) add(node *yaml.Node, format string, args ...interface{}) {
+	c.issues = append(c.issues, NodeIssue(c.file, node, format, args...))
+}
+
+// walk compares node against the Go type t, recursing into structs, maps and slices
+func (c *checker) walk(node *yaml.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			c.add(node, "%s: expected a mapping", displayPath(path))
+			return
+		}
+		fields := yamlFields(t)
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if seen[key.Value] {
+				c.add(key, "%s: duplicate key %q", displayPath(path), key.Value)
+			}
+			seen[key.Value] = true
+
+			field, known := fields[key.Value]
+			if !known {
+				c.add(key, "%s: unknown field %q", displayPath(path), key.Value)
+				continue
+			}
+			fieldPath := joinPath(path, key.Value)
+			c.checkTag(value, field.Tag.Get("schema"), fieldPath)
+			c.walk(value, field.Type, fieldPath)
+		}
+		for name, field := range fields {
+			if hasOption(field.Tag.Get("schema"), "required") && !seen[name] {
+				c.add(node, "%s: missing required field %q", displayPath(path), name)
+			}
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			c.add(node, "%s: expected a mapping", displayPath(path))
+			return
+		}
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if seen[key.Value] {
+				c.add(key, "%s: duplicate key %q", displayPath(path), key.Value)
+			}
+			seen[key.Value] = true
+			c.walk(value, t.Elem(), joinPath(path, key.Value))
+		}
+
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			// Let the decoder report the type mismatch
+			return
+		}
+		for i, item := range node.Content {
+			c.walk(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// checkTag applies the schema tag checks for a field to its value node
+func (c *checker) checkTag(node *yaml.Node, tag, path string) {
+	if tag == "" {
+		return
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(opt, "=")
+		switch name {
+		case "required":
+			if node.Kind == yaml.ScalarNode && node.Value == "" {
+				c.add(node, "%s: value is required", path)
+			}
+		case "enum":
+			allowed := strings.Split(arg, "|")
+			for _, n := range scalars(node) {
+				if n.Value != "" && !contains(allowed, n.Value) {
+					c.add(n, "%s: invalid value %q (allowed: %s)", path, n.Value, strings.Join(allowed, ", "))
+				}
+			}
+		case "regex", "pattern":
+			for _, n := range scalars(node) {
+				if n.Value == "" || (name == "pattern" && n.Value == "*") {
+					continue
+				}
+				if _, err := regexp.Compile(n.Value); err != nil {
+					c.add(n, "%s: invalid regex %q: %v", path, n.Value, err)
+				}
+			}
+		case "unique":
+			c.checkUnique(node, arg, path)
+		}
+	}
+}
+
+// checkUnique reports repeated list elements, optionally compared by a mapping key
+func (c *checker) checkUnique(node *yaml.Node, key, path string) {
+	if node.Kind != yaml.SequenceNode {
+		return
+	}
+	firstSeen := make(map[string]int)
+	for _, item := range node.Content {
+		valueNode := item
+		if key != "" {
+			valueNode = Lookup(item, key)
+			if valueNode == nil {
+				continue
+			}
+		}
+		if line, dup := firstSeen[valueNode.Value]; dup {
+			c.add(valueNode, "%s: duplicate entry %q (first defined at line %d)", path, valueNode.Value, line)
+			continue
+		}
+		firstSeen[valueNode.Value] = valueNode.Line
+	}
+}
+
+// yamlFields maps yaml key names to struct fields
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// scalars returns the node itself for scalars, or the scalar elements of sequences and mapping values
+func scalars(node *yaml.Node) []*yaml.Node {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return []*yaml.Node{node}
+	case yaml.SequenceNode:
+		var result []*yaml.Node
+		for _, item := range node.Content {
+			result = append(result, scalars(item)...)
+		}
+		return result
+	case yaml.MappingNode:
+		var result []*yaml.Node
+		for i := 1; i < len(node.Content); i += 2 {
+			result = append(result, scalars(node.Content[i])...)
+		}
+		return result
+	}
+	return nil
+}
+
+func hasOption(tag, option string) bool {
+	for _, opt := range strings.Split(tag, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "document"
+	}
+	return path
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)(?:, column (\d+))?: `)
+
+// messageIssue converts a yaml error message ("line N: ...") to an issue
+func messageIssue(file, msg string) Issue {
+	issue := Issue{File: file, Message: msg}
+	if m := yamlLinePattern.FindStringSubmatchIndex(msg); m != nil {
+		issue.Line, _ = strconv.Atoi(msg[m[2]:m[3]])
+		if m[4] >= 0 {
+			issue.Column, _ = strconv.Atoi(msg[m[4]:m[5]])
+		}
+		issue.Message = msg[m[1]:]
+	}
+	return issue
+}
+
+func yamlErrorIssue(file string, err error) Issue {
+	return messageIssue(file, strings.TrimPrefix(err.Error(), "yaml: "))
+}
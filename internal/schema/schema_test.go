@@ -0,0 +1,123 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+type testRule struct {
+	Name    string   `yaml:"name" schema:"required"`
+	Level   string   `yaml:"level" schema:"enum=read|write|admin"`
+	Pattern string   `yaml:"pattern" schema:"pattern"`
+	Regexes []string `yaml:"regexes" schema:"regex"`
+}
+
+type testConfig struct {
+	Rules []testRule `yaml:"rules" schema:"unique=name"`
+	Tags  []string   `yaml:"tags" schema:"unique"`
+	Count int        `yaml:"count"`
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		wantMsgs []string // substrings, one per expected issue, in position order
+		wantPos  [][2]int // line, column per expected issue
+	}{
+		{
+			name: "Valid Document",
+			yaml: `
+rules:
+  - name: a
+    level: read
+    pattern: "*"
+    regexes: ["^roles/.*"]
+tags: [x, y]
+count: 2
+`,
+		},
+		{
+			name: "Unknown Field",
+			yaml: `
+rules:
+  - name: a
+    levle: read
+`,
+			wantMsgs: []string{`unknown field "levle"`},
+			wantPos:  [][2]int{{4, 5}},
+		},
+		{
+			name: "Invalid Enum",
+			yaml: `
+rules:
+  - name: a
+    level: owner
+`,
+			wantMsgs: []string{`invalid value "owner"`},
+			wantPos:  [][2]int{{4, 12}},
+		},
+		{
+			name: "Invalid Regex",
+			yaml: `
+rules:
+  - name: a
+    pattern: "roles/(storage"
+    regexes: ["ok", "*"]
+`,
+			wantMsgs: []string{`invalid regex "roles/(storage"`, `invalid regex "*"`},
+			wantPos:  [][2]int{{4, 14}, {5, 21}},
+		},
+		{
+			name: "Duplicates",
+			yaml: `
+rules:
+  - name: a
+  - name: a
+tags: [x, x]
+`,
+			wantMsgs: []string{`duplicate entry "a" (first defined at line 3)`, `duplicate entry "x"`},
+			wantPos:  [][2]int{{4, 11}, {5, 11}},
+		},
+		{
+			name: "Missing Required And Type Mismatch",
+			yaml: `
+rules:
+  - level: read
+count: many
+`,
+			wantMsgs: []string{`missing required field "name"`, `cannot unmarshal`},
+			wantPos:  [][2]int{{3, 5}, {4, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg testConfig
+			issues := Check("test.yaml", []byte(tt.yaml), &cfg)
+
+			if len(issues) != len(tt.wantMsgs) {
+				t.Fatalf("got %d issues, want %d: %v", len(issues), len(tt.wantMsgs), issues)
+			}
+			for i, issue := range issues {
+				if !strings.Contains(issue.Message, tt.wantMsgs[i]) {
+					t.Errorf("issue %d = %q, want it to contain %q", i, issue.Message, tt.wantMsgs[i])
+				}
+				if issue.File != "test.yaml" || issue.Line != tt.wantPos[i][0] || issue.Column != tt.wantPos[i][1] {
+					t.Errorf("issue %d at %s:%d:%d, want test.yaml:%d:%d", i, issue.File, issue.Line, issue.Column, tt.wantPos[i][0], tt.wantPos[i][1])
+				}
+			}
+		})
+	}
+}
+
+func TestDecode_ReturnsErrors(t *testing.T) {
+	var cfg testConfig
+	err := Decode("test.yaml", []byte("tagz: [a]\n"), &cfg)
+	if err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+	if !strings.HasPrefix(err.Error(), "test.yaml:1:1: ") {
+		t.Errorf("error = %q, want file:line:column prefix", err.Error())
+	}
+}